@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -31,6 +32,7 @@ import (
 func main() {
 	app := server.New()
 	server.Mount(app.R, app.DB)
+	server.StartJobs(context.Background(), app.DB)
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -0,0 +1,195 @@
+// Package audit records administrative and owner actions in the
+// append-only audit_logs table.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionUserDelete           = "user.delete"
+	ActionOrganizationDelete   = "organization.delete"
+	ActionSubscriptionActivate = "subscription.activate"
+	ActionReviewApprove        = "review.approve"
+	ActionRestaurantUpdate     = "restaurant.update"
+	ActionRestaurantDelete     = "restaurant.delete"
+	ActionRestaurantClone      = "restaurant.clone"
+	ActionRestaurantPricing    = "restaurant.pricing"
+	ActionRestaurantBudget     = "restaurant.budget"
+	ActionRestaurantLocation   = "restaurant.location"
+	ActionRestaurantHours      = "restaurant.special_hours"
+	ActionMenuCreate           = "menu.create"
+	ActionMenuUpdate           = "menu.update"
+	ActionMenuDelete           = "menu.delete"
+	ActionMenuReorder          = "menu.reorder"
+	ActionMenuAvailability     = "menu.availability"
+	ActionMenuSoldOut          = "menu.sold_out"
+	ActionMenuBackInStock      = "menu.back_in_stock"
+	ActionMenuImport           = "menu.import"
+	ActionMenuCategoryCreate   = "menu_category.create"
+	ActionMenuCategoryUpdate   = "menu_category.update"
+	ActionMenuCategoryDelete   = "menu_category.delete"
+	ActionMenuCategoryReorder  = "menu_category.reorder"
+	ActionCategoryAvailability = "menu_category.availability"
+	ActionMenuVersionCreate    = "menu_version.create"
+	ActionMenuVersionUpdate    = "menu_version.update"
+	ActionMenuVersionDelete    = "menu_version.delete"
+	ActionMenuVersionSchedule  = "menu_version.schedule"
+	ActionMenuVersionPublish   = "menu_version.publish"
+	ActionMenuVersionRollback  = "menu_version.rollback"
+	ActionCourseCreate         = "course.create"
+	ActionCourseUpdate         = "course.update"
+	ActionCourseDelete         = "course.delete"
+	ActionInvitationCreate     = "invitation.create"
+	ActionInvitationResend     = "invitation.resend"
+	ActionInvitationRevoke     = "invitation.revoke"
+	ActionInvitationAccept     = "invitation.accept"
+	ActionInvitationDecline    = "invitation.decline"
+	ActionAPIKeyCreate         = "apikey.create"
+	ActionAPIKeyRotate         = "apikey.rotate"
+	ActionAPIKeyRevoke         = "apikey.revoke"
+)
+
+// redacted replaces the value of sensitive fields
+const redacted = "[REDACTED]"
+
+// Entry describes an action to record. Before and After are snapshots of
+// the entity (usually the model) and are reduced to the fields that
+// changed; leave Before nil for creations and After nil for deletions.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	OrgID      *uuid.UUID
+	Before     interface{}
+	After      interface{}
+}
+
+// Record writes e using tx, which should be the transaction making the
+// change so that the log and the change commit together. Actor and request
+// metadata are taken from c; c may be nil for system actions.
+func Record(tx *gorm.DB, c *gin.Context, e Entry) error {
+	before, after, err := Diff(e.Before, e.After)
+	if err != nil {
+		return err
+	}
+
+	entry := db.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      e.OrgID,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     before,
+		After:      after,
+		CreatedAt:  time.Now(),
+	}
+
+	if c != nil {
+		if uid, err := uuid.Parse(c.GetString("uid")); err == nil {
+			entry.ActorID = &uid
+		}
+		entry.ActorRole = c.GetString("role")
+		if orgRole := c.GetString("orgRole"); orgRole != "" {
+			entry.ActorRole = orgRole
+		}
+		if entry.OrgID == nil {
+			if orgID, err := uuid.Parse(c.GetString("orgId")); err == nil {
+				entry.OrgID = &orgID
+			}
+		}
+		if c.Request != nil {
+			entry.IP = c.ClientIP()
+			entry.UserAgent = c.Request.UserAgent()
+			entry.Method = c.Request.Method
+			entry.Path = c.Request.URL.Path
+			entry.RequestID = c.GetHeader("X-Request-ID")
+		}
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// Diff converts the before and after snapshots to JSON objects holding only
+// the fields that differ. When one side is nil the other is kept whole.
+// Sensitive fields such as passwords are redacted.
+func Diff(before, after interface{}) (db.JSON, db.JSON, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if b != nil && a != nil {
+		for key, value := range b {
+			if other, ok := a[key]; ok && reflect.DeepEqual(value, other) {
+				delete(b, key)
+				delete(a, key)
+			}
+		}
+	}
+
+	beforeJSON, err := fromMap(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := fromMap(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func fromMap(m map[string]interface{}) (db.JSON, error) {
+	if m == nil {
+		return nil, nil
+	}
+	for key := range m {
+		if sensitive(key) {
+			m[key] = redacted
+		}
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return db.JSON(raw), nil
+}
+
+// sensitive reports whether a field must not be stored in the log
+func sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range []string{"password", "secret", "token", "verifier", "nonce", "hash"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
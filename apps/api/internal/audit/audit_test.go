@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Name     string
+	Price    int
+	Password string
+	Hash     string
+}
+
+func decode(t *testing.T, raw []byte) map[string]interface{} {
+	if raw == nil {
+		return nil
+	}
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &m))
+	return m
+}
+
+func TestDiff_OnlyChangedFields(t *testing.T) {
+	before, after, err := Diff(item{Name: "Momo", Price: 300}, item{Name: "Momo", Price: 350})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"Price": float64(300)}, decode(t, before))
+	assert.Equal(t, map[string]interface{}{"Price": float64(350)}, decode(t, after))
+}
+
+func TestDiff_CreateAndDelete(t *testing.T) {
+	before, after, err := Diff(nil, item{Name: "Momo", Price: 300})
+	require.NoError(t, err)
+	assert.Nil(t, before)
+	assert.Equal(t, "Momo", decode(t, after)["Name"])
+
+	before, after, err = Diff(item{Name: "Momo"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Momo", decode(t, before)["Name"])
+	assert.Nil(t, after)
+}
+
+func TestDiff_RedactsSensitiveFields(t *testing.T) {
+	before, after, err := Diff(item{Password: "old-hash"}, item{Password: "new-hash"})
+	require.NoError(t, err)
+
+	// The change is still visible, the values are not
+	assert.Equal(t, redacted, decode(t, before)["Password"])
+	assert.Equal(t, redacted, decode(t, after)["Password"])
+
+	_, after, err = Diff(nil, item{Name: "a", Password: "hash", Hash: "9f86d081"})
+	require.NoError(t, err)
+	assert.Equal(t, redacted, decode(t, after)["Password"])
+	assert.Equal(t, redacted, decode(t, after)["Hash"])
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "rsk_"+prefix+"_"))
+	assert.Len(t, prefix, 16)
+
+	parsed, ok := APIKeyPrefix(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+	assert.Len(t, HashAPIKey(key), 64)
+	assert.NotContains(t, HashAPIKey(key), prefix)
+
+	// Keys issued with shorter prefixes
+	parsed, ok = APIKeyPrefix("rsk_0123abcd_secret")
+	assert.True(t, ok)
+	assert.Equal(t, "0123abcd", parsed)
+
+	for _, bad := range []string{"", "rsk_", "rsk_short_x", "sk_0123abcd_secret", "rsk_0123abcd_"} {
+		_, ok := APIKeyPrefix(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestRequireAuthOrAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "apikey-test")
+
+	valid, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	principal := &APIKeyPrincipal{ID: uuid.New(), OrgID: uuid.New(), Scopes: []string{"menus:read"}}
+	resolve := func(key string) (*APIKeyPrincipal, error) {
+		if key == valid {
+			return principal, nil
+		}
+		return nil, ErrInvalidAPIKey
+	}
+
+	r := gin.New()
+	r.GET("/", RequireAuthOrAPIKey(resolve), AddTokenToResponse(), func(c *gin.Context) {
+		_, isKey := APIKeyFromContext(c)
+		c.JSON(200, gin.H{"uid": c.GetString("uid"), "role": c.GetString("role"), "key": isKey})
+	})
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, w := range []*httptest.ResponseRecorder{
+		get("X-API-Key", valid),
+		get("Authorization", "Bearer "+valid),
+	} {
+		assert.Equal(t, 200, w.Code)
+		assert.Contains(t, w.Body.String(), `"role":"API_KEY"`)
+		assert.Contains(t, w.Body.String(), principal.ID.String())
+		// Key clients are never handed a session token
+		assert.Empty(t, w.Header().Get("X-Auth-Token"))
+	}
+
+	assert.Equal(t, 401, get("X-API-Key", "rsk_0123abcd_wrong").Code)
+	assert.Equal(t, 401, get("", "").Code)
+
+	// User sessions still work
+	token, err := IssueToken(uuid.NewString(), "OWNER")
+	require.NoError(t, err)
+	w := get("Authorization", "Bearer "+token)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"key":false`)
+	assert.NotEmpty(t, w.Header().Get("X-Auth-Token"))
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInviteToken_RoundTrip(t *testing.T) {
+	t.Setenv("JWT_SECRET", "invite-test")
+
+	token, err := IssueInviteToken("inv-1", "nonce-1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	claims, err := ParseInviteToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "inv-1", claims.InvitationID)
+	assert.Equal(t, "nonce-1", claims.Nonce)
+}
+
+func TestInviteToken_Expired(t *testing.T) {
+	t.Setenv("JWT_SECRET", "invite-test")
+
+	token, err := IssueInviteToken("inv-1", "nonce-1", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	_, err = ParseInviteToken(token)
+	assert.ErrorIs(t, err, ErrInvalidInviteToken)
+}
+
+func TestInviteToken_NotInterchangeableWithSessionTokens(t *testing.T) {
+	t.Setenv("JWT_SECRET", "invite-test")
+
+	session, err := IssueToken("user-1", "OWNER")
+	require.NoError(t, err)
+	_, err = ParseInviteToken(session)
+	assert.ErrorIs(t, err, ErrInvalidInviteToken)
+
+	invite, err := IssueInviteToken("inv-1", "nonce-1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = jwt.ParseWithClaims(invite, &Claims{}, func(t *jwt.Token) (interface{}, error) {
+		return []byte("invite-test"), nil
+	})
+	assert.Error(t, err)
+}
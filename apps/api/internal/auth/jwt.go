@@ -94,7 +94,8 @@ func AddTokenToResponse() gin.HandlerFunc {
 		// Only add token to successful responses
 		if c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
 			if uid, exists := c.Get("uid"); exists {
-				if role, exists := c.Get("role"); exists {
+				// API key clients keep using their key; never mint them a session
+				if role, exists := c.Get("role"); exists && role != RoleAPIKey {
 					if token, err := IssueToken(uid.(string), role.(string)); err == nil {
 						c.Header("X-Auth-Token", token)
 					}
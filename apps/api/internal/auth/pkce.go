@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// RandomToken returns n random bytes encoded as unpadded base64url
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewPKCE returns a PKCE code verifier and its S256 code challenge (RFC 7636)
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return verifier, PKCEChallenge(verifier), nil
+}
+
+// PKCEChallenge derives the S256 code challenge for a verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
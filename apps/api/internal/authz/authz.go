@@ -0,0 +1,236 @@
+package authz
+
+import (
+	"github.com/example/restosaas/apps/api/internal/auth"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Permission is an action an organization member may perform
+type Permission string
+
+const (
+	PermRestaurantRead    Permission = "restaurant:read"
+	PermRestaurantWrite   Permission = "restaurant:write" // Details, hours and images
+	PermRestaurantCreate  Permission = "restaurant:create"
+	PermRestaurantDelete  Permission = "restaurant:delete"
+	PermMenusRead         Permission = "menus:read" // Menus and courses
+	PermMenusWrite        Permission = "menus:write"
+	PermMenusStock        Permission = "menus:stock" // Mark items sold out and back in stock
+	PermReservationsRead  Permission = "reservations:read"
+	PermReservationsWrite Permission = "reservations:write"
+	PermReviewsModerate   Permission = "reviews:moderate"
+	PermMembersManage     Permission = "members:manage"
+	PermBillingManage     Permission = "billing:manage"
+	PermAuditRead         Permission = "audit:read"
+	PermAPIKeysManage     Permission = "apikeys:manage"
+)
+
+// rolePermissions maps each organization role to what it may do
+var rolePermissions = map[db.OrgRole][]Permission{
+	db.OrgRoleOwner: {
+		PermRestaurantRead, PermRestaurantWrite, PermRestaurantCreate, PermRestaurantDelete,
+		PermMenusRead, PermMenusWrite, PermMenusStock,
+		PermReservationsRead, PermReservationsWrite,
+		PermReviewsModerate, PermMembersManage, PermBillingManage,
+		PermAuditRead, PermAPIKeysManage,
+	},
+	db.OrgRoleManager: {
+		PermRestaurantRead, PermRestaurantWrite,
+		PermMenusRead, PermMenusWrite, PermMenusStock,
+		PermReservationsRead, PermReservationsWrite,
+		PermReviewsModerate,
+	},
+	db.OrgRoleHost: {
+		PermRestaurantRead, PermMenusRead,
+		PermReservationsRead, PermReservationsWrite,
+	},
+	db.OrgRoleKitchen: {
+		PermRestaurantRead, PermMenusRead, PermMenusStock,
+		PermReservationsRead,
+	},
+	db.OrgRoleMarketing: {
+		PermRestaurantRead, PermRestaurantWrite,
+		PermMenusRead, PermMenusWrite,
+		PermReviewsModerate,
+	},
+}
+
+// APIKeyScopes are the permissions that may be granted to API keys.
+// Membership, billing and key management stay with people.
+var APIKeyScopes = []Permission{
+	PermRestaurantRead, PermRestaurantWrite,
+	PermMenusRead, PermMenusWrite, PermMenusStock,
+	PermReservationsRead, PermReservationsWrite,
+	PermReviewsModerate,
+}
+
+// ValidAPIKeyScope reports whether scope may be granted to an API key
+func ValidAPIKeyScope(scope string) bool {
+	for _, p := range APIKeyScopes {
+		if string(p) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether role grants perm
+func Can(role db.OrgRole, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns the permissions granted to role
+func Permissions(role db.OrgRole) []Permission {
+	return append([]Permission(nil), rolePermissions[role]...)
+}
+
+// Membership loads the user's membership in an organization
+func Membership(gdb *gorm.DB, userID string, orgID uuid.UUID) (*db.OrgMember, error) {
+	var member db.OrgMember
+	if err := gdb.Where("user_id = ? AND org_id = ?", userID, orgID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// access decides whether the caller may perform perm in the organization.
+// member is false when the caller does not belong to it at all. API keys
+// belong to their own organization and may do what their scopes allow.
+func access(c *gin.Context, gdb *gorm.DB, orgID uuid.UUID, perm Permission) (member, allowed bool, err error) {
+	if key, ok := auth.APIKeyFromContext(c); ok {
+		if key.OrgID != orgID {
+			return false, false, nil
+		}
+		return true, key.HasScope(string(perm)), nil
+	}
+
+	m, err := Membership(gdb, c.GetString("uid"), orgID)
+	if err == gorm.ErrRecordNotFound {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	c.Set("orgRole", string(m.Role))
+	return true, Can(m.Role, perm), nil
+}
+
+// Authorize checks that the caller may perform perm in the organization and
+// writes a 403 response when not. SUPER_ADMIN is always allowed.
+func Authorize(c *gin.Context, gdb *gorm.DB, orgID uuid.UUID, perm Permission) bool {
+	if c.GetString("role") == string(db.RoleSuper) {
+		return true
+	}
+
+	member, allowed, err := access(c, gdb, orgID, perm)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to check permissions"})
+		return false
+	}
+	if !member {
+		c.JSON(403, gin.H{"error": "access denied: not a member of this organization"})
+		return false
+	}
+	if !allowed {
+		c.JSON(403, gin.H{"error": "access denied: missing permission " + string(perm)})
+		return false
+	}
+
+	c.Set("orgId", orgID.String())
+	return true
+}
+
+// OrgScope is a middleware for routes with an organization :id. Like
+// RestaurantScope it answers 404 to callers outside the organization and
+// 403 to members lacking perm; SUPER_ADMIN may access every organization.
+func OrgScope(gdb *gorm.DB, perm Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(400, gin.H{"error": "invalid organization ID"})
+			return
+		}
+
+		if c.GetString("role") != string(db.RoleSuper) {
+			member, allowed, err := access(c, gdb, orgID, perm)
+			if err != nil {
+				c.AbortWithStatusJSON(500, gin.H{"error": "failed to check permissions"})
+				return
+			}
+			if !member {
+				c.AbortWithStatusJSON(404, gin.H{"error": "organization not found"})
+				return
+			}
+			if !allowed {
+				c.AbortWithStatusJSON(403, gin.H{"error": "access denied: missing permission " + string(perm)})
+				return
+			}
+		}
+
+		c.Set("orgId", orgID.String())
+		c.Next()
+	}
+}
+
+// restaurantKey is the gin context key holding the scoped restaurant
+const restaurantKey = "restaurant"
+
+// RestaurantScope is a middleware for routes with a restaurant :id. It loads
+// the restaurant, requires the caller to be a member of its organization
+// with perm, and stores it for handlers (see ScopedRestaurant). Callers
+// outside the organization get 404 so restaurant IDs of other tenants
+// cannot be probed; members lacking perm get 403. SUPER_ADMIN may access
+// every restaurant.
+func RestaurantScope(gdb *gorm.DB, perm Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		restaurantID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(400, gin.H{"error": "invalid restaurant ID"})
+			return
+		}
+
+		var restaurant db.Restaurant
+		if err := gdb.Where("id = ?", restaurantID).First(&restaurant).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.AbortWithStatusJSON(404, gin.H{"error": "restaurant not found"})
+				return
+			}
+			c.AbortWithStatusJSON(500, gin.H{"error": "failed to fetch restaurant"})
+			return
+		}
+
+		if c.GetString("role") != string(db.RoleSuper) {
+			member, allowed, err := access(c, gdb, restaurant.OrgID, perm)
+			if err != nil {
+				c.AbortWithStatusJSON(500, gin.H{"error": "failed to check permissions"})
+				return
+			}
+			if !member {
+				c.AbortWithStatusJSON(404, gin.H{"error": "restaurant not found"})
+				return
+			}
+			if !allowed {
+				c.AbortWithStatusJSON(403, gin.H{"error": "access denied: missing permission " + string(perm)})
+				return
+			}
+			c.Set("orgId", restaurant.OrgID.String())
+		}
+
+		c.Set(restaurantKey, &restaurant)
+		c.Next()
+	}
+}
+
+// ScopedRestaurant returns the restaurant loaded by RestaurantScope. It
+// panics when the route is not behind RestaurantScope, which is a wiring bug.
+func ScopedRestaurant(c *gin.Context) *db.Restaurant {
+	return c.MustGet(restaurantKey).(*db.Restaurant)
+}
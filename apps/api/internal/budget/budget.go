@@ -0,0 +1,157 @@
+// Package budget reads what a meal at a restaurant costs per person,
+// written as an amount ("1000"), a range ("500-1500", "Rs 500 ~ 1,500") or
+// a price level ("$$"), and places it on a price level from $ to $$$$.
+package budget
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/example/restosaas/apps/api/internal/money"
+)
+
+// Range is a budget per person. Both ends are zero when it is unknown.
+type Range struct {
+	Min money.Money `json:"min"`
+	Max money.Money `json:"max"`
+}
+
+// MaxLevel is the most expensive price level, $$$$
+const MaxLevel = 4
+
+// levelBounds are the budgets per person, in whole units, at which each
+// currency's price levels $$, $$$ and $$$$ start
+var levelBounds = map[string][MaxLevel - 1]int64{
+	"NPR": {500, 1500, 3000},
+	"INR": {300, 1000, 2000},
+	"JPY": {1000, 3000, 6000},
+	"CNY": {50, 150, 300},
+	"USD": {15, 30, 60},
+	"EUR": {15, 30, 60},
+	"GBP": {15, 30, 60},
+	"AUD": {20, 45, 90},
+}
+
+var amountPattern = regexp.MustCompile(`\d[\d,]*(?:\.\d+)?`)
+
+// Parse reads a budget written in currency. A price level gives the range
+// of that level (see LevelRange).
+func Parse(text, currency string) (Range, error) {
+	text = strings.TrimSpace(text)
+	if level, ok := ParseLevel(text); ok && strings.HasPrefix(text, "$") {
+		return LevelRange(level, currency), nil
+	}
+
+	amounts := amountPattern.FindAllString(text, -1)
+	if len(amounts) == 0 || len(amounts) > 2 || strings.HasPrefix(text, "-") {
+		return Range{}, fmt.Errorf("budget must be an amount or range per person, such as 500-1500, or a price level from $ to $$$$")
+	}
+	var r Range
+	for i, amount := range amounts {
+		m, err := money.Parse(strings.ReplaceAll(amount, ",", ""), currency)
+		if err != nil {
+			return Range{}, err
+		}
+		if i == 0 {
+			r.Min = m
+		}
+		r.Max = m
+	}
+	if r.Max.Amount < r.Min.Amount {
+		return Range{}, fmt.Errorf("budget %q ends below where it starts", text)
+	}
+	return r, nil
+}
+
+// ParseLevel reads a price level written as "$$" or "2"
+func ParseLevel(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	level := len(s)
+	if strings.Trim(s, "$") != "" {
+		if len(s) != 1 || s[0] < '1' || s[0] > '0'+MaxLevel {
+			return 0, false
+		}
+		level = int(s[0] - '0')
+	}
+	if level < 1 || level > MaxLevel {
+		return 0, false
+	}
+	return level, true
+}
+
+// Symbol writes a price level as "$" to "$$$$", empty when unknown
+func Symbol(level int) string {
+	return strings.Repeat("$", level)
+}
+
+// FromPrices estimates a budget from what the restaurant charges: from its
+// cheapest to its most expensive price. Free items are left out.
+func FromPrices(prices []money.Money, currency string) Range {
+	var r Range
+	for _, price := range prices {
+		if price.Amount <= 0 {
+			continue
+		}
+		price = price.Rescale(currency)
+		if r.Min.IsZero() || price.Amount < r.Min.Amount {
+			r.Min = price
+		}
+		if price.Amount > r.Max.Amount {
+			r.Max = price
+		}
+	}
+	return r
+}
+
+// Level places a budget on a price level by its midpoint, 0 when the
+// budget or its currency is unknown
+func Level(r Range) int {
+	bounds, ok := levelBounds[r.Max.Currency]
+	if !ok || r.Max.IsZero() {
+		return 0
+	}
+	mid := (r.Min.Amount + r.Max.Amount) / 2
+	level := 1
+	for _, bound := range bounds {
+		if mid >= money.FromMajor(bound, r.Max.Currency).Amount {
+			level++
+		}
+	}
+	return level
+}
+
+// LevelRange is the budget a price level stands for in currency. $$$$ has
+// no upper end, so it is taken to reach twice where it starts.
+func LevelRange(level int, currency string) Range {
+	bounds, ok := levelBounds[currency]
+	if !ok || level < 1 || level > MaxLevel {
+		return Range{}
+	}
+	edges := append([]int64{0}, bounds[:]...)
+	edges = append(edges, 2*bounds[len(bounds)-1])
+	return Range{
+		Min: money.FromMajor(edges[level-1], currency),
+		Max: money.FromMajor(edges[level], currency),
+	}
+}
+
+// Text writes a budget the way owners do, such as "500-1500"
+func Text(r Range) string {
+	if r.Max.IsZero() {
+		return ""
+	}
+	if r.Min.Amount == r.Max.Amount {
+		return amountText(r.Min)
+	}
+	return amountText(r.Min) + "-" + amountText(r.Max)
+}
+
+// amountText writes an amount without a fraction of zeros
+func amountText(m money.Money) string {
+	s := m.Decimal()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package budget
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func npr(rupees int64) money.Money {
+	return money.FromMajor(rupees, "NPR")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Range
+	}{
+		{"500-1500", Range{npr(500), npr(1500)}},
+		{"500 ~ 1500", Range{npr(500), npr(1500)}},
+		{"Rs 1,000 to Rs 2,500", Range{npr(1000), npr(2500)}},
+		{"1000", Range{npr(1000), npr(1000)}},
+		{"750.50", Range{money.New(75050, "NPR"), money.New(75050, "NPR")}},
+		{"$$", Range{npr(500), npr(1500)}},
+		{"$$$$", Range{npr(3000), npr(6000)}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in, "NPR")
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, bad := range []string{"", "cheap", "$$$$$", "1500-500", "1-2-3", "-500"} {
+		_, err := Parse(bad, "NPR")
+		assert.Error(t, err, bad)
+	}
+
+	got, err := Parse("$15-30", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, Range{money.FromMajor(15, "USD"), money.FromMajor(30, "USD")}, got)
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]int{"$": 1, " $$$ ": 3, "4": 4} {
+		got, ok := ParseLevel(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, bad := range []string{"", "0", "5", "$$$$$", "$2"} {
+		_, ok := ParseLevel(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, 0, Level(Range{}))
+	assert.Equal(t, 1, Level(Range{npr(200), npr(400)}))
+	assert.Equal(t, 2, Level(Range{npr(500), npr(1500)}))
+	assert.Equal(t, 3, Level(Range{npr(1500), npr(2000)}))
+	assert.Equal(t, 4, Level(Range{npr(5000), npr(5000)}))
+	assert.Equal(t, 2, Level(Range{money.New(1500, "JPY"), money.New(2500, "JPY")}))
+
+	// Every level's range is on that level
+	for level := 1; level <= MaxLevel; level++ {
+		assert.Equal(t, level, Level(LevelRange(level, "JPY")), Symbol(level))
+	}
+}
+
+func TestFromPrices(t *testing.T) {
+	got := FromPrices([]money.Money{npr(0), npr(450), npr(1200), money.New(80000, "NPR")}, "NPR")
+	assert.Equal(t, Range{npr(450), npr(1200)}, got)
+	assert.Equal(t, Range{}, FromPrices(nil, "NPR"))
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "500-1500", Text(Range{npr(500), npr(1500)}))
+	assert.Equal(t, "750.5", Text(Range{money.New(75050, "NPR"), money.New(75050, "NPR")}))
+	assert.Equal(t, "", Text(Range{}))
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/example/restosaas/apps/api/internal/budget"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"gorm.io/gorm"
+)
+
+// parseBudgets fills in the budget amounts and price level of restaurants
+// saved before budgets were stored as amounts, by reading their budget
+// text. Budgets that can't be read, such as "cheap", are left unknown.
+func parseBudgets(db *gorm.DB) error {
+	var restaurants []Restaurant
+	if err := db.Select("id", "budget", "currency").Where("price_level = 0 AND budget <> ''").Find(&restaurants).Error; err != nil {
+		return fmt.Errorf("failed to read restaurant budgets: %w", err)
+	}
+
+	for _, restaurant := range restaurants {
+		currency := restaurant.Currency
+		if currency == "" {
+			currency = money.Default
+		}
+		r, err := budget.Parse(restaurant.Budget, currency)
+		if err != nil || budget.Level(r) == 0 {
+			continue
+		}
+		if err := db.Model(&Restaurant{}).Where("id = ?", restaurant.ID).Updates(map[string]interface{}{
+			"budget_min_amount":   r.Min.Amount,
+			"budget_min_currency": r.Min.Currency,
+			"budget_max_amount":   r.Max.Amount,
+			"budget_max_currency": r.Max.Currency,
+			"price_level":         budget.Level(r),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update budget of restaurant %s: %w", restaurant.ID, err)
+		}
+	}
+	return nil
+}
@@ -18,6 +18,16 @@ func RunMigrations(db *gorm.DB) error {
 		return fmt.Errorf("failed to run custom migrations: %w", err)
 	}
 
+	// Move integer prices to money columns
+	if err := convertPricesToMoney(db); err != nil {
+		return fmt.Errorf("failed to convert prices: %w", err)
+	}
+
+	// Read budget amounts from budget text
+	if err := parseBudgets(db); err != nil {
+		return fmt.Errorf("failed to parse budgets: %w", err)
+	}
+
 	return nil
 }
 
@@ -113,6 +123,255 @@ func runCustomMigrations(db *gorm.DB) error {
 			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_restaurants_open_hours'`,
 			description: "Add foreign key constraint for restaurant_id in opening_hours",
 		},
+		{
+			name:        "map_org_member_roles",
+			query:       `UPDATE org_members SET role = CASE role WHEN 'SUPER_ADMIN' THEN 'OWNER' WHEN 'CUSTOMER' THEN 'HOST' ELSE role END WHERE role IN ('SUPER_ADMIN', 'CUSTOMER')`,
+			checkQuery:  `SELECT CASE WHEN EXISTS (SELECT 1 FROM org_members WHERE role IN ('SUPER_ADMIN', 'CUSTOMER')) THEN 0 ELSE 1 END`,
+			description: "Map global roles stored on org_members to organization roles",
+		},
+		{
+			name:        "add_foreign_key_user_identities_user",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_users_identities') THEN ALTER TABLE user_identities ADD CONSTRAINT fk_users_identities FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_users_identities'`,
+			description: "Add foreign key constraint for user_id in user_identities",
+		},
+		{
+			name:        "backfill_user_identities_from_users",
+			query:       `INSERT INTO user_identities (id, user_id, provider, subject, email, email_verified, avatar_url, created_at) SELECT gen_random_uuid(), id, oauth_provider, oauth_id, email, false, COALESCE(avatar_url, ''), created_at FROM users WHERE COALESCE(oauth_provider, '') <> '' AND COALESCE(oauth_id, '') <> '' ON CONFLICT DO NOTHING`,
+			checkQuery:  `SELECT COUNT(*) FROM user_identities`,
+			description: "Copy legacy users.oauth_provider/oauth_id pairs into user_identities",
+		},
+		{
+			name:        "add_audit_logs_append_only_function",
+			query:       `CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$ BEGIN RAISE EXCEPTION 'audit_logs is append-only'; END; $$ LANGUAGE plpgsql`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_proc WHERE proname = 'audit_logs_append_only'`,
+			description: "Add trigger function rejecting changes to audit_logs",
+		},
+		{
+			name:        "add_audit_logs_append_only_trigger",
+			query:       `CREATE TRIGGER trg_audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_trigger WHERE tgname = 'trg_audit_logs_append_only'`,
+			description: "Make audit_logs append-only",
+		},
+		{
+			name:        "add_foreign_key_invitations_org",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_invitations_org') THEN ALTER TABLE invitations ADD CONSTRAINT fk_invitations_org FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_invitations_org'`,
+			description: "Add foreign key constraint for org_id in invitations",
+		},
+		{
+			name:        "add_unique_pending_invitation_index",
+			query:       `CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_org_email_pending ON invitations(org_id, email) WHERE status = 'PENDING'`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'invitations' AND indexname = 'idx_invitations_org_email_pending'`,
+			description: "Allow one pending invitation per email and organization",
+		},
+		{
+			name:        "add_foreign_key_api_keys_org",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_api_keys_org') THEN ALTER TABLE api_keys ADD CONSTRAINT fk_api_keys_org FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_api_keys_org'`,
+			description: "Add foreign key constraint for org_id in api_keys",
+		},
+		{
+			name:        "add_foreign_key_menu_categories_restaurant",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_categories_restaurant') THEN ALTER TABLE menu_categories ADD CONSTRAINT fk_menu_categories_restaurant FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_categories_restaurant'`,
+			description: "Add foreign key constraint for restaurant_id in menu_categories",
+		},
+		{
+			name:        "add_foreign_key_menu_categories_parent",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_categories_parent') THEN ALTER TABLE menu_categories ADD CONSTRAINT fk_menu_categories_parent FOREIGN KEY (parent_id) REFERENCES menu_categories(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_categories_parent'`,
+			description: "Add foreign key constraint for parent_id in menu_categories",
+		},
+		{
+			name:        "add_foreign_key_menus_category",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menus_category') THEN ALTER TABLE menus ADD CONSTRAINT fk_menus_category FOREIGN KEY (category_id) REFERENCES menu_categories(id) ON DELETE SET NULL; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menus_category'`,
+			description: "Add foreign key constraint for category_id in menus",
+		},
+		{
+			name:        "add_foreign_key_menu_variants_menu",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_variants_menu') THEN ALTER TABLE menu_variants ADD CONSTRAINT fk_menu_variants_menu FOREIGN KEY (menu_id) REFERENCES menus(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_variants_menu'`,
+			description: "Add foreign key constraint for menu_id in menu_variants",
+		},
+		{
+			name:        "add_foreign_key_menu_option_groups_menu",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_option_groups_menu') THEN ALTER TABLE menu_option_groups ADD CONSTRAINT fk_menu_option_groups_menu FOREIGN KEY (menu_id) REFERENCES menus(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_option_groups_menu'`,
+			description: "Add foreign key constraint for menu_id in menu_option_groups",
+		},
+		{
+			name:        "add_foreign_key_menu_options_group",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_options_group') THEN ALTER TABLE menu_options ADD CONSTRAINT fk_menu_options_group FOREIGN KEY (group_id) REFERENCES menu_option_groups(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_options_group'`,
+			description: "Add foreign key constraint for group_id in menu_options",
+		},
+		{
+			name:        "add_dietary_tags_index_to_menus",
+			query:       `CREATE INDEX IF NOT EXISTS idx_menus_dietary_tags ON menus USING GIN (dietary_tags)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'menus' AND indexname = 'idx_menus_dietary_tags'`,
+			description: "Add GIN index on dietary_tags column of menus",
+		},
+		{
+			name:        "add_allergens_index_to_menus",
+			query:       `CREATE INDEX IF NOT EXISTS idx_menus_allergens ON menus USING GIN (allergens)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'menus' AND indexname = 'idx_menus_allergens'`,
+			description: "Add GIN index on allergens column of menus",
+		},
+		{
+			name:        "add_dietary_tags_index_to_courses",
+			query:       `CREATE INDEX IF NOT EXISTS idx_courses_dietary_tags ON courses USING GIN (dietary_tags)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'courses' AND indexname = 'idx_courses_dietary_tags'`,
+			description: "Add GIN index on dietary_tags column of courses",
+		},
+		{
+			name:        "add_sku_index_to_menus",
+			query:       `CREATE UNIQUE INDEX IF NOT EXISTS idx_menus_restaurant_sku ON menus(restaurant_id, sku) WHERE sku <> ''`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'menus' AND indexname = 'idx_menus_restaurant_sku'`,
+			description: "Add partial unique index on restaurant_id and sku of menus",
+		},
+		{
+			name:        "add_foreign_key_menu_availabilities_restaurant",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_availabilities_restaurant') THEN ALTER TABLE menu_availabilities ADD CONSTRAINT fk_menu_availabilities_restaurant FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_availabilities_restaurant'`,
+			description: "Add foreign key constraint for restaurant_id in menu_availabilities",
+		},
+		{
+			name:        "add_foreign_key_menu_availabilities_menu",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_availabilities_menu') THEN ALTER TABLE menu_availabilities ADD CONSTRAINT fk_menu_availabilities_menu FOREIGN KEY (menu_id) REFERENCES menus(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_availabilities_menu'`,
+			description: "Add foreign key constraint for menu_id in menu_availabilities",
+		},
+		{
+			name:        "add_foreign_key_menu_availabilities_category",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_availabilities_category') THEN ALTER TABLE menu_availabilities ADD CONSTRAINT fk_menu_availabilities_category FOREIGN KEY (category_id) REFERENCES menu_categories(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_availabilities_category'`,
+			description: "Add foreign key constraint for category_id in menu_availabilities",
+		},
+		{
+			name:        "add_foreign_key_menu_versions_restaurant",
+			query:       `DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_versions_restaurant') THEN ALTER TABLE menu_versions ADD CONSTRAINT fk_menu_versions_restaurant FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE; END IF; END $$`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.table_constraints WHERE constraint_name = 'fk_menu_versions_restaurant'`,
+			description: "Add foreign key constraint for restaurant_id in menu_versions",
+		},
+		{
+			name:        "add_number_index_to_menu_versions",
+			query:       `CREATE UNIQUE INDEX IF NOT EXISTS idx_menu_versions_restaurant_number ON menu_versions(restaurant_id, number)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'menu_versions' AND indexname = 'idx_menu_versions_restaurant_number'`,
+			description: "Add unique index on restaurant_id and number of menu_versions",
+		},
+		{
+			name:        "add_search_vector_column_to_restaurants",
+			query:       `ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+			checkQuery:  `SELECT COUNT(*) FROM information_schema.columns WHERE table_name = 'restaurants' AND column_name = 'search_vector'`,
+			description: "Add full-text search_vector column to restaurants",
+		},
+		{
+			name: "add_restaurant_search_vector_function",
+			query: `CREATE OR REPLACE FUNCTION restaurant_search_vector(r restaurants) RETURNS tsvector AS $$
+				SELECT setweight(to_tsvector('simple', coalesce(r.name, '')), 'A')
+					|| setweight(to_tsvector('simple', concat_ws(' ', r.genre, r.place, r.area)), 'B')
+					|| setweight(to_tsvector('simple', concat_ws(' ', r.slogan, r.title, r.description,
+						(SELECT string_agg(f.value, ' ') FROM jsonb_each(r.translations) AS t, jsonb_each_text(t.value) AS f))), 'C')
+					|| setweight(to_tsvector('simple', concat_ws(' ',
+						(SELECT string_agg(name, ' ') FROM menus WHERE restaurant_id = r.id),
+						(SELECT string_agg(title, ' ') FROM courses WHERE restaurant_id = r.id))), 'D')
+			$$ LANGUAGE sql STABLE`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_proc WHERE proname = 'restaurant_search_vector'`,
+			description: "Add function building a restaurant's search vector from its text, menu items and courses",
+		},
+		{
+			name: "add_restaurants_search_vector_trigger_function",
+			query: `CREATE OR REPLACE FUNCTION restaurants_search_vector_update() RETURNS trigger AS $$
+			BEGIN
+				NEW.search_vector := restaurant_search_vector(NEW);
+				RETURN NEW;
+			END $$ LANGUAGE plpgsql`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_proc WHERE proname = 'restaurants_search_vector_update'`,
+			description: "Add trigger function keeping search_vector current when restaurants change",
+		},
+		{
+			name:        "add_restaurants_search_vector_trigger",
+			query:       `CREATE TRIGGER restaurants_search_vector BEFORE INSERT OR UPDATE OF name, slogan, title, description, genre, place, area, translations ON restaurants FOR EACH ROW EXECUTE FUNCTION restaurants_search_vector_update()`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_trigger WHERE tgname = 'restaurants_search_vector'`,
+			description: "Add trigger updating search_vector of changed restaurants",
+		},
+		{
+			name: "add_restaurant_items_search_vector_trigger_function",
+			query: `CREATE OR REPLACE FUNCTION restaurant_items_search_vector_update() RETURNS trigger AS $$
+			BEGIN
+				IF TG_OP <> 'INSERT' THEN
+					UPDATE restaurants SET search_vector = restaurant_search_vector(restaurants) WHERE id = OLD.restaurant_id;
+				END IF;
+				IF TG_OP <> 'DELETE' THEN
+					UPDATE restaurants SET search_vector = restaurant_search_vector(restaurants) WHERE id = NEW.restaurant_id;
+				END IF;
+				RETURN NULL;
+			END $$ LANGUAGE plpgsql`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_proc WHERE proname = 'restaurant_items_search_vector_update'`,
+			description: "Add trigger function updating a restaurant's search_vector when its menu items or courses change",
+		},
+		{
+			name:        "add_menus_search_vector_trigger",
+			query:       `CREATE TRIGGER menus_search_vector AFTER INSERT OR DELETE OR UPDATE OF name, restaurant_id ON menus FOR EACH ROW EXECUTE FUNCTION restaurant_items_search_vector_update()`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_trigger WHERE tgname = 'menus_search_vector'`,
+			description: "Add trigger updating search_vector of restaurants whose menu items change",
+		},
+		{
+			name:        "add_courses_search_vector_trigger",
+			query:       `CREATE TRIGGER courses_search_vector AFTER INSERT OR DELETE OR UPDATE OF title, restaurant_id ON courses FOR EACH ROW EXECUTE FUNCTION restaurant_items_search_vector_update()`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_trigger WHERE tgname = 'courses_search_vector'`,
+			description: "Add trigger updating search_vector of restaurants whose courses change",
+		},
+		{
+			name:        "backfill_search_vector_of_restaurants",
+			query:       `UPDATE restaurants SET search_vector = restaurant_search_vector(restaurants) WHERE search_vector IS NULL`,
+			checkQuery:  `SELECT CASE WHEN EXISTS (SELECT 1 FROM restaurants WHERE search_vector IS NULL) THEN 0 ELSE 1 END`,
+			description: "Fill in search_vector of existing restaurants",
+		},
+		{
+			name:        "add_search_vector_index_to_restaurants",
+			query:       `CREATE INDEX IF NOT EXISTS idx_restaurants_search_vector ON restaurants USING GIN (search_vector)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'restaurants' AND indexname = 'idx_restaurants_search_vector'`,
+			description: "Add GIN index on search_vector of restaurants",
+		},
+		{
+			name:        "enable_pg_trgm_extension",
+			query:       `CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_extension WHERE extname = 'pg_trgm'`,
+			description: "Enable trigram matching for search suggestions",
+		},
+		{
+			name:        "add_name_trigram_index_to_restaurants",
+			query:       `CREATE INDEX IF NOT EXISTS idx_restaurants_name_trgm ON restaurants USING GIN (name gin_trgm_ops)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'restaurants' AND indexname = 'idx_restaurants_name_trgm'`,
+			description: "Add trigram index on name of restaurants",
+		},
+		{
+			name:        "add_genre_trigram_index_to_restaurants",
+			query:       `CREATE INDEX IF NOT EXISTS idx_restaurants_genre_trgm ON restaurants USING GIN (genre gin_trgm_ops)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'restaurants' AND indexname = 'idx_restaurants_genre_trgm'`,
+			description: "Add trigram index on genre of restaurants",
+		},
+		{
+			name:        "add_area_trigram_index_to_restaurants",
+			query:       `CREATE INDEX IF NOT EXISTS idx_restaurants_area_trgm ON restaurants USING GIN (area gin_trgm_ops)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'restaurants' AND indexname = 'idx_restaurants_area_trgm'`,
+			description: "Add trigram index on area of restaurants",
+		},
+		{
+			name:        "add_place_trigram_index_to_restaurants",
+			query:       `CREATE INDEX IF NOT EXISTS idx_restaurants_place_trgm ON restaurants USING GIN (place gin_trgm_ops)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'restaurants' AND indexname = 'idx_restaurants_place_trgm'`,
+			description: "Add trigram index on place of restaurants",
+		},
+		{
+			name:        "add_name_trigram_index_to_menus",
+			query:       `CREATE INDEX IF NOT EXISTS idx_menus_name_trgm ON menus USING GIN (name gin_trgm_ops)`,
+			checkQuery:  `SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'menus' AND indexname = 'idx_menus_name_trgm'`,
+			description: "Add trigram index on name of menus",
+		},
 	}
 
 	for _, migration := range migrations {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/example/restosaas/apps/api/internal/money"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -33,6 +34,27 @@ const (
 	RoleCustomer Role = "CUSTOMER"
 )
 
+// OrgRole is a member's role within one organization. It is independent of
+// the user's global Role.
+type OrgRole string
+
+const (
+	OrgRoleOwner     OrgRole = "OWNER"
+	OrgRoleManager   OrgRole = "MANAGER"
+	OrgRoleHost      OrgRole = "HOST"
+	OrgRoleKitchen   OrgRole = "KITCHEN"
+	OrgRoleMarketing OrgRole = "MARKETING"
+)
+
+// Valid reports whether r is a known organization role
+func (r OrgRole) Valid() bool {
+	switch r {
+	case OrgRoleOwner, OrgRoleManager, OrgRoleHost, OrgRoleKitchen, OrgRoleMarketing:
+		return true
+	}
+	return false
+}
+
 type OAuthProvider string
 
 const (
@@ -48,10 +70,13 @@ type User struct {
 	DisplayName string
 	Role        Role `gorm:"type:text;not null"`
 	CreatedAt   time.Time
-	// OAuth fields
+	// Legacy single-provider OAuth fields, superseded by UserIdentity.
+	// Kept so existing rows can be backfilled; no longer written.
 	OAuthProvider string `gorm:"column:oauth_provider"` // google, facebook, twitter
 	OAuthID       string `gorm:"column:oauth_id"`       // OAuth provider's user ID
 	AvatarURL     string `gorm:"column:avatar_url"`     // Profile picture from OAuth
+	// Relationships
+	Identities []UserIdentity `gorm:"foreignKey:UserID"`
 }
 
 // TableName explicitly sets the table name for GORM
@@ -59,6 +84,34 @@ func (User) TableName() string {
 	return "users"
 }
 
+// UserIdentity links an external login (Google, Facebook, ...) to a user.
+// A user can have one identity per provider.
+type UserIdentity struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_identities_user_provider"`
+	Provider      string    `gorm:"not null;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject       string    `gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"` // Provider's user ID
+	Email         string
+	EmailVerified bool `gorm:"not null;default:false"`
+	AvatarURL     string
+	CreatedAt     time.Time
+	LastLoginAt   *time.Time
+}
+
+// OAuthState is a server-issued authorization request waiting for its
+// callback. Each state is single use and carries the PKCE verifier.
+type OAuthState struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	State        string     `gorm:"uniqueIndex;not null"`
+	Provider     string     `gorm:"not null"`
+	CodeVerifier string     `gorm:"not null"`
+	Nonce        string     // OpenID Connect nonce expected in the ID token
+	RedirectURI  string     `gorm:"not null"`
+	UserID       *uuid.UUID `gorm:"type:uuid"` // Set when linking a provider to a signed-in user
+	ExpiresAt    time.Time  `gorm:"index;not null"`
+	CreatedAt    time.Time
+}
+
 type Organization struct {
 	ID                 uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Name               string    `gorm:"not null"`
@@ -71,10 +124,61 @@ type OrgMember struct {
 	ID     uuid.UUID `gorm:"type:uuid;primaryKey"`
 	UserID uuid.UUID `gorm:"type:uuid;index;not null"`
 	OrgID  uuid.UUID `gorm:"type:uuid;index;not null"`
-	Role   Role      `gorm:"type:text;not null"`
+	Role   OrgRole   `gorm:"type:text;not null"`
 	// Foreign key constraints will be added manually after migration
 }
 
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "PENDING"
+	InvitationAccepted InvitationStatus = "ACCEPTED"
+	InvitationDeclined InvitationStatus = "DECLINED"
+	InvitationRevoked  InvitationStatus = "REVOKED"
+)
+
+// Invitation asks someone, by email, to join an organization with a role.
+// The emailed token embeds Nonce; resending rotates it so older links stop
+// working.
+type Invitation struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey"`
+	OrgID       uuid.UUID        `gorm:"type:uuid;index;not null"`
+	Email       string           `gorm:"not null;index"` // Stored lower case
+	Role        OrgRole          `gorm:"type:text;not null"`
+	Status      InvitationStatus `gorm:"type:text;not null;default:PENDING"`
+	Nonce       string           `gorm:"not null"`
+	InvitedBy   *uuid.UUID       `gorm:"type:uuid"`
+	AcceptedBy  *uuid.UUID       `gorm:"type:uuid"`
+	ExpiresAt   time.Time        `gorm:"not null"`
+	SentAt      time.Time        // Last time the invitation email was sent
+	RespondedAt *time.Time       // Accepted, declined or revoked
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// APIKey lets a machine client act on one organization with a fixed set
+// of scopes. Only the SHA-256 hash of the key is stored; Prefix is the
+// public part used to find it.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrgID      uuid.UUID  `gorm:"type:uuid;index;not null"`
+	Name       string     `gorm:"not null"`
+	Prefix     string     `gorm:"uniqueIndex;not null"`
+	Hash       string     `gorm:"not null"`
+	Scopes     StringList `gorm:"type:jsonb;not null"`
+	CreatedBy  *uuid.UUID `gorm:"type:uuid"`
+	LastUsedAt *time.Time
+	ExpiresAt  *time.Time
+	RotatedAt  *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// TableName keeps the table name readable (GORM would use a_p_i_keys)
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
 type Restaurant struct {
 	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
 	OrgID       uuid.UUID `gorm:"type:uuid;index;not null"`
@@ -83,7 +187,7 @@ type Restaurant struct {
 	Slogan      string    `gorm:"not null"`
 	Place       string    `gorm:"not null;index"` // Near city or some place
 	Genre       string    `gorm:"not null;index"` // Cuisine type
-	Budget      string    `gorm:"not null"`       // Budget range (e.g., "500-1500")
+	Budget      string    `gorm:"not null"`       // Budget range as written (e.g., "500-1500"); see BudgetMin
 	Title       string    `gorm:"not null"`       // Restaurant title
 	Description string    `gorm:"type:text"`
 	Area        string    `gorm:"index"` // Area within the place
@@ -93,6 +197,31 @@ type Restaurant struct {
 	Capacity    int64      `gorm:"not null;default:30"`                  // Changed to int64 to match database
 	IsOpen      bool       `gorm:"column:is_open;not null;default:true"` // Optional if restaurant is closed
 	MainImageID *uuid.UUID `gorm:"type:uuid"`                            // Reference to main image
+
+	// Name, slogan, title and description by locale (see package i18n)
+	Translations Translations `gorm:"type:jsonb;not null;default:'{}'"`
+
+	// Prices of the restaurant's menus and courses are in Currency. Tax and
+	// service charge rates are in basis points (1300 = 13%); see
+	// money.Pricing.
+	Currency         string `gorm:"type:varchar(3);not null;default:'NPR'"`
+	TaxRate          int    `gorm:"not null;default:0"`
+	ServiceCharge    int    `gorm:"not null;default:0"`
+	PricesIncludeTax bool   `gorm:"not null;default:false"`
+
+	// Budget per person, parsed from Budget or estimated from menu and
+	// course prices (zero when unknown), and its price level from 1 ($) to
+	// 4 ($$$$), 0 when unknown; see package budget
+	BudgetMin  money.Money `gorm:"embedded;embeddedPrefix:budget_min_"`
+	BudgetMax  money.Money `gorm:"embedded;embeddedPrefix:budget_max_"`
+	PriceLevel int         `gorm:"not null;default:0;index"`
+
+	// Position on the map in degrees, nil until set by the owner or
+	// geocoded from Address and Place (Geocoded); see package geo
+	Latitude  *float64 `gorm:"index:idx_restaurants_location"`
+	Longitude *float64 `gorm:"index:idx_restaurants_location"`
+	Geocoded  bool     `gorm:"not null;default:false"`
+
 	// Relationships
 	Images       []Image       `gorm:"foreignKey:RestaurantID"`
 	OpenHours    []OpeningHour `gorm:"foreignKey:RestaurantID"`
@@ -117,6 +246,24 @@ func (OpeningHour) TableName() string {
 	return "opening_hours"
 }
 
+// SpecialHour replaces a restaurant's opening hours on one date, such as a
+// holiday it is closed on or a festival night it stays open late for
+type SpecialHour struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RestaurantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_special_hours_restaurant_date"`
+	Date         string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_special_hours_restaurant_date"` // Format: "2025-10-24", in the restaurant's timezone
+	OpenTime     string    `gorm:"column:open_time"`                                                        // Format: "09:00", empty when closed
+	CloseTime    string    `gorm:"column:close_time"`                                                       // Format: "23:00"; before OpenTime for hours past midnight
+	IsClosed     bool      `gorm:"column:is_closed;not null;default:false"`
+	Note         string    // Shown to customers, e.g. "Closed for Dashain"
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (SpecialHour) TableName() string {
+	return "special_hours"
+}
+
 type MenuType string
 type MealType string
 
@@ -131,15 +278,125 @@ const (
 	MealTypeBoth   MealType = "BOTH"
 )
 
+// MenuCategory is a section of a restaurant's menu (Starters, Momo, Beer).
+// Categories nest through ParentID and are ordered among their siblings by
+// SortOrder.
+type MenuCategory struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	RestaurantID uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ParentID     *uuid.UUID `gorm:"type:uuid;index"` // Nil for top-level sections
+	Name         string     `gorm:"not null"`
+	Description  string     `gorm:"type:text"`
+	SortOrder    int        `gorm:"not null;default:0"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
 type Menu struct {
-	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
-	RestaurantID uuid.UUID `gorm:"type:uuid;index;not null"`
-	Name         string    `gorm:"not null"`  // Title/Name of the menu item
-	ShortDesc    string    `gorm:"type:text"` // Short description
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	RestaurantID uuid.UUID  `gorm:"type:uuid;index;not null"`
+	CategoryID   *uuid.UUID `gorm:"type:uuid;index"` // Nil for uncategorized items
+	Name         string     `gorm:"not null"`        // Title/Name of the menu item
+	ShortDesc    string     `gorm:"type:text"`       // Short description
 	ImageURL     string
-	Price        int      `gorm:"not null"`
-	Type         MenuType `gorm:"type:text;not null"` // DRINK or FOOD
-	MealType     MealType `gorm:"type:text;not null"` // LUNCH, DINNER, or BOTH
+	SKU          string     `gorm:"not null;default:''"`              // Restaurant's own code, unique when set; imports match on it
+	Type         MenuType   `gorm:"type:text;not null"`               // DRINK or FOOD
+	MealType     MealType   `gorm:"type:text;not null"`               // LUNCH, DINNER, or BOTH
+	SortOrder    int        `gorm:"not null;default:0"`               // Order within the category
+	DietaryTags  StringList `gorm:"type:jsonb;not null;default:'[]'"` // dietary.Tags, e.g. VEGAN
+	Allergens    StringList `gorm:"type:jsonb;not null;default:'[]'"` // dietary.Allergens, e.g. NUTS
+	SpiceLevel   int        `gorm:"not null;default:0"`               // 0 (not spicy) to 3 (hot)
+	SoldOutUntil *time.Time // Set when the item is 86'd; it is back on sale from then on
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	// Name and shortDesc by locale (see package i18n)
+	Translations Translations `gorm:"type:jsonb;not null;default:'{}'"`
+
+	// In the restaurant's currency; for items with variants, the lowest
+	// variant price
+	Price money.Money `gorm:"embedded;embeddedPrefix:price_"`
+
+	// Relations
+	Variants     []MenuVariant     `gorm:"foreignKey:MenuID"`
+	OptionGroups []MenuOptionGroup `gorm:"foreignKey:MenuID"`
+}
+
+// MenuVariant is a priced version of a menu item, such as a 10 or 20 piece
+// plate. An item with variants is sold at a variant's price rather than
+// Menu.Price.
+type MenuVariant struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primaryKey"`
+	MenuID    uuid.UUID   `gorm:"type:uuid;index;not null"`
+	Name      string      `gorm:"not null"`
+	Price     money.Money `gorm:"embedded;embeddedPrefix:price_"`
+	IsDefault bool        `gorm:"not null;default:false"`
+	SortOrder int         `gorm:"not null;default:0"`
+}
+
+// MenuOptionGroup is a set of choices for a menu item (Style: steamed,
+// fried, jhol). Between MinSelect and MaxSelect options must be chosen.
+type MenuOptionGroup struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	MenuID    uuid.UUID `gorm:"type:uuid;index;not null"`
+	Name      string    `gorm:"not null"`
+	MinSelect int       `gorm:"not null;default:0"`
+	MaxSelect int       `gorm:"not null;default:1"`
+	SortOrder int       `gorm:"not null;default:0"`
+
+	// Relations
+	Options []MenuOption `gorm:"foreignKey:GroupID"`
+}
+
+// MenuAvailability is a window in which a menu item, or every item of a
+// category, can be ordered. Items with no rules of their own follow the
+// rules of their nearest category that has some; with no rules at all they
+// are always available. Times are in the restaurant's timezone.
+type MenuAvailability struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	RestaurantID uuid.UUID  `gorm:"type:uuid;index;not null"`
+	MenuID       *uuid.UUID `gorm:"type:uuid;index"`      // Set for item rules
+	CategoryID   *uuid.UUID `gorm:"type:uuid;index"`      // Set for category rules
+	Weekdays     int        `gorm:"not null;default:127"` // Bit mask, bit 0 = Sunday ... bit 6 = Saturday
+	StartTime    string     // Format: "11:00", empty for the whole day
+	EndTime      string     // Format: "15:00"; before StartTime for windows past midnight
+	StartDate    string     // Format: "2025-12-01", empty for no start (seasonal items)
+	EndDate      string     // Format: "2026-02-28", inclusive, empty for no end
+}
+
+// MenuOption is one choice of an option group. PriceDelta is added to the
+// item price when it is chosen and may be negative.
+type MenuOption struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primaryKey"`
+	GroupID    uuid.UUID   `gorm:"type:uuid;index;not null"`
+	Name       string      `gorm:"not null"`
+	PriceDelta money.Money `gorm:"embedded;embeddedPrefix:price_delta_"`
+	IsDefault  bool        `gorm:"not null;default:false"`
+	SortOrder  int         `gorm:"not null;default:0"`
+}
+
+type MenuVersionStatus string
+
+const (
+	MenuVersionDraft     MenuVersionStatus = "DRAFT"
+	MenuVersionScheduled MenuVersionStatus = "SCHEDULED"
+	MenuVersionPublished MenuVersionStatus = "PUBLISHED" // The live menu; one per restaurant
+	MenuVersionArchived  MenuVersionStatus = "ARCHIVED"  // Published before; can be rolled back to
+)
+
+// MenuVersion is a snapshot of a restaurant's categories and items. Drafts
+// are edited without touching the live menu and are published, now or at
+// PublishAt, by replacing the live menu with the snapshot.
+type MenuVersion struct {
+	ID           uuid.UUID         `gorm:"type:uuid;primaryKey"`
+	RestaurantID uuid.UUID         `gorm:"type:uuid;index;not null"`
+	Number       int               `gorm:"not null"` // 1, 2, ... per restaurant
+	Name         string            `gorm:"not null"`
+	Status       MenuVersionStatus `gorm:"type:text;not null;index"`
+	Snapshot     JSON              `gorm:"type:jsonb;not null"`
+	PublishAt    *time.Time        `gorm:"index"` // When a SCHEDULED version goes live
+	PublishedAt  *time.Time
+	CreatedBy    *uuid.UUID `gorm:"type:uuid"` // User or API key; nil for versions recorded by the system
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
@@ -150,14 +407,24 @@ type Course struct {
 	Title         string    `gorm:"not null"`
 	Description   string
 	ImageURL      string
-	CoursePrice   int    `gorm:"not null"`     // Current price
-	OriginalPrice *int   `gorm:"default:null"` // Optional original price for strikethrough
-	NumberOfItems int    `gorm:"not null;default:1"`
-	StayTime      int    `gorm:"not null"`  // Stay time in minutes
-	CourseContent string `gorm:"type:text"` // Rich text content
-	Precautions   string `gorm:"type:text"` // Precautions items
+	NumberOfItems int        `gorm:"not null;default:1"`
+	StayTime      int        `gorm:"not null"`                         // Stay time in minutes
+	CourseContent string     `gorm:"type:text"`                        // Rich text content
+	Precautions   string     `gorm:"type:text"`                        // Precautions items
+	DietaryTags   StringList `gorm:"type:jsonb;not null;default:'[]'"` // dietary.Tags, e.g. VEGAN
+	Allergens     StringList `gorm:"type:jsonb;not null;default:'[]'"` // dietary.Allergens, e.g. NUTS
+	SpiceLevel    int        `gorm:"not null;default:0"`               // 0 (not spicy) to 3 (hot)
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+
+	// Title, description, courseContent and precautions by locale (see
+	// package i18n)
+	Translations Translations `gorm:"type:jsonb;not null;default:'{}'"`
+
+	// In the restaurant's currency. OriginalPrice is shown struck through
+	// and is zero when there is none.
+	CoursePrice   money.Money `gorm:"embedded;embeddedPrefix:course_price_"`
+	OriginalPrice money.Money `gorm:"embedded;embeddedPrefix:original_price_"`
 }
 
 type Image struct {
@@ -212,6 +479,26 @@ type Review struct {
 	UpdatedAt    time.Time
 }
 
+// AuditLog records one administrative or owner action. Rows are append
+// only; a trigger rejects updates and deletes.
+type AuditLog struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	ActorID    *uuid.UUID `gorm:"type:uuid;index"` // User, or API key when ActorRole is API_KEY; nil for system actions
+	ActorRole  string     `gorm:"type:text"`       // Effective role: organization role when acting in one, otherwise the global role
+	OrgID      *uuid.UUID `gorm:"type:uuid;index"` // Organization the action applies to, if any
+	Action     string     `gorm:"type:text;not null;index"`
+	EntityType string     `gorm:"type:text;not null;index:idx_audit_logs_entity"`
+	EntityID   string     `gorm:"type:text;index:idx_audit_logs_entity"`
+	Before     JSON       `gorm:"type:jsonb"` // Changed fields before the action
+	After      JSON       `gorm:"type:jsonb"` // Changed fields after the action
+	IP         string     `gorm:"type:text"`
+	UserAgent  string     `gorm:"type:text"`
+	Method     string     `gorm:"type:text"`
+	Path       string     `gorm:"type:text"`
+	RequestID  string     `gorm:"type:text"`
+	CreatedAt  time.Time  `gorm:"index;not null"`
+}
+
 type LandingPage struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Slug      string    `gorm:"uniqueIndex;not null"`
@@ -243,17 +530,29 @@ func AutoMigrate(db *gorm.DB) error {
 	// Use GORM's AutoMigrate to create tables with proper relationships
 	if err := db.AutoMigrate(
 		&User{},
+		&UserIdentity{},
+		&OAuthState{},
 		&Organization{},
 		&OrgMember{},
+		&Invitation{},
+		&APIKey{},
 		&Restaurant{},
 		&OpeningHour{},
+		&SpecialHour{},
+		&MenuCategory{},
 		&Menu{},
+		&MenuVariant{},
+		&MenuOptionGroup{},
+		&MenuOption{},
+		&MenuAvailability{},
+		&MenuVersion{},
 		&Course{},
 		&Image{},
 		&Customer{},
 		&Reservation{},
 		&Review{},
 		&LandingPage{},
+		&AuditLog{},
 	); err != nil {
 		return fmt.Errorf("failed to run auto migration: %w", err)
 	}
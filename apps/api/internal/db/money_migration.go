@@ -0,0 +1,108 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/example/restosaas/apps/api/internal/money"
+	"gorm.io/gorm"
+)
+
+// moneyColumns are the columns that held prices as paisa before prices
+// were stored as money, in <column>_amount and <column>_currency
+var moneyColumns = []struct {
+	table  string
+	column string
+}{
+	{"menus", "price"},
+	{"menu_variants", "price"},
+	{"menu_options", "price_delta"},
+	{"courses", "course_price"},
+	{"courses", "original_price"},
+}
+
+// convertPricesToMoney moves prices, already stored in paisa, into the
+// money columns in the default currency, drops the old columns and
+// converts the prices saved in menu version snapshots. Columns already
+// converted are skipped, so it is safe to run on every start.
+func convertPricesToMoney(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		converted := false
+		for _, c := range moneyColumns {
+			var count int64
+			if err := tx.Raw(`SELECT COUNT(*) FROM information_schema.columns WHERE table_name = ? AND column_name = ?`, c.table, c.column).Scan(&count).Error; err != nil {
+				return fmt.Errorf("failed to check %s.%s: %w", c.table, c.column, err)
+			}
+			if count == 0 {
+				continue
+			}
+
+			update := fmt.Sprintf(`UPDATE %[1]s SET %[2]s_amount = COALESCE(%[2]s, 0), %[2]s_currency = ?`, c.table, c.column)
+			if err := tx.Exec(update, money.Default).Error; err != nil {
+				return fmt.Errorf("failed to convert %s.%s: %w", c.table, c.column, err)
+			}
+			if err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s DROP COLUMN %s`, c.table, c.column)).Error; err != nil {
+				return fmt.Errorf("failed to drop %s.%s: %w", c.table, c.column, err)
+			}
+			converted = true
+		}
+		if !converted {
+			return nil
+		}
+
+		var versions []MenuVersion
+		if err := tx.Select("id", "snapshot").Find(&versions).Error; err != nil {
+			return fmt.Errorf("failed to read menu versions: %w", err)
+		}
+		for _, version := range versions {
+			snapshot, err := convertSnapshotPrices(version.Snapshot)
+			if err != nil {
+				return fmt.Errorf("failed to convert menu version %s: %w", version.ID, err)
+			}
+			if err := tx.Model(&MenuVersion{}).Where("id = ?", version.ID).Update("snapshot", snapshot).Error; err != nil {
+				return fmt.Errorf("failed to update menu version %s: %w", version.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// convertSnapshotPrices rewrites the numeric prices of a menu version
+// snapshot (items, variants and option deltas), in paisa, as money
+func convertSnapshotPrices(raw JSON) (JSON, error) {
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, err
+	}
+
+	toMoney := func(object interface{}, key string) {
+		fields, ok := object.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if amount, ok := fields[key].(float64); ok {
+			fields[key] = money.New(int64(math.Round(amount)), money.Default)
+		}
+	}
+	list := func(object interface{}, key string) []interface{} {
+		if fields, ok := object.(map[string]interface{}); ok {
+			values, _ := fields[key].([]interface{})
+			return values
+		}
+		return nil
+	}
+
+	for _, item := range list(snapshot, "items") {
+		toMoney(item, "price")
+		for _, variant := range list(item, "variants") {
+			toMoney(variant, "price")
+		}
+		for _, group := range list(item, "optionGroups") {
+			for _, option := range list(group, "options") {
+				toMoney(option, "priceDelta")
+			}
+		}
+	}
+	return json.Marshal(snapshot)
+}
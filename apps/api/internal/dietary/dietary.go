@@ -0,0 +1,141 @@
+// Package dietary defines the diet tags and allergens that menu items and
+// courses are labelled with, and how labels are parsed and checked.
+package dietary
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+)
+
+// Diet tags describe who can eat a dish
+const (
+	Vegetarian = "VEGETARIAN"
+	Vegan      = "VEGAN"
+	Jain       = "JAIN" // No meat, eggs, onion, garlic or root vegetables
+	Halal      = "HALAL"
+)
+
+// Allergens a dish contains
+const (
+	Gluten    = "GLUTEN"
+	Nuts      = "NUTS" // Tree nuts and peanuts
+	Dairy     = "DAIRY"
+	Egg       = "EGG"
+	Soy       = "SOY"
+	Fish      = "FISH"
+	Shellfish = "SHELLFISH"
+	Sesame    = "SESAME"
+)
+
+// Spice levels, from not spicy to hot
+const (
+	SpiceNone   = 0
+	SpiceMild   = 1
+	SpiceMedium = 2
+	SpiceHot    = 3
+)
+
+// Tags and Allergens list the taxonomy in display order
+var (
+	Tags      = []string{Vegetarian, Vegan, Jain, Halal}
+	Allergens = []string{Gluten, Nuts, Dairy, Egg, Soy, Fish, Shellfish, Sesame}
+)
+
+// implied lists the tags a tag implies: every vegan or Jain dish is also
+// vegetarian, so a vegetarian filter finds them
+var implied = map[string][]string{
+	Vegan: {Vegetarian},
+	Jain:  {Vegetarian},
+}
+
+// ErrSpiceLevel is returned for a spice level outside SpiceNone..SpiceHot
+var ErrSpiceLevel = fmt.Errorf("spice level must be between %d and %d", SpiceNone, SpiceHot)
+
+// ParseTags validates diet tags, case-insensitively, and returns them in
+// taxonomy order without duplicates, including the tags they imply
+func ParseTags(values []string) (db.StringList, error) {
+	set, err := parse(values, Tags, "diet tag")
+	if err != nil {
+		return nil, err
+	}
+	for tag := range set {
+		for _, t := range implied[tag] {
+			set[t] = true
+		}
+	}
+	return ordered(set, Tags), nil
+}
+
+// ParseAllergens validates allergens, case-insensitively, and returns them
+// in taxonomy order without duplicates
+func ParseAllergens(values []string) (db.StringList, error) {
+	set, err := parse(values, Allergens, "allergen")
+	if err != nil {
+		return nil, err
+	}
+	return ordered(set, Allergens), nil
+}
+
+// Check reports labels that contradict each other, such as a vegan dish
+// containing dairy
+func Check(tags, allergens db.StringList, spiceLevel int) error {
+	if spiceLevel < SpiceNone || spiceLevel > SpiceHot {
+		return ErrSpiceLevel
+	}
+	if contains(tags, Vegan) && (contains(allergens, Dairy) || contains(allergens, Egg)) {
+		return errors.New("a vegan dish cannot contain dairy or egg")
+	}
+	if (contains(tags, Vegetarian) || contains(tags, Jain)) && (contains(allergens, Fish) || contains(allergens, Shellfish)) {
+		return errors.New("a vegetarian dish cannot contain fish or shellfish")
+	}
+	if contains(tags, Jain) && contains(allergens, Egg) {
+		return errors.New("a Jain dish cannot contain egg")
+	}
+	return nil
+}
+
+// SplitQuery splits a comma separated query parameter such as
+// "vegan,halal", ignoring empty entries
+func SplitQuery(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func parse(values, allowed []string, kind string) (map[string]bool, error) {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if !contains(allowed, v) {
+			return nil, fmt.Errorf("unknown %s %q", kind, v)
+		}
+		set[v] = true
+	}
+	return set, nil
+}
+
+func ordered(set map[string]bool, order []string) db.StringList {
+	list := make(db.StringList, 0, len(set))
+	for _, v := range order {
+		if set[v] {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
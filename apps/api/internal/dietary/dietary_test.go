@@ -0,0 +1,53 @@
+package dietary
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags([]string{"halal", " Vegan ", "VEGAN"})
+	require.NoError(t, err)
+	assert.Equal(t, db.StringList{Vegetarian, Vegan, Halal}, tags)
+
+	tags, err = ParseTags([]string{"jain"})
+	require.NoError(t, err)
+	assert.Equal(t, db.StringList{Vegetarian, Jain}, tags)
+
+	tags, err = ParseTags(nil)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+	assert.NotNil(t, tags)
+
+	_, err = ParseTags([]string{"keto"})
+	assert.Error(t, err)
+}
+
+func TestParseAllergens(t *testing.T) {
+	allergens, err := ParseAllergens([]string{"dairy", "gluten", "Dairy"})
+	require.NoError(t, err)
+	assert.Equal(t, db.StringList{Gluten, Dairy}, allergens)
+
+	_, err = ParseAllergens([]string{"peanut"})
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check(db.StringList{Vegetarian, Vegan}, db.StringList{Gluten, Nuts}, SpiceHot))
+	assert.NoError(t, Check(db.StringList{Vegetarian}, db.StringList{Dairy, Egg}, SpiceNone))
+	assert.NoError(t, Check(db.StringList{Halal}, db.StringList{Fish}, SpiceMild))
+
+	assert.Error(t, Check(db.StringList{Vegetarian, Vegan}, db.StringList{Dairy}, SpiceNone))
+	assert.Error(t, Check(db.StringList{Vegetarian}, db.StringList{Shellfish}, SpiceNone))
+	assert.Error(t, Check(db.StringList{Vegetarian, Jain}, db.StringList{Egg}, SpiceNone))
+	assert.ErrorIs(t, Check(nil, nil, 4), ErrSpiceLevel)
+	assert.ErrorIs(t, Check(nil, nil, -1), ErrSpiceLevel)
+}
+
+func TestSplitQuery(t *testing.T) {
+	assert.Equal(t, []string{"vegan", "halal"}, SplitQuery("vegan, ,halal,"))
+	assert.Nil(t, SplitQuery(""))
+}
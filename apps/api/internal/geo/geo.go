@@ -0,0 +1,130 @@
+// Package geo places restaurants on the map: points, distances between
+// them, the boxes around them that map views and "near me" searches query,
+// and geocoding of addresses.
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// EarthRadiusKm is the mean radius of the earth
+const EarthRadiusKm = 6371.0
+
+// Point is a position in degrees
+type Point struct {
+	Lat float64 `json:"latitude"`
+	Lng float64 `json:"longitude"`
+}
+
+// Validate checks that a point is on the map
+func (p Point) Validate() error {
+	if math.IsNaN(p.Lat) || p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(p.Lng) || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// Distance is the great-circle distance between two points in km
+func Distance(a, b Point) float64 {
+	dLat := radians(b.Lat - a.Lat)
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(radians(a.Lat))*math.Cos(radians(b.Lat))*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox is the area between two latitudes and two longitudes. A box
+// crossing the antimeridian has West greater than East.
+type BoundingBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// ParseBoundingBox reads a box written as "south,west,north,east"
+func ParseBoundingBox(s string) (BoundingBox, error) {
+	var b BoundingBox
+	if _, err := fmt.Sscanf(s, "%g,%g,%g,%g", &b.South, &b.West, &b.North, &b.East); err != nil {
+		return BoundingBox{}, fmt.Errorf("bounds must be south,west,north,east in degrees")
+	}
+	if err := b.Validate(); err != nil {
+		return BoundingBox{}, err
+	}
+	return b, nil
+}
+
+// Validate checks that a box is on the map, with its south below its north
+func (b BoundingBox) Validate() error {
+	for _, corner := range []Point{{b.South, b.West}, {b.North, b.East}} {
+		if err := corner.Validate(); err != nil {
+			return err
+		}
+	}
+	if b.South > b.North {
+		return fmt.Errorf("south of bounds cannot be above north")
+	}
+	return nil
+}
+
+// CrossesAntimeridian reports whether the box wraps around from 180 to -180
+// degrees of longitude
+func (b BoundingBox) CrossesAntimeridian() bool {
+	return b.West > b.East
+}
+
+// Contains reports whether a point is in the box
+func (b BoundingBox) Contains(p Point) bool {
+	if p.Lat < b.South || p.Lat > b.North {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return p.Lng >= b.West || p.Lng <= b.East
+	}
+	return p.Lng >= b.West && p.Lng <= b.East
+}
+
+// Around is the smallest box holding every point within radiusKm of center,
+// for narrowing a search by distance with an index before measuring it
+func Around(center Point, radiusKm float64) BoundingBox {
+	dLat := degrees(radiusKm / EarthRadiusKm)
+	b := BoundingBox{
+		South: math.Max(-90, center.Lat-dLat),
+		North: math.Min(90, center.Lat+dLat),
+		West:  -180,
+		East:  180,
+	}
+	// Near a pole every longitude is within reach
+	if b.South == -90 || b.North == 90 {
+		return b
+	}
+	dLng := degrees(math.Asin(math.Min(1, math.Sin(radiusKm/EarthRadiusKm)/math.Cos(radians(center.Lat)))))
+	if dLng >= 180 {
+		return b
+	}
+	b.West = wrapLongitude(center.Lng - dLng)
+	b.East = wrapLongitude(center.Lng + dLng)
+	return b
+}
+
+func wrapLongitude(lng float64) float64 {
+	switch {
+	case lng < -180:
+		return lng + 360
+	case lng > 180:
+		return lng - 360
+	}
+	return lng
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,63 @@
+package geo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0.0, Distance(KnownPlaces["thamel"], KnownPlaces["thamel"]))
+	// Kathmandu to Pokhara is about 143 km as the crow flies
+	assert.InDelta(t, 143, Distance(KnownPlaces["kathmandu"], KnownPlaces["pokhara"]), 2)
+	// Across the antimeridian
+	assert.InDelta(t, 222.4, Distance(Point{0, 179}, Point{0, -179}), 0.1)
+}
+
+func TestAround(t *testing.T) {
+	center := KnownPlaces["kathmandu"]
+	b := Around(center, 2)
+	assert.True(t, b.Contains(center))
+	// The box reaches as far as the radius in every direction
+	for _, corner := range []Point{{b.South, center.Lng}, {b.North, center.Lng}, {center.Lat, b.West}, {center.Lat, b.East}} {
+		assert.InDelta(t, 2, Distance(center, corner), 0.01)
+	}
+	assert.False(t, b.Contains(KnownPlaces["bhaktapur"]))
+
+	wrapped := Around(Point{0, 179.99}, 10)
+	assert.True(t, wrapped.CrossesAntimeridian())
+	assert.True(t, wrapped.Contains(Point{0, -179.99}))
+	assert.False(t, wrapped.Contains(Point{0, 0}))
+
+	polar := Around(Point{89.99, 0}, 10)
+	assert.Equal(t, BoundingBox{South: polar.South, West: -180, North: 90, East: 180}, polar)
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	b, err := ParseBoundingBox("27.6,85.2,27.8,85.4")
+	require.NoError(t, err)
+	assert.Equal(t, BoundingBox{27.6, 85.2, 27.8, 85.4}, b)
+	assert.True(t, b.Contains(KnownPlaces["thamel"]))
+
+	for _, bad := range []string{"", "27.6,85.2,27.8", "north,west,south,east", "27.8,85.2,27.6,85.4", "91,0,92,1"} {
+		_, err := ParseBoundingBox(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestStaticGeocoder(t *testing.T) {
+	g := NewStaticGeocoder()
+
+	p, err := g.Geocode(context.Background(), Address{Address: "Chaksibari Marg, Thamel, Kathmandu", Place: "Kathmandu"})
+	require.NoError(t, err)
+	assert.Equal(t, KnownPlaces["thamel"], p)
+
+	p, err = g.Geocode(context.Background(), Address{Area: "Lakeside", Place: "Pokhara"})
+	require.NoError(t, err)
+	assert.Equal(t, KnownPlaces["lakeside"], p)
+
+	_, err = g.Geocode(context.Background(), Address{Place: "Atlantis"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,66 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrNotFound is returned by a Geocoder that cannot place an address
+var ErrNotFound = errors.New("address not found")
+
+// Address is where a restaurant is, as its owner wrote it
+type Address struct {
+	Address string // e.g. "Thamel Marg 12, Kathmandu"
+	Area    string // Area within the place
+	Place   string // City or town
+}
+
+// Geocoder places an address on the map
+type Geocoder interface {
+	Geocode(ctx context.Context, address Address) (Point, error)
+}
+
+// StaticGeocoder looks up the area, address parts and place of an address,
+// most precise first, in a fixed list of places. It stands in for a
+// geocoding service and is precise to a neighbourhood at best.
+type StaticGeocoder struct {
+	Places map[string]Point // By lower-case name
+}
+
+// NewStaticGeocoder returns a StaticGeocoder of KnownPlaces
+func NewStaticGeocoder() StaticGeocoder {
+	return StaticGeocoder{Places: KnownPlaces}
+}
+
+func (g StaticGeocoder) Geocode(_ context.Context, address Address) (Point, error) {
+	names := []string{address.Area}
+	names = append(names, strings.Split(address.Address, ",")...)
+	names = append(names, address.Place)
+	for _, name := range names {
+		if p, ok := g.Places[strings.ToLower(strings.TrimSpace(name))]; ok {
+			return p, nil
+		}
+	}
+	return Point{}, ErrNotFound
+}
+
+// KnownPlaces are the centres of the cities and neighbourhoods restaurants
+// are listed in
+var KnownPlaces = map[string]Point{
+	"kathmandu":   {27.7172, 85.3240},
+	"thamel":      {27.7154, 85.3123},
+	"durbar marg": {27.7110, 85.3180},
+	"boudha":      {27.7215, 85.3620},
+	"baneshwor":   {27.6915, 85.3420},
+	"lalitpur":    {27.6644, 85.3188},
+	"patan":       {27.6727, 85.3253},
+	"jhamsikhel":  {27.6790, 85.3080},
+	"bhaktapur":   {27.6710, 85.4298},
+	"pokhara":     {28.2096, 83.9856},
+	"lakeside":    {28.2120, 83.9590},
+	"tokyo":       {35.6762, 139.6503},
+	"shinjuku":    {35.6938, 139.7034},
+	"shibuya":     {35.6595, 139.7005},
+	"osaka":       {34.6937, 135.5023},
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/auth"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apiKeyLastUsedInterval limits how often last-used tracking writes to the
+// database for a busy key
+const apiKeyLastUsedInterval = time.Minute
+
+// APIKeyHandler manages organization API keys and resolves them for
+// auth.RequireAuthOrAPIKey
+type APIKeyHandler struct{ DB *gorm.DB }
+
+// Request/Response DTOs
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	OrgID      string     `json:"orgId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	RotatedAt  *time.Time `json:"rotatedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// APIKeyWithSecretResponse is returned once, when a key is created or
+// rotated. The key cannot be retrieved again.
+type APIKeyWithSecretResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// ListAPIKeys godoc
+// @Summary List an organization's API keys
+// @Description List API keys without their secrets (apikeys:manage)
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	// Parsed and authorized by authz.OrgScope
+	orgID := c.Param("id")
+
+	var keys []db.APIKey
+	if err := h.DB.Where("org_id = ?", orgID).Order("created_at desc").Find(&keys).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch api keys"})
+		return
+	}
+
+	response := make([]APIKeyResponse, len(keys))
+	for i, key := range keys {
+		response[i] = toAPIKeyResponse(key)
+	}
+	c.JSON(200, gin.H{"apiKeys": response})
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an organization API key with scopes. The key is only shown in this response. (apikeys:manage)
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param apiKey body CreateAPIKeyRequest true "API key"
+// @Success 201 {object} APIKeyWithSecretResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	// Parsed and authorized by authz.OrgScope
+	orgID := uuid.MustParse(c.Param("id"))
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	scopes := make(db.StringList, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !authz.ValidAPIKeyScope(scope) {
+			c.JSON(400, gin.H{"error": "invalid scope " + scope})
+			return
+		}
+		scopes = append(scopes, scope)
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		c.JSON(400, gin.H{"error": "expiresAt must be in the future"})
+		return
+	}
+
+	secret, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create api key"})
+		return
+	}
+
+	key := db.APIKey{
+		ID:        uuid.New(),
+		OrgID:     orgID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    prefix,
+		Hash:      auth.HashAPIKey(secret),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	if creator, err := uuid.Parse(c.GetString("uid")); err == nil {
+		key.CreatedBy = &creator
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&key).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionAPIKeyCreate,
+			EntityType: "api_key",
+			EntityID:   key.ID.String(),
+			OrgID:      &orgID,
+			After:      toAPIKeyResponse(key),
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create api key"})
+		return
+	}
+
+	c.JSON(201, APIKeyWithSecretResponse{APIKeyResponse: toAPIKeyResponse(key), Key: secret})
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate an API key
+// @Description Replace the key's secret, keeping its name and scopes. The old key stops working immediately. (apikeys:manage)
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} APIKeyWithSecretResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/api-keys/{keyId}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	key, ok := h.activeKey(c)
+	if !ok {
+		return
+	}
+
+	secret, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to rotate api key"})
+		return
+	}
+
+	before := *key
+	now := time.Now()
+	key.Prefix = prefix
+	key.Hash = auth.HashAPIKey(secret)
+	key.RotatedAt = &now
+	key.LastUsedAt = nil
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(key).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionAPIKeyRotate,
+			EntityType: "api_key",
+			EntityID:   key.ID.String(),
+			OrgID:      &key.OrgID,
+			Before:     toAPIKeyResponse(before),
+			After:      toAPIKeyResponse(*key),
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to rotate api key"})
+		return
+	}
+
+	c.JSON(200, APIKeyWithSecretResponse{APIKeyResponse: toAPIKeyResponse(*key), Key: secret})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Tags api-keys
+// @Param id path string true "Organization ID"
+// @Param keyId path string true "API key ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/api-keys/{keyId} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	key, ok := h.activeKey(c)
+	if !ok {
+		return
+	}
+
+	before := *key
+	now := time.Now()
+	key.RevokedAt = &now
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&db.APIKey{}).Where("id = ?", key.ID).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionAPIKeyRevoke,
+			EntityType: "api_key",
+			EntityID:   key.ID.String(),
+			OrgID:      &key.OrgID,
+			Before:     toAPIKeyResponse(before),
+			After:      toAPIKeyResponse(*key),
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to revoke api key"})
+		return
+	}
+	c.Status(204)
+}
+
+// Resolve implements auth.APIKeyResolver. It finds the key by prefix,
+// compares hashes in constant time and records when the key was last used.
+func (h *APIKeyHandler) Resolve(secret string) (*auth.APIKeyPrincipal, error) {
+	prefix, ok := auth.APIKeyPrefix(secret)
+	if !ok {
+		return nil, auth.ErrInvalidAPIKey
+	}
+
+	var key db.APIKey
+	if err := h.DB.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, auth.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(auth.HashAPIKey(secret))) != 1 ||
+		key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyLastUsedInterval {
+		h.DB.Model(&db.APIKey{}).Where("id = ?", key.ID).Update("last_used_at", now)
+	}
+
+	return &auth.APIKeyPrincipal{ID: key.ID, OrgID: key.OrgID, Scopes: key.Scopes}, nil
+}
+
+// activeKey loads the :keyId key of the :id organization and requires it
+// not to be revoked
+func (h *APIKeyHandler) activeKey(c *gin.Context) (*db.APIKey, bool) {
+	keyID, err := uuid.Parse(c.Param("keyId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid api key ID"})
+		return nil, false
+	}
+
+	var key db.APIKey
+	if err := h.DB.Where("id = ? AND org_id = ?", keyID, c.Param("id")).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(404, gin.H{"error": "api key not found"})
+			return nil, false
+		}
+		c.JSON(500, gin.H{"error": "failed to fetch api key"})
+		return nil, false
+	}
+	if key.RevokedAt != nil {
+		c.JSON(409, gin.H{"error": "api key is revoked"})
+		return nil, false
+	}
+	return &key, true
+}
+
+func toAPIKeyResponse(key db.APIKey) APIKeyResponse {
+	scopes := []string(key.Scopes)
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return APIKeyResponse{
+		ID:         key.ID.String(),
+		OrgID:      key.OrgID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RotatedAt:  key.RotatedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
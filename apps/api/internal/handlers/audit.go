@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditHandler struct{ DB *gorm.DB }
+
+type AuditLogResponse struct {
+	ID         string    `json:"id"`
+	ActorID    *string   `json:"actorId"`
+	ActorRole  string    `json:"actorRole"`
+	OrgID      *string   `json:"orgId"`
+	Action     string    `json:"action"`
+	EntityType string    `json:"entityType"`
+	EntityID   string    `json:"entityId"`
+	Before     db.JSON   `json:"before" swaggertype:"object"`
+	After      db.JSON   `json:"after" swaggertype:"object"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	RequestID  string    `json:"requestId"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description Query the audit log across all organizations (only for super admins)
+// @Tags audit
+// @Produce json
+// @Param actorId query string false "Actor user ID"
+// @Param orgId query string false "Organization ID"
+// @Param action query string false "Action, e.g. menu.update"
+// @Param entityType query string false "Entity type, e.g. menu"
+// @Param entityId query string false "Entity ID"
+// @Param from query string false "Earliest time (RFC3339)"
+// @Param to query string false "Latest time, exclusive (RFC3339)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /super-admin/audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	q := h.DB.Model(&db.AuditLog{})
+	if orgID := c.Query("orgId"); orgID != "" {
+		id, err := uuid.Parse(orgID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid organization ID"})
+			return
+		}
+		q = q.Where("org_id = ?", id)
+	}
+	h.listAuditLogs(c, q)
+}
+
+// ListOrganizationAuditLogs godoc
+// @Summary List an organization's audit logs
+// @Description Query the audit log of one organization (audit:read)
+// @Tags audit
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param actorId query string false "Actor user ID"
+// @Param action query string false "Action, e.g. menu.update"
+// @Param entityType query string false "Entity type, e.g. menu"
+// @Param entityId query string false "Entity ID"
+// @Param from query string false "Earliest time (RFC3339)"
+// @Param to query string false "Latest time, exclusive (RFC3339)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/audit-logs [get]
+func (h *AuditHandler) ListOrganizationAuditLogs(c *gin.Context) {
+	// Parsed and authorized by authz.OrgScope
+	orgID := c.Param("id")
+	h.listAuditLogs(c, h.DB.Model(&db.AuditLog{}).Where("org_id = ?", orgID))
+}
+
+// listAuditLogs applies the common filters and pagination to q and writes
+// the page, newest first
+func (h *AuditHandler) listAuditLogs(c *gin.Context, q *gorm.DB) {
+	if actorID := c.Query("actorId"); actorID != "" {
+		id, err := uuid.Parse(actorID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid actor ID"})
+			return
+		}
+		q = q.Where("actor_id = ?", id)
+	}
+	if action := c.Query("action"); action != "" {
+		q = q.Where("action = ?", action)
+	}
+	if entityType := c.Query("entityType"); entityType != "" {
+		q = q.Where("entity_type = ?", entityType)
+	}
+	if entityID := c.Query("entityId"); entityID != "" {
+		q = q.Where("entity_id = ?", entityID)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid from time, expected RFC3339"})
+			return
+		}
+		q = q.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid to time, expected RFC3339"})
+			return
+		}
+		q = q.Where("created_at < ?", t)
+	}
+
+	page := 1
+	limit := 20
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to count audit logs"})
+		return
+	}
+
+	var logs []db.AuditLog
+	if err := q.Order("created_at desc").Offset((page - 1) * limit).Limit(limit).Find(&logs).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch audit logs"})
+		return
+	}
+
+	response := make([]AuditLogResponse, len(logs))
+	for i, l := range logs {
+		response[i] = toAuditLogResponse(l)
+	}
+
+	c.JSON(200, gin.H{
+		"auditLogs": response,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func toAuditLogResponse(l db.AuditLog) AuditLogResponse {
+	r := AuditLogResponse{
+		ID:         l.ID.String(),
+		ActorRole:  l.ActorRole,
+		Action:     l.Action,
+		EntityType: l.EntityType,
+		EntityID:   l.EntityID,
+		Before:     l.Before,
+		After:      l.After,
+		IP:         l.IP,
+		UserAgent:  l.UserAgent,
+		Method:     l.Method,
+		Path:       l.Path,
+		RequestID:  l.RequestID,
+		CreatedAt:  l.CreatedAt,
+	}
+	if l.ActorID != nil {
+		id := l.ActorID.String()
+		r.ActorID = &id
+	}
+	if l.OrgID != nil {
+		id := l.OrgID.String()
+		r.OrgID = &id
+	}
+	return r
+}
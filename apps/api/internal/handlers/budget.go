@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/budget"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RestaurantBudget is what a meal costs per person at a restaurant
+type RestaurantBudget struct {
+	Budget     string      `json:"budget"` // As shown to customers, e.g. "500-1500"
+	Min        money.Money `json:"min"`
+	Max        money.Money `json:"max"`
+	PriceLevel int         `json:"priceLevel"` // 1 ($) to 4 ($$$$), 0 when unknown
+}
+
+// SetRestaurantBudgetRequest sets the budget from text, from amounts or,
+// with fromPrices, from the restaurant's course prices (menu item prices
+// when it has no courses)
+type SetRestaurantBudgetRequest struct {
+	Budget     *string      `json:"budget,omitempty"` // e.g. "500-1500" or "$$"
+	Min        *money.Money `json:"min,omitempty"`
+	Max        *money.Money `json:"max,omitempty"`
+	FromPrices bool         `json:"fromPrices"`
+}
+
+func toRestaurantBudget(restaurant db.Restaurant) RestaurantBudget {
+	return RestaurantBudget{
+		Budget:     restaurant.Budget,
+		Min:        restaurant.BudgetMin,
+		Max:        restaurant.BudgetMax,
+		PriceLevel: restaurant.PriceLevel,
+	}
+}
+
+// GET /api/owner/restaurants/:id/budget - Get budget per person (restaurant:read)
+func (h *RestaurantHandler) GetRestaurantBudget(c *gin.Context) {
+	c.JSON(200, toRestaurantBudget(*authz.ScopedRestaurant(c)))
+}
+
+// PUT /api/owner/restaurants/:id/budget - Set budget per person (restaurant:write)
+func (h *RestaurantHandler) SetRestaurantBudget(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	currency := restaurantCurrency(*restaurant)
+
+	var req SetRestaurantBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := toRestaurantBudget(*restaurant)
+	switch {
+	case req.FromPrices:
+		prices, err := restaurantPrices(h.DB, restaurant.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "failed to fetch prices"})
+			return
+		}
+		r := budget.FromPrices(prices, currency)
+		if r.Max.IsZero() {
+			c.JSON(400, gin.H{"error": "restaurant has no prices to estimate a budget from"})
+			return
+		}
+		setBudgetRange(restaurant, r)
+	case req.Min != nil && req.Max != nil:
+		r := budget.Range{Min: *req.Min, Max: *req.Max}
+		if err := coursePricesIn(currency, &r.Min, &r.Max); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if r.Max.Amount < r.Min.Amount {
+			c.JSON(400, gin.H{"error": "max cannot be below min"})
+			return
+		}
+		setBudgetRange(restaurant, r)
+	case req.Budget != nil:
+		if err := setBudget(restaurant, *req.Budget); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	default:
+		c.JSON(400, gin.H{"error": "budget, min and max, or fromPrices is required"})
+		return
+	}
+	restaurant.UpdatedAt = time.Now()
+	after := toRestaurantBudget(*restaurant)
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(restaurant).
+			Select("budget", "budget_min_amount", "budget_min_currency", "budget_max_amount", "budget_max_currency", "price_level", "updated_at").
+			Updates(restaurant).Error
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantBudget,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      after,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update budget"})
+		return
+	}
+
+	c.JSON(200, after)
+}
+
+// setBudget sets a restaurant's budget from text in its currency, such as
+// "500-1500" or "$$"
+func setBudget(restaurant *db.Restaurant, text string) error {
+	r, err := budget.Parse(text, restaurantCurrency(*restaurant))
+	if err != nil {
+		return err
+	}
+	restaurant.Budget = strings.TrimSpace(text)
+	restaurant.BudgetMin, restaurant.BudgetMax = r.Min, r.Max
+	restaurant.PriceLevel = budget.Level(r)
+	return nil
+}
+
+// setBudgetRange sets a restaurant's budget from amounts, writing the text
+// shown to customers
+func setBudgetRange(restaurant *db.Restaurant, r budget.Range) {
+	restaurant.Budget = budget.Text(r)
+	restaurant.BudgetMin, restaurant.BudgetMax = r.Min, r.Max
+	restaurant.PriceLevel = budget.Level(r)
+}
+
+// restaurantPrices returns the prices of a restaurant's courses, which are
+// per person, or of its menu items when it has no courses
+func restaurantPrices(tx *gorm.DB, restaurantID uuid.UUID) ([]money.Money, error) {
+	var courses []db.Course
+	if err := tx.Select("course_price_amount", "course_price_currency").Where("restaurant_id = ?", restaurantID).Find(&courses).Error; err != nil {
+		return nil, err
+	}
+	prices := make([]money.Money, 0, len(courses))
+	for _, course := range courses {
+		prices = append(prices, course.CoursePrice)
+	}
+	if len(prices) > 0 {
+		return prices, nil
+	}
+
+	var menus []db.Menu
+	if err := tx.Select("price_amount", "price_currency").Where("restaurant_id = ?", restaurantID).Find(&menus).Error; err != nil {
+		return nil, err
+	}
+	for _, menu := range menus {
+		prices = append(prices, menu.Price)
+	}
+	return prices, nil
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
 	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/i18n"
+	"github.com/example/restosaas/apps/api/internal/money"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -14,43 +19,101 @@ type CourseHandler struct{ DB *gorm.DB }
 
 // Request/Response DTOs
 type CreateCourseRequest struct {
-	Title         string `json:"title" binding:"required"`
-	Description   string `json:"description"`
-	ImageURL      string `json:"imageUrl"`
-	CoursePrice   int    `json:"coursePrice" binding:"required,min=0"`
-	OriginalPrice *int   `json:"originalPrice,omitempty"`
-	NumberOfItems int    `json:"numberOfItems" binding:"required,min=1"`
-	StayTime      int    `json:"stayTime" binding:"required,min=1"`
-	CourseContent string `json:"courseContent"`
-	Precautions   string `json:"precautions"`
+	Title         string       `json:"title" binding:"required"`
+	Description   string       `json:"description"`
+	ImageURL      string       `json:"imageUrl"`
+	CoursePrice   money.Money  `json:"coursePrice"`
+	OriginalPrice *money.Money `json:"originalPrice,omitempty"`
+	NumberOfItems int          `json:"numberOfItems" binding:"required,min=1"`
+	StayTime      int          `json:"stayTime" binding:"required,min=1"`
+	CourseContent string       `json:"courseContent"`
+	Precautions   string       `json:"precautions"`
+	// Dietary labels from the dietary package, e.g. ["VEGAN"] and ["NUTS"]
+	DietaryTags []string `json:"dietaryTags"`
+	Allergens   []string `json:"allergens"`
+	SpiceLevel  int      `json:"spiceLevel" binding:"min=0,max=3"`
 }
 
 type UpdateCourseRequest struct {
-	Title         *string `json:"title,omitempty"`
-	Description   *string `json:"description,omitempty"`
-	ImageURL      *string `json:"imageUrl,omitempty"`
-	CoursePrice   *int    `json:"coursePrice,omitempty"`
-	OriginalPrice *int    `json:"originalPrice,omitempty"`
-	NumberOfItems *int    `json:"numberOfItems,omitempty"`
-	StayTime      *int    `json:"stayTime,omitempty"`
-	CourseContent *string `json:"courseContent,omitempty"`
-	Precautions   *string `json:"precautions,omitempty"`
+	Title         *string      `json:"title,omitempty"`
+	Description   *string      `json:"description,omitempty"`
+	ImageURL      *string      `json:"imageUrl,omitempty"`
+	CoursePrice   *money.Money `json:"coursePrice,omitempty"`
+	OriginalPrice *money.Money `json:"originalPrice,omitempty"`
+	NumberOfItems *int         `json:"numberOfItems,omitempty"`
+	StayTime      *int         `json:"stayTime,omitempty"`
+	CourseContent *string      `json:"courseContent,omitempty"`
+	Precautions   *string      `json:"precautions,omitempty"`
+	DietaryTags   *[]string    `json:"dietaryTags,omitempty"`
+	Allergens     *[]string    `json:"allergens,omitempty"`
+	SpiceLevel    *int         `json:"spiceLevel,omitempty" binding:"omitempty,min=0,max=3"`
 }
 
 type CourseResponse struct {
-	ID            string    `json:"id"`
-	RestaurantID  string    `json:"restaurantId"`
-	Title         string    `json:"title"`
-	Description   string    `json:"description"`
-	ImageURL      string    `json:"imageUrl"`
-	CoursePrice   int       `json:"coursePrice"`
-	OriginalPrice *int      `json:"originalPrice"`
-	NumberOfItems int       `json:"numberOfItems"`
-	StayTime      int       `json:"stayTime"`
-	CourseContent string    `json:"courseContent"`
-	Precautions   string    `json:"precautions"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            string       `json:"id"`
+	RestaurantID  string       `json:"restaurantId"`
+	Title         string       `json:"title"`
+	Description   string       `json:"description"`
+	ImageURL      string       `json:"imageUrl"`
+	CoursePrice   money.Money  `json:"coursePrice"`
+	OriginalPrice *money.Money `json:"originalPrice"`
+	NumberOfItems int          `json:"numberOfItems"`
+	StayTime      int          `json:"stayTime"`
+	CourseContent string       `json:"courseContent"`
+	Precautions   string       `json:"precautions"`
+	DietaryTags   []string     `json:"dietaryTags"`
+	Allergens     []string     `json:"allergens"`
+	SpiceLevel    int          `json:"spiceLevel"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+
+	// Public endpoints only: the price with service charge and tax
+	CustomerPrice *PriceDisplay `json:"customerPrice,omitempty"`
+}
+
+// originalPrice returns the price a course is discounted from, nil when it
+// isn't discounted
+func originalPrice(course db.Course) *money.Money {
+	if course.OriginalPrice.IsZero() {
+		return nil
+	}
+	price := course.OriginalPrice
+	return &price
+}
+
+// coursePricesIn checks that a course's prices are in currency and not
+// negative, filling in left out currencies
+func coursePricesIn(currency string, prices ...*money.Money) error {
+	for _, price := range prices {
+		if err := inCurrency(price, currency); err != nil {
+			return err
+		}
+		if price.Amount < 0 {
+			return errors.New("price cannot be negative")
+		}
+	}
+	return nil
+}
+
+func toCourseResponse(course db.Course) CourseResponse {
+	return CourseResponse{
+		ID:            course.ID.String(),
+		RestaurantID:  course.RestaurantID.String(),
+		Title:         course.Title,
+		Description:   course.Description,
+		ImageURL:      course.ImageURL,
+		CoursePrice:   course.CoursePrice,
+		OriginalPrice: originalPrice(course),
+		NumberOfItems: course.NumberOfItems,
+		StayTime:      course.StayTime,
+		CourseContent: course.CourseContent,
+		Precautions:   course.Precautions,
+		DietaryTags:   stringsOrEmpty(course.DietaryTags),
+		Allergens:     stringsOrEmpty(course.Allergens),
+		SpiceLevel:    course.SpiceLevel,
+		CreatedAt:     course.CreatedAt,
+		UpdatedAt:     course.UpdatedAt,
+	}
 }
 
 // CreateCourse godoc
@@ -67,26 +130,27 @@ type CourseResponse struct {
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/courses [post]
 func (h *CourseHandler) CreateCourse(c *gin.Context) {
-	restaurantID := c.Param("id")
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
+	// Loaded and authorized by authz.RestaurantScope
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
+
+	var req CreateCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Check if restaurant exists
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ?", restaurantUUID).First(&restaurant).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "restaurant not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": "failed to fetch restaurant"})
+	original := money.Money{}
+	if req.OriginalPrice != nil {
+		original = *req.OriginalPrice
+	}
+	if err := coursePricesIn(restaurantCurrency(*restaurant), &req.CoursePrice, &original); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	var req CreateCourseRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	tags, allergens, err := dietaryLabels(req.DietaryTags, req.Allergens, req.SpiceLevel)
+	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -98,35 +162,36 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 		Description:   req.Description,
 		ImageURL:      req.ImageURL,
 		CoursePrice:   req.CoursePrice,
-		OriginalPrice: req.OriginalPrice,
+		OriginalPrice: original,
 		NumberOfItems: req.NumberOfItems,
 		StayTime:      req.StayTime,
 		CourseContent: req.CourseContent,
 		Precautions:   req.Precautions,
+		DietaryTags:   tags,
+		Allergens:     allergens,
+		SpiceLevel:    req.SpiceLevel,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
 
-	if err := h.DB.Create(&course).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&course).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionCourseCreate,
+			EntityType: "course",
+			EntityID:   course.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			After:      course,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to create course"})
 		return
 	}
 
-	response := CourseResponse{
-		ID:            course.ID.String(),
-		RestaurantID:  course.RestaurantID.String(),
-		Title:         course.Title,
-		Description:   course.Description,
-		ImageURL:      course.ImageURL,
-		CoursePrice:   course.CoursePrice,
-		OriginalPrice: course.OriginalPrice,
-		NumberOfItems: course.NumberOfItems,
-		StayTime:      course.StayTime,
-		CourseContent: course.CourseContent,
-		Precautions:   course.Precautions,
-		CreatedAt:     course.CreatedAt,
-		UpdatedAt:     course.UpdatedAt,
-	}
+	response := toCourseResponse(course)
 
 	c.JSON(201, response)
 }
@@ -144,23 +209,8 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/courses [get]
 func (h *CourseHandler) ListCourses(c *gin.Context) {
-	restaurantID := c.Param("id")
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
-	// Check if restaurant exists
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ?", restaurantUUID).First(&restaurant).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "restaurant not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": "failed to fetch restaurant"})
-		return
-	}
+	// Loaded and authorized by authz.RestaurantScope
+	restaurantUUID := authz.ScopedRestaurant(c).ID
 
 	var courses []db.Course
 	if err := h.DB.Where("restaurant_id = ?", restaurantUUID).Order("created_at DESC").Find(&courses).Error; err != nil {
@@ -168,23 +218,9 @@ func (h *CourseHandler) ListCourses(c *gin.Context) {
 		return
 	}
 
-	var response []CourseResponse
+	response := make([]CourseResponse, 0, len(courses))
 	for _, course := range courses {
-		response = append(response, CourseResponse{
-			ID:            course.ID.String(),
-			RestaurantID:  course.RestaurantID.String(),
-			Title:         course.Title,
-			Description:   course.Description,
-			ImageURL:      course.ImageURL,
-			CoursePrice:   course.CoursePrice,
-			OriginalPrice: course.OriginalPrice,
-			NumberOfItems: course.NumberOfItems,
-			StayTime:      course.StayTime,
-			CourseContent: course.CourseContent,
-			Precautions:   course.Precautions,
-			CreatedAt:     course.CreatedAt,
-			UpdatedAt:     course.UpdatedAt,
-		})
+		response = append(response, toCourseResponse(course))
 	}
 
 	c.JSON(200, gin.H{"courses": response})
@@ -204,15 +240,9 @@ func (h *CourseHandler) ListCourses(c *gin.Context) {
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/courses/{courseId} [get]
 func (h *CourseHandler) GetCourse(c *gin.Context) {
-	restaurantID := c.Param("id")
+	restaurantUUID := authz.ScopedRestaurant(c).ID
 	courseID := c.Param("courseId")
 
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
 	courseUUID, err := uuid.Parse(courseID)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "invalid course ID"})
@@ -229,21 +259,7 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 		return
 	}
 
-	response := CourseResponse{
-		ID:            course.ID.String(),
-		RestaurantID:  course.RestaurantID.String(),
-		Title:         course.Title,
-		Description:   course.Description,
-		ImageURL:      course.ImageURL,
-		CoursePrice:   course.CoursePrice,
-		OriginalPrice: course.OriginalPrice,
-		NumberOfItems: course.NumberOfItems,
-		StayTime:      course.StayTime,
-		CourseContent: course.CourseContent,
-		Precautions:   course.Precautions,
-		CreatedAt:     course.CreatedAt,
-		UpdatedAt:     course.UpdatedAt,
-	}
+	response := toCourseResponse(course)
 
 	c.JSON(200, response)
 }
@@ -263,15 +279,10 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/courses/{courseId} [put]
 func (h *CourseHandler) UpdateCourse(c *gin.Context) {
-	restaurantID := c.Param("id")
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
 	courseID := c.Param("courseId")
 
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
 	courseUUID, err := uuid.Parse(courseID)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "invalid course ID"})
@@ -295,6 +306,7 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 	}
 
 	// Update fields
+	before := course
 	if req.Title != nil {
 		course.Title = *req.Title
 	}
@@ -308,7 +320,11 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 		course.CoursePrice = *req.CoursePrice
 	}
 	if req.OriginalPrice != nil {
-		course.OriginalPrice = req.OriginalPrice
+		course.OriginalPrice = *req.OriginalPrice
+	}
+	if err := coursePricesIn(restaurantCurrency(*restaurant), &course.CoursePrice, &course.OriginalPrice); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
 	}
 	if req.NumberOfItems != nil {
 		course.NumberOfItems = *req.NumberOfItems
@@ -322,29 +338,45 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 	if req.Precautions != nil {
 		course.Precautions = *req.Precautions
 	}
+	if req.DietaryTags != nil || req.Allergens != nil || req.SpiceLevel != nil {
+		tags, allergens := []string(course.DietaryTags), []string(course.Allergens)
+		if req.DietaryTags != nil {
+			tags = *req.DietaryTags
+		}
+		if req.Allergens != nil {
+			allergens = *req.Allergens
+		}
+		if req.SpiceLevel != nil {
+			course.SpiceLevel = *req.SpiceLevel
+		}
+		course.DietaryTags, course.Allergens, err = dietaryLabels(tags, allergens, course.SpiceLevel)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	course.UpdatedAt = time.Now()
 
-	if err := h.DB.Save(&course).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&course).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionCourseUpdate,
+			EntityType: "course",
+			EntityID:   course.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      course,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to update course"})
 		return
 	}
 
-	response := CourseResponse{
-		ID:            course.ID.String(),
-		RestaurantID:  course.RestaurantID.String(),
-		Title:         course.Title,
-		Description:   course.Description,
-		ImageURL:      course.ImageURL,
-		CoursePrice:   course.CoursePrice,
-		OriginalPrice: course.OriginalPrice,
-		NumberOfItems: course.NumberOfItems,
-		StayTime:      course.StayTime,
-		CourseContent: course.CourseContent,
-		Precautions:   course.Precautions,
-		CreatedAt:     course.CreatedAt,
-		UpdatedAt:     course.UpdatedAt,
-	}
+	response := toCourseResponse(course)
 
 	c.JSON(200, response)
 }
@@ -363,15 +395,10 @@ func (h *CourseHandler) UpdateCourse(c *gin.Context) {
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/courses/{courseId} [delete]
 func (h *CourseHandler) DeleteCourse(c *gin.Context) {
-	restaurantID := c.Param("id")
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
 	courseID := c.Param("courseId")
 
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
 	courseUUID, err := uuid.Parse(courseID)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "invalid course ID"})
@@ -388,7 +415,19 @@ func (h *CourseHandler) DeleteCourse(c *gin.Context) {
 		return
 	}
 
-	if err := h.DB.Delete(&course).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&course).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionCourseDelete,
+			EntityType: "course",
+			EntityID:   course.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     course,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to delete course"})
 		return
 	}
@@ -403,6 +442,10 @@ func (h *CourseHandler) DeleteCourse(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param slug path string true "Restaurant slug"
+// @Param diet query string false "Comma separated diet tags the courses must all have, e.g. VEGAN,HALAL"
+// @Param excludeAllergens query string false "Comma separated allergens the courses must not contain, e.g. NUTS,DAIRY"
+// @Param maxSpice query int false "Highest spice level, 0 (not spicy) to 3 (hot)"
+// @Param lang query string false "Locale to show text in, overriding Accept-Language: en, ne, ja or zh"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -422,29 +465,26 @@ func (h *CourseHandler) PublicGetCourses(c *gin.Context) {
 		return
 	}
 
+	query, err := applyDietaryFilters(c, h.DB.Where("restaurant_id = ?", restaurant.ID))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
 	var courses []db.Course
-	if err := h.DB.Where("restaurant_id = ?", restaurant.ID).Order("created_at DESC").Find(&courses).Error; err != nil {
+	if err := query.Order("created_at DESC").Find(&courses).Error; err != nil {
 		c.JSON(500, gin.H{"error": "failed to fetch courses"})
 		return
 	}
 
-	var response []CourseResponse
+	locales := requestLocales(c)
+	pricing := restaurantPricing(restaurant)
+	response := make([]CourseResponse, 0, len(courses))
 	for _, course := range courses {
-		response = append(response, CourseResponse{
-			ID:            course.ID.String(),
-			RestaurantID:  course.RestaurantID.String(),
-			Title:         course.Title,
-			Description:   course.Description,
-			ImageURL:      course.ImageURL,
-			CoursePrice:   course.CoursePrice,
-			OriginalPrice: course.OriginalPrice,
-			NumberOfItems: course.NumberOfItems,
-			StayTime:      course.StayTime,
-			CourseContent: course.CourseContent,
-			Precautions:   course.Precautions,
-			CreatedAt:     course.CreatedAt,
-			UpdatedAt:     course.UpdatedAt,
-		})
+		i18n.LocalizeCourse(&course, locales)
+		item := toCourseResponse(course)
+		item.CustomerPrice = toPriceDisplay(pricing, course.CoursePrice, locales[0])
+		response = append(response, item)
 	}
 
 	c.JSON(200, gin.H{"courses": response})
@@ -458,6 +498,7 @@ func (h *CourseHandler) PublicGetCourses(c *gin.Context) {
 // @Produce json
 // @Param slug path string true "Restaurant slug"
 // @Param courseId path string true "Course ID"
+// @Param lang query string false "Locale to show text in, overriding Accept-Language: en, ne, ja or zh"
 // @Success 200 {object} CourseResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -494,21 +535,10 @@ func (h *CourseHandler) PublicGetCourse(c *gin.Context) {
 		return
 	}
 
-	response := CourseResponse{
-		ID:            course.ID.String(),
-		RestaurantID:  course.RestaurantID.String(),
-		Title:         course.Title,
-		Description:   course.Description,
-		ImageURL:      course.ImageURL,
-		CoursePrice:   course.CoursePrice,
-		OriginalPrice: course.OriginalPrice,
-		NumberOfItems: course.NumberOfItems,
-		StayTime:      course.StayTime,
-		CourseContent: course.CourseContent,
-		Precautions:   course.Precautions,
-		CreatedAt:     course.CreatedAt,
-		UpdatedAt:     course.UpdatedAt,
-	}
+	locales := requestLocales(c)
+	i18n.LocalizeCourse(&course, locales)
+	response := toCourseResponse(course)
+	response.CustomerPrice = toPriceDisplay(restaurantPricing(restaurant), course.CoursePrice, locales[0])
 
 	c.JSON(200, response)
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/dietary"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// dietaryLabels parses and cross-checks the dietary labels of a menu item
+// or course
+func dietaryLabels(tags, allergens []string, spiceLevel int) (db.StringList, db.StringList, error) {
+	parsedTags, err := dietary.ParseTags(tags)
+	if err != nil {
+		return nil, nil, err
+	}
+	parsedAllergens, err := dietary.ParseAllergens(allergens)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := dietary.Check(parsedTags, parsedAllergens, spiceLevel); err != nil {
+		return nil, nil, err
+	}
+	return parsedTags, parsedAllergens, nil
+}
+
+// applyDietaryFilters narrows a menu or course query using the diet
+// (every tag required), excludeAllergens (none present) and maxSpice query
+// parameters
+func applyDietaryFilters(c *gin.Context, q *gorm.DB) (*gorm.DB, error) {
+	if diet := dietary.SplitQuery(c.Query("diet")); len(diet) > 0 {
+		tags, err := dietary.ParseTags(diet)
+		if err != nil {
+			return nil, err
+		}
+		q = q.Where("dietary_tags @> ?::jsonb", tags)
+	}
+	if exclude := dietary.SplitQuery(c.Query("excludeAllergens")); len(exclude) > 0 {
+		allergens, err := dietary.ParseAllergens(exclude)
+		if err != nil {
+			return nil, err
+		}
+		for _, allergen := range allergens {
+			q = q.Where("NOT (allergens @> ?::jsonb)", db.StringList{allergen})
+		}
+	}
+	if maxSpice := c.Query("maxSpice"); maxSpice != "" {
+		level, err := strconv.Atoi(maxSpice)
+		if err != nil || level < dietary.SpiceNone || level > dietary.SpiceHot {
+			return nil, fmt.Errorf("maxSpice must be between %d and %d", dietary.SpiceNone, dietary.SpiceHot)
+		}
+		q = q.Where("spice_level <= ?", level)
+	}
+	return q, nil
+}
+
+// stringsOrEmpty returns the list, or an empty one for nil, so that it is
+// encoded as [] rather than null
+func stringsOrEmpty(list db.StringList) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
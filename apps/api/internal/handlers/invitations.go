@@ -0,0 +1,652 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/auth"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// invitationTTL is how long an invitation link stays valid after it is sent
+const invitationTTL = 7 * 24 * time.Hour
+
+// InvitationHandler lets organization owners invite people by email
+type InvitationHandler struct {
+	DB     *gorm.DB
+	Mailer notify.Mailer // Optional; invitations are created but not mailed when nil
+	AppURL string        // Web app base URL used in invitation links
+}
+
+// Request/Response DTOs
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type InvitationTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+	// Required when no account exists for the invited email
+	DisplayName string `json:"displayName"`
+	Password    string `json:"password"`
+}
+
+type InvitationResponse struct {
+	ID          string     `json:"id"`
+	OrgID       string     `json:"orgId"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	Expired     bool       `json:"expired"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	SentAt      time.Time  `json:"sentAt"`
+	RespondedAt *time.Time `json:"respondedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// InvitationPreviewResponse is what the invitee sees before responding
+type InvitationPreviewResponse struct {
+	OrganizationName string    `json:"organizationName"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	AccountExists    bool      `json:"accountExists"` // Sign in to accept instead of registering
+}
+
+// CreateInvitation godoc
+// @Summary Invite someone to an organization
+// @Description Email an invitation to join the organization with a role (members:manage)
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param invitation body CreateInvitationRequest true "Invitation"
+// @Success 201 {object} InvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	// Parsed and authorized by authz.OrgScope
+	orgID := uuid.MustParse(c.Param("id"))
+
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	role := db.OrgRole(strings.ToUpper(req.Role))
+	if !role.Valid() {
+		c.JSON(400, gin.H{"error": "invalid role"})
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	var org db.Organization
+	if err := h.DB.Where("id = ?", orgID).First(&org).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(404, gin.H{"error": "organization not found"})
+			return
+		}
+		c.JSON(500, gin.H{"error": "failed to fetch organization"})
+		return
+	}
+
+	var members int64
+	if err := h.DB.Model(&db.OrgMember{}).
+		Joins("JOIN users ON users.id = org_members.user_id").
+		Where("org_members.org_id = ? AND LOWER(users.email) = ?", orgID, email).
+		Count(&members).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to check membership"})
+		return
+	}
+	if members > 0 {
+		c.JSON(409, gin.H{"error": "user is already a member of this organization"})
+		return
+	}
+
+	var pending int64
+	if err := h.DB.Model(&db.Invitation{}).
+		Where("org_id = ? AND email = ? AND status = ?", orgID, email, db.InvitationPending).
+		Count(&pending).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to check invitations"})
+		return
+	}
+	if pending > 0 {
+		c.JSON(409, gin.H{"error": "an invitation is already pending for this email; resend or revoke it"})
+		return
+	}
+
+	nonce, err := auth.RandomToken(16)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create invitation"})
+		return
+	}
+
+	now := time.Now()
+	inv := db.Invitation{
+		ID:        uuid.New(),
+		OrgID:     orgID,
+		Email:     email,
+		Role:      role,
+		Status:    db.InvitationPending,
+		Nonce:     nonce,
+		ExpiresAt: now.Add(invitationTTL),
+		SentAt:    now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if inviter, err := uuid.Parse(c.GetString("uid")); err == nil {
+		inv.InvitedBy = &inviter
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&inv).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionInvitationCreate,
+			EntityType: "invitation",
+			EntityID:   inv.ID.String(),
+			OrgID:      &orgID,
+			After:      inv,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create invitation"})
+		return
+	}
+
+	h.send(c, &inv, &org)
+	c.JSON(201, toInvitationResponse(inv))
+}
+
+// ListInvitations godoc
+// @Summary List an organization's invitations
+// @Description List invitations, pending ones by default (members:manage)
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param status query string false "PENDING (default), ACCEPTED, DECLINED, REVOKED or ALL"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/invitations [get]
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	// Parsed and authorized by authz.OrgScope
+	orgID := c.Param("id")
+
+	q := h.DB.Where("org_id = ?", orgID)
+	switch status := strings.ToUpper(c.DefaultQuery("status", string(db.InvitationPending))); db.InvitationStatus(status) {
+	case db.InvitationPending, db.InvitationAccepted, db.InvitationDeclined, db.InvitationRevoked:
+		q = q.Where("status = ?", status)
+	case "ALL":
+	default:
+		c.JSON(400, gin.H{"error": "invalid status"})
+		return
+	}
+
+	var invitations []db.Invitation
+	if err := q.Order("created_at desc").Find(&invitations).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch invitations"})
+		return
+	}
+
+	response := make([]InvitationResponse, len(invitations))
+	for i, inv := range invitations {
+		response[i] = toInvitationResponse(inv)
+	}
+	c.JSON(200, gin.H{"invitations": response})
+}
+
+// ResendInvitation godoc
+// @Summary Resend an invitation
+// @Description Send a new invitation link and restart its expiry; earlier links stop working (members:manage)
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param invitationId path string true "Invitation ID"
+// @Success 200 {object} InvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/invitations/{invitationId}/resend [post]
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	inv, ok := h.pendingInvitation(c)
+	if !ok {
+		return
+	}
+
+	var org db.Organization
+	if err := h.DB.Where("id = ?", inv.OrgID).First(&org).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch organization"})
+		return
+	}
+
+	nonce, err := auth.RandomToken(16)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to resend invitation"})
+		return
+	}
+
+	before := *inv
+	now := time.Now()
+	inv.Nonce = nonce
+	inv.ExpiresAt = now.Add(invitationTTL)
+	inv.SentAt = now
+	inv.UpdatedAt = now
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(inv).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionInvitationResend,
+			EntityType: "invitation",
+			EntityID:   inv.ID.String(),
+			OrgID:      &inv.OrgID,
+			Before:     before,
+			After:      *inv,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to resend invitation"})
+		return
+	}
+
+	h.send(c, inv, &org)
+	c.JSON(200, toInvitationResponse(*inv))
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation so its link can no longer be used (members:manage)
+// @Tags invitations
+// @Param id path string true "Organization ID"
+// @Param invitationId path string true "Invitation ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations/{id}/invitations/{invitationId} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	inv, ok := h.pendingInvitation(c)
+	if !ok {
+		return
+	}
+
+	if err := h.respond(h.DB, c, inv, db.InvitationRevoked, nil, audit.ActionInvitationRevoke); err != nil {
+		c.JSON(500, gin.H{"error": "failed to revoke invitation"})
+		return
+	}
+	c.Status(204)
+}
+
+// PreviewInvitation godoc
+// @Summary Look up an invitation
+// @Description Show the organization, email and role of an invitation token
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param invitation body InvitationTokenRequest true "Invitation token"
+// @Success 200 {object} InvitationPreviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Router /invitations/preview [post]
+func (h *InvitationHandler) PreviewInvitation(c *gin.Context) {
+	var req InvitationTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv, ok := h.invitationFromToken(c, req.Token)
+	if !ok {
+		return
+	}
+
+	var org db.Organization
+	if err := h.DB.Where("id = ?", inv.OrgID).First(&org).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch organization"})
+		return
+	}
+
+	var accounts int64
+	if err := h.DB.Model(&db.User{}).Where("LOWER(email) = ?", inv.Email).Count(&accounts).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch user"})
+		return
+	}
+
+	c.JSON(200, InvitationPreviewResponse{
+		OrganizationName: org.Name,
+		Email:            inv.Email,
+		Role:             string(inv.Role),
+		ExpiresAt:        inv.ExpiresAt,
+		AccountExists:    accounts > 0,
+	})
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Join the organization. Signed-in users join with their account, which must match the invited email; otherwise a new account is created from displayName and password.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param invitation body AcceptInvitationRequest true "Invitation token and, for new accounts, profile"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv, ok := h.invitationFromToken(c, req.Token)
+	if !ok {
+		return
+	}
+
+	var user db.User
+	newAccount := false
+	if callerID, err := uuid.Parse(c.GetString("uid")); err == nil {
+		if err := h.DB.Where("id = ?", callerID).First(&user).Error; err != nil {
+			c.JSON(401, gin.H{"error": "unauthorized"})
+			return
+		}
+		if !strings.EqualFold(user.Email, inv.Email) {
+			c.JSON(403, gin.H{"error": "this invitation was sent to a different email address"})
+			return
+		}
+	} else if err := h.DB.Where("LOWER(email) = ?", inv.Email).First(&user).Error; err == nil {
+		// Existing accounts must prove they own it before joining
+		c.JSON(401, gin.H{"error": "sign in to accept this invitation", "code": "login_required"})
+		return
+	} else if err != gorm.ErrRecordNotFound {
+		c.JSON(500, gin.H{"error": "failed to fetch user"})
+		return
+	} else {
+		if strings.TrimSpace(req.DisplayName) == "" || len(req.Password) < 4 {
+			c.JSON(400, gin.H{"error": "displayName and a password of at least 4 characters are required"})
+			return
+		}
+
+		hasher := sha256.New()
+		hasher.Write([]byte(req.Password))
+
+		user = db.User{
+			ID:          uuid.New(),
+			Email:       inv.Email,
+			Password:    hex.EncodeToString(hasher.Sum(nil)),
+			DisplayName: strings.TrimSpace(req.DisplayName),
+			Role:        db.RoleCustomer,
+			CreatedAt:   time.Now(),
+		}
+		if inv.Role == db.OrgRoleOwner {
+			user.Role = db.RoleOwner
+		}
+		newAccount = true
+	}
+
+	// The invitee is the actor for the audit entry
+	c.Set("uid", user.ID.String())
+	c.Set("role", string(user.Role))
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if newAccount {
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		}
+
+		// Existing memberships keep their role
+		var member db.OrgMember
+		err := tx.Where("user_id = ? AND org_id = ?", user.ID, inv.OrgID).First(&member).Error
+		if err == gorm.ErrRecordNotFound {
+			member = db.OrgMember{ID: uuid.New(), UserID: user.ID, OrgID: inv.OrgID, Role: inv.Role}
+			err = tx.Create(&member).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return h.respond(tx, c, inv, db.InvitationAccepted, &user.ID, audit.ActionInvitationAccept)
+	})
+	if err != nil {
+		if err == errInvitationGone {
+			c.JSON(410, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": "failed to accept invitation"})
+		return
+	}
+
+	token, err := auth.IssueToken(user.ID.String(), string(user.Role))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"user": UserResponse{
+			ID:          user.ID.String(),
+			Email:       user.Email,
+			DisplayName: user.DisplayName,
+			Role:        string(user.Role),
+			CreatedAt:   user.CreatedAt,
+		},
+		"token": token,
+		"orgId": inv.OrgID.String(),
+		"role":  string(inv.Role),
+	})
+}
+
+// DeclineInvitation godoc
+// @Summary Decline an invitation
+// @Tags invitations
+// @Accept json
+// @Param invitation body InvitationTokenRequest true "Invitation token"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /invitations/decline [post]
+func (h *InvitationHandler) DeclineInvitation(c *gin.Context) {
+	var req InvitationTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv, ok := h.invitationFromToken(c, req.Token)
+	if !ok {
+		return
+	}
+
+	if err := h.respond(h.DB, c, inv, db.InvitationDeclined, nil, audit.ActionInvitationDecline); err != nil {
+		if err == errInvitationGone {
+			c.JSON(410, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": "failed to decline invitation"})
+		return
+	}
+	c.Status(204)
+}
+
+var errInvitationGone = errors.New("invitation is no longer pending")
+
+// respond moves a pending invitation to status and records it. The update
+// is conditional on the invitation still being pending with the same
+// nonce, so concurrent responses cannot both succeed.
+func (h *InvitationHandler) respond(gdb *gorm.DB, c *gin.Context, inv *db.Invitation, status db.InvitationStatus, acceptedBy *uuid.UUID, action string) error {
+	before := *inv
+	now := time.Now()
+	inv.Status = status
+	inv.AcceptedBy = acceptedBy
+	inv.RespondedAt = &now
+	inv.UpdatedAt = now
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&db.Invitation{}).
+			Where("id = ? AND status = ? AND nonce = ?", inv.ID, db.InvitationPending, before.Nonce).
+			Updates(map[string]interface{}{
+				"status":       inv.Status,
+				"accepted_by":  inv.AcceptedBy,
+				"responded_at": inv.RespondedAt,
+				"updated_at":   inv.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvitationGone
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     action,
+			EntityType: "invitation",
+			EntityID:   inv.ID.String(),
+			OrgID:      &inv.OrgID,
+			Before:     before,
+			After:      *inv,
+		})
+	})
+}
+
+// pendingInvitation loads the :invitationId invitation of the :id
+// organization and requires it to be pending
+func (h *InvitationHandler) pendingInvitation(c *gin.Context) (*db.Invitation, bool) {
+	invitationID, err := uuid.Parse(c.Param("invitationId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid invitation ID"})
+		return nil, false
+	}
+
+	var inv db.Invitation
+	if err := h.DB.Where("id = ? AND org_id = ?", invitationID, c.Param("id")).First(&inv).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(404, gin.H{"error": "invitation not found"})
+			return nil, false
+		}
+		c.JSON(500, gin.H{"error": "failed to fetch invitation"})
+		return nil, false
+	}
+	if inv.Status != db.InvitationPending {
+		c.JSON(409, gin.H{"error": "invitation is " + strings.ToLower(string(inv.Status))})
+		return nil, false
+	}
+	return &inv, true
+}
+
+// invitationFromToken resolves an emailed token to its pending invitation.
+// Tokens replaced by a resend, answered or expired invitations are rejected.
+func (h *InvitationHandler) invitationFromToken(c *gin.Context, token string) (*db.Invitation, bool) {
+	claims, err := auth.ParseInviteToken(token)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid or expired invitation"})
+		return nil, false
+	}
+
+	var inv db.Invitation
+	if err := h.DB.Where("id = ?", claims.InvitationID).First(&inv).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(400, gin.H{"error": "invalid or expired invitation"})
+			return nil, false
+		}
+		c.JSON(500, gin.H{"error": "failed to fetch invitation"})
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(inv.Nonce), []byte(claims.Nonce)) != 1 {
+		c.JSON(400, gin.H{"error": "this invitation link has been replaced by a newer one"})
+		return nil, false
+	}
+	if inv.Status != db.InvitationPending {
+		c.JSON(410, gin.H{"error": "invitation has already been " + strings.ToLower(string(inv.Status))})
+		return nil, false
+	}
+	if time.Now().After(inv.ExpiresAt) {
+		c.JSON(410, gin.H{"error": "invitation has expired"})
+		return nil, false
+	}
+	return &inv, true
+}
+
+// send emails the invitation link. Failures are logged; the owner can resend.
+func (h *InvitationHandler) send(c *gin.Context, inv *db.Invitation, org *db.Organization) {
+	if h.Mailer == nil {
+		return
+	}
+
+	token, err := auth.IssueInviteToken(inv.ID.String(), inv.Nonce, inv.ExpiresAt)
+	if err != nil {
+		log.Printf("failed to sign invitation %s: %v", inv.ID, err)
+		return
+	}
+
+	appURL := h.AppURL
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+	link := strings.TrimSuffix(appURL, "/") + "/invitations/accept?token=" + url.QueryEscape(token)
+
+	inviter := "A team member"
+	if inv.InvitedBy != nil {
+		var user db.User
+		if err := h.DB.Where("id = ?", *inv.InvitedBy).First(&user).Error; err == nil && user.DisplayName != "" {
+			inviter = user.DisplayName
+		}
+	}
+
+	msg := notify.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("You're invited to join %s", org.Name),
+		Body: fmt.Sprintf("Hi,\n\n%s has invited you to join %s as %s.\n\n"+
+			"Accept or decline the invitation here:\n%s\n\n"+
+			"The link expires on %s.\n",
+			inviter, org.Name, strings.ToLower(string(inv.Role)), link,
+			inv.ExpiresAt.UTC().Format(time.RFC1123)),
+	}
+	if err := h.Mailer.Send(msg); err != nil {
+		log.Printf("failed to send invitation %s to %s: %v", inv.ID, inv.Email, err)
+	}
+}
+
+func toInvitationResponse(inv db.Invitation) InvitationResponse {
+	return InvitationResponse{
+		ID:          inv.ID.String(),
+		OrgID:       inv.OrgID.String(),
+		Email:       inv.Email,
+		Role:        string(inv.Role),
+		Status:      string(inv.Status),
+		Expired:     inv.Status == db.InvitationPending && time.Now().After(inv.ExpiresAt),
+		ExpiresAt:   inv.ExpiresAt,
+		SentAt:      inv.SentAt,
+		RespondedAt: inv.RespondedAt,
+		CreatedAt:   inv.CreatedAt,
+	}
+}
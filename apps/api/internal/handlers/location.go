@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/geo"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RestaurantLocation is where a restaurant is on the map. Latitude and
+// longitude are left out until it has been placed.
+type RestaurantLocation struct {
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Geocoded  bool     `json:"geocoded"` // Placed from its address rather than by its owner
+}
+
+// SetRestaurantLocationRequest places a restaurant at a latitude and
+// longitude or, with geocode, from its address and place
+type SetRestaurantLocationRequest struct {
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Geocode   bool     `json:"geocode"`
+}
+
+func toRestaurantLocation(restaurant db.Restaurant) RestaurantLocation {
+	return RestaurantLocation{
+		Latitude:  restaurant.Latitude,
+		Longitude: restaurant.Longitude,
+		Geocoded:  restaurant.Geocoded,
+	}
+}
+
+// GET /api/owner/restaurants/:id/location - Get position on the map (restaurant:read)
+func (h *RestaurantHandler) GetRestaurantLocation(c *gin.Context) {
+	c.JSON(200, toRestaurantLocation(*authz.ScopedRestaurant(c)))
+}
+
+// PUT /api/owner/restaurants/:id/location - Set or geocode position on the map (restaurant:write)
+func (h *RestaurantHandler) SetRestaurantLocation(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	var req SetRestaurantLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := toRestaurantLocation(*restaurant)
+	if req.Geocode {
+		if h.Geocoder == nil {
+			c.JSON(503, gin.H{"error": "geocoding is not available"})
+			return
+		}
+		p, err := h.Geocoder.Geocode(c.Request.Context(), restaurantAddress(*restaurant))
+		if errors.Is(err, geo.ErrNotFound) {
+			c.JSON(422, gin.H{"error": "address could not be placed on the map; set latitude and longitude instead"})
+			return
+		}
+		if err != nil {
+			c.JSON(502, gin.H{"error": "failed to geocode address"})
+			return
+		}
+		placeRestaurant(restaurant, &p, true)
+	} else {
+		p, err := requestLocation(req.Latitude, req.Longitude)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if p == nil {
+			c.JSON(400, gin.H{"error": "latitude and longitude, or geocode, is required"})
+			return
+		}
+		placeRestaurant(restaurant, p, false)
+	}
+	restaurant.UpdatedAt = time.Now()
+	after := toRestaurantLocation(*restaurant)
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(restaurant).
+			Select("latitude", "longitude", "geocoded", "updated_at").
+			Updates(restaurant).Error
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantLocation,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      after,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update location"})
+		return
+	}
+
+	c.JSON(200, after)
+}
+
+// requestLocation reads the latitude and longitude of a request, nil when
+// both are left out
+func requestLocation(latitude, longitude *float64) (*geo.Point, error) {
+	if latitude == nil && longitude == nil {
+		return nil, nil
+	}
+	if latitude == nil || longitude == nil {
+		return nil, fmt.Errorf("latitude and longitude must be given together")
+	}
+	p := geo.Point{Lat: *latitude, Lng: *longitude}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// placeRestaurant sets a restaurant's position, or clears it when p is nil
+func placeRestaurant(restaurant *db.Restaurant, p *geo.Point, geocoded bool) {
+	if p == nil {
+		restaurant.Latitude, restaurant.Longitude, restaurant.Geocoded = nil, nil, false
+		return
+	}
+	lat, lng := p.Lat, p.Lng
+	restaurant.Latitude, restaurant.Longitude, restaurant.Geocoded = &lat, &lng, geocoded
+}
+
+// locateRestaurant places a restaurant whose address has changed: where the
+// request put it or, failing that, where its address is. A position set by
+// the owner is kept when the address alone changes, and one geocoded from
+// an old address is dropped when the new one cannot be placed. Geocoding
+// is best effort and never fails the request.
+func (h *RestaurantHandler) locateRestaurant(ctx context.Context, restaurant *db.Restaurant, requested *geo.Point) {
+	if requested != nil {
+		placeRestaurant(restaurant, requested, false)
+		return
+	}
+	if restaurant.Latitude != nil && !restaurant.Geocoded {
+		return
+	}
+	if h.Geocoder == nil {
+		return
+	}
+	p, err := h.Geocoder.Geocode(ctx, restaurantAddress(*restaurant))
+	if err != nil {
+		if !errors.Is(err, geo.ErrNotFound) {
+			log.Printf("geocoding restaurant %s failed: %v", restaurant.ID, err)
+		}
+		placeRestaurant(restaurant, nil, false)
+		return
+	}
+	placeRestaurant(restaurant, &p, true)
+}
+
+func restaurantAddress(restaurant db.Restaurant) geo.Address {
+	return geo.Address{Address: restaurant.Address, Area: restaurant.Area, Place: restaurant.Place}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingGeocoder struct{}
+
+func (failingGeocoder) Geocode(context.Context, geo.Address) (geo.Point, error) {
+	return geo.Point{}, errors.New("service unavailable")
+}
+
+func TestRequestLocation(t *testing.T) {
+	lat, lng, far := 27.7, 85.3, 200.0
+
+	p, err := requestLocation(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	p, err = requestLocation(&lat, &lng)
+	require.NoError(t, err)
+	assert.Equal(t, &geo.Point{Lat: lat, Lng: lng}, p)
+
+	_, err = requestLocation(&lat, nil)
+	assert.Error(t, err)
+	_, err = requestLocation(&far, &lng)
+	assert.Error(t, err)
+}
+
+func TestLocateRestaurant(t *testing.T) {
+	h := RestaurantHandler{Geocoder: geo.NewStaticGeocoder()}
+	ctx := context.Background()
+	thamel := geo.KnownPlaces["thamel"]
+
+	// Geocoded from the address
+	restaurant := db.Restaurant{Address: "Chaksibari Marg, Thamel", Place: "Kathmandu"}
+	h.locateRestaurant(ctx, &restaurant, nil)
+	require.NotNil(t, restaurant.Latitude)
+	assert.Equal(t, thamel, geo.Point{Lat: *restaurant.Latitude, Lng: *restaurant.Longitude})
+	assert.True(t, restaurant.Geocoded)
+
+	// Geocoded again when the address moves
+	restaurant.Address, restaurant.Place = "Lakeside", "Pokhara"
+	h.locateRestaurant(ctx, &restaurant, nil)
+	assert.Equal(t, geo.KnownPlaces["lakeside"], geo.Point{Lat: *restaurant.Latitude, Lng: *restaurant.Longitude})
+
+	// A position from an old address is dropped when the new one is unknown
+	restaurant.Address, restaurant.Place = "", "Atlantis"
+	h.locateRestaurant(ctx, &restaurant, nil)
+	assert.Nil(t, restaurant.Latitude)
+	assert.Nil(t, restaurant.Longitude)
+
+	// A position set by the owner is kept
+	h.locateRestaurant(ctx, &restaurant, &thamel)
+	assert.False(t, restaurant.Geocoded)
+	restaurant.Place = "Pokhara"
+	h.locateRestaurant(ctx, &restaurant, nil)
+	assert.Equal(t, thamel, geo.Point{Lat: *restaurant.Latitude, Lng: *restaurant.Longitude})
+
+	// Geocoding failures leave the restaurant off the map
+	failing := RestaurantHandler{Geocoder: failingGeocoder{}}
+	restaurant = db.Restaurant{Place: "Kathmandu"}
+	failing.locateRestaurant(ctx, &restaurant, nil)
+	assert.Nil(t, restaurant.Latitude)
+}
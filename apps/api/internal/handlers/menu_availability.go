@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxAvailabilityRules limits the rules of one item or category
+const maxAvailabilityRules = 20
+
+// Request/Response DTOs
+type AvailabilityRule struct {
+	// Days the rule applies, 0=Sunday ... 6=Saturday; empty for every day
+	DaysOfWeek []int  `json:"daysOfWeek"`
+	StartTime  string `json:"startTime"` // "11:00"; empty with EndTime for the whole day
+	EndTime    string `json:"endTime"`   // "15:00"; before StartTime for windows past midnight
+	StartDate  string `json:"startDate"` // "2025-12-01", optional
+	EndDate    string `json:"endDate"`   // "2026-02-28", optional and inclusive
+}
+
+// SetAvailabilityRequest replaces all rules; an empty list makes the item
+// or category always available again
+type SetAvailabilityRequest struct {
+	Rules []AvailabilityRule `json:"rules"`
+}
+
+type SoldOutRequest struct {
+	// When the item is back on sale; defaults to the next midnight in the
+	// restaurant's timezone
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// GetMenuAvailability godoc
+// @Summary Get a menu item's availability rules
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param menuId path string true "Menu ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/{menuId}/availability [get]
+func (h *MenuHandler) GetMenuAvailability(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	menu, ok := h.menuParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	h.listAvailability(c, "menu_id = ?", menu.ID)
+}
+
+// SetMenuAvailability godoc
+// @Summary Set a menu item's availability rules
+// @Description Replace the item's rules. An item with rules of its own ignores its category's rules.
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param menuId path string true "Menu ID"
+// @Param rules body SetAvailabilityRequest true "Availability rules"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/{menuId}/availability [put]
+func (h *MenuHandler) SetMenuAvailability(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	menu, ok := h.menuParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	h.setAvailability(c, restaurant, db.MenuAvailability{MenuID: &menu.ID}, "menu_id = ?", menu.ID,
+		audit.ActionMenuAvailability, "menu")
+}
+
+// GetMenuCategoryAvailability godoc
+// @Summary Get a menu category's availability rules
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param categoryId path string true "Category ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-categories/{categoryId}/availability [get]
+func (h *MenuHandler) GetMenuCategoryAvailability(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	_, category, ok := h.categoryParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	h.listAvailability(c, "category_id = ?", category.ID)
+}
+
+// SetMenuCategoryAvailability godoc
+// @Summary Set a menu category's availability rules
+// @Description Replace the category's rules. They apply to its items and sub-categories that have no rules of their own.
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param categoryId path string true "Category ID"
+// @Param rules body SetAvailabilityRequest true "Availability rules"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-categories/{categoryId}/availability [put]
+func (h *MenuHandler) SetMenuCategoryAvailability(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	_, category, ok := h.categoryParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	h.setAvailability(c, restaurant, db.MenuAvailability{CategoryID: &category.ID}, "category_id = ?", category.ID,
+		audit.ActionCategoryAvailability, "menu_category")
+}
+
+// MarkMenuSoldOut godoc
+// @Summary Mark a menu item sold out
+// @Description 86 an item. It is back on sale at the given time, by default the next midnight in the restaurant's timezone. (menus:stock)
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param menuId path string true "Menu ID"
+// @Param soldOut body SoldOutRequest false "Back on sale at"
+// @Success 200 {object} MenuResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/{menuId}/sold-out [post]
+func (h *MenuHandler) MarkMenuSoldOut(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	menu, ok := h.menuParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	var req SoldOutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	now := time.Now()
+	until := services.NextDayStart(now, services.RestaurantLocation(*restaurant))
+	if req.Until != nil {
+		if !req.Until.After(now) {
+			c.JSON(400, gin.H{"error": "until must be in the future"})
+			return
+		}
+		until = *req.Until
+	}
+
+	h.setSoldOut(c, restaurant, menu, &until, audit.ActionMenuSoldOut)
+}
+
+// ClearMenuSoldOut godoc
+// @Summary Put a sold-out menu item back on sale
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param menuId path string true "Menu ID"
+// @Success 200 {object} MenuResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/{menuId}/sold-out [delete]
+func (h *MenuHandler) ClearMenuSoldOut(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	menu, ok := h.menuParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	h.setSoldOut(c, restaurant, menu, nil, audit.ActionMenuBackInStock)
+}
+
+func (h *MenuHandler) setSoldOut(c *gin.Context, restaurant *db.Restaurant, menu *db.Menu, until *time.Time, action string) {
+	before := *menu
+	menu.SoldOutUntil = until
+	menu.UpdatedAt = time.Now()
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&db.Menu{}).Where("id = ?", menu.ID).Updates(map[string]interface{}{
+			"sold_out_until": until,
+			"updated_at":     menu.UpdatedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     action,
+			EntityType: "menu",
+			EntityID:   menu.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      *menu,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update menu"})
+		return
+	}
+
+	c.JSON(200, toMenuResponse(*menu))
+}
+
+func (h *MenuHandler) listAvailability(c *gin.Context, where string, id uuid.UUID) {
+	var rules []db.MenuAvailability
+	if err := h.DB.Where(where, id).Order("start_date ASC, start_time ASC").Find(&rules).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch availability"})
+		return
+	}
+	c.JSON(200, gin.H{"rules": toAvailabilityRules(rules)})
+}
+
+// setAvailability replaces the rules selected by where with the request's,
+// created from template (which carries the item or category ID)
+func (h *MenuHandler) setAvailability(c *gin.Context, restaurant *db.Restaurant, template db.MenuAvailability, where string, id uuid.UUID, action, entityType string) {
+	var req SetAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	rules, err := parseAvailabilityRules(req.Rules)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var before []db.MenuAvailability
+		if err := tx.Where(where, id).Find(&before).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(where, id).Delete(&db.MenuAvailability{}).Error; err != nil {
+			return err
+		}
+		for i := range rules {
+			rules[i].ID = uuid.New()
+			rules[i].RestaurantID = restaurant.ID
+			rules[i].MenuID = template.MenuID
+			rules[i].CategoryID = template.CategoryID
+		}
+		if len(rules) > 0 {
+			if err := tx.Create(&rules).Error; err != nil {
+				return err
+			}
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     action,
+			EntityType: entityType,
+			EntityID:   id.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     gin.H{"rules": toAvailabilityRules(before)},
+			After:      gin.H{"rules": toAvailabilityRules(rules)},
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update availability"})
+		return
+	}
+
+	c.JSON(200, gin.H{"rules": toAvailabilityRules(rules)})
+}
+
+// menuParam loads the :menuId item of the restaurant
+func (h *MenuHandler) menuParam(c *gin.Context, restaurantID uuid.UUID) (*db.Menu, bool) {
+	menuID, err := uuid.Parse(c.Param("menuId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid menu ID"})
+		return nil, false
+	}
+
+	var menu db.Menu
+	if err := withMenuOptions(h.DB).Where("id = ? AND restaurant_id = ?", menuID, restaurantID).First(&menu).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(404, gin.H{"error": "menu not found"})
+			return nil, false
+		}
+		c.JSON(500, gin.H{"error": "failed to fetch menu"})
+		return nil, false
+	}
+	return &menu, true
+}
+
+// menuSchedule loads what is needed to tell which of the restaurant's
+// items can be ordered when
+func (h *MenuHandler) menuSchedule(restaurant db.Restaurant, categories []db.MenuCategory) (*services.MenuSchedule, error) {
+	var rules []db.MenuAvailability
+	if err := h.DB.Where("restaurant_id = ?", restaurant.ID).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return services.NewMenuSchedule(services.RestaurantLocation(restaurant), rules, categories), nil
+}
+
+// availabilityTime reads the at= query parameter. Without it availability
+// is evaluated for now and unavailable items are still listed.
+func availabilityTime(c *gin.Context) (at time.Time, onlyAvailable bool, err error) {
+	value := c.Query("at")
+	if value == "" {
+		return time.Now(), false, nil
+	}
+	at, err = time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid at time, expected RFC3339")
+	}
+	return at, true, nil
+}
+
+// markAvailable sets the availability of the items of a menu tree
+func markAvailable(tree []MenuCategoryResponse, items []MenuResponse, available map[uuid.UUID]bool) {
+	for i := range items {
+		isAvailable := available[uuid.MustParse(items[i].ID)]
+		items[i].Available = &isAvailable
+	}
+	for i := range tree {
+		markAvailable(tree[i].Children, tree[i].Items, available)
+	}
+}
+
+// parseAvailabilityRules validates rules from a request
+func parseAvailabilityRules(input []AvailabilityRule) ([]db.MenuAvailability, error) {
+	if len(input) > maxAvailabilityRules {
+		return nil, fmt.Errorf("at most %d availability rules are allowed", maxAvailabilityRules)
+	}
+
+	rules := make([]db.MenuAvailability, 0, len(input))
+	for i, in := range input {
+		rule := db.MenuAvailability{
+			Weekdays:  services.AllWeekdays,
+			StartTime: in.StartTime,
+			EndTime:   in.EndTime,
+			StartDate: in.StartDate,
+			EndDate:   in.EndDate,
+		}
+
+		if len(in.DaysOfWeek) > 0 {
+			rule.Weekdays = 0
+			for _, d := range in.DaysOfWeek {
+				if d < 0 || d > 6 {
+					return nil, fmt.Errorf("rule %d: days of week go from 0 (Sunday) to 6 (Saturday)", i+1)
+				}
+				rule.Weekdays |= 1 << uint(d)
+			}
+		}
+
+		if (in.StartTime == "") != (in.EndTime == "") {
+			return nil, fmt.Errorf("rule %d: startTime and endTime must be given together", i+1)
+		}
+		if in.StartTime != "" {
+			start, err := services.ParseClock(in.StartTime)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			end, err := services.ParseClock(in.EndTime)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			if start == end {
+				return nil, fmt.Errorf("rule %d: startTime and endTime cannot be equal", i+1)
+			}
+		}
+
+		for _, date := range []string{in.StartDate, in.EndDate} {
+			if date == "" {
+				continue
+			}
+			if err := services.ParseDate(date); err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+		}
+		if in.StartDate != "" && in.EndDate != "" && in.EndDate < in.StartDate {
+			return nil, fmt.Errorf("rule %d: endDate is before startDate", i+1)
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func toAvailabilityRules(rules []db.MenuAvailability) []AvailabilityRule {
+	response := make([]AvailabilityRule, 0, len(rules))
+	for _, rule := range rules {
+		days := []int{}
+		for d := 0; d < 7; d++ {
+			if rule.Weekdays&(1<<uint(d)) != 0 {
+				days = append(days, d)
+			}
+		}
+		response = append(response, AvailabilityRule{
+			DaysOfWeek: days,
+			StartTime:  rule.StartTime,
+			EndTime:    rule.EndTime,
+			StartDate:  rule.StartDate,
+			EndDate:    rule.EndDate,
+		})
+	}
+	return response
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAvailabilityRules(t *testing.T) {
+	rules, err := parseAvailabilityRules([]AvailabilityRule{
+		{DaysOfWeek: []int{1, 2, 3, 4, 5}, StartTime: "11:00", EndTime: "15:00"},
+		{StartTime: "22:00", EndTime: "02:00"},
+		{StartDate: "2025-12-01", EndDate: "2026-02-28"},
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.Equal(t, 0b0111110, rules[0].Weekdays)
+	assert.Equal(t, services.AllWeekdays, rules[1].Weekdays)
+	assert.Equal(t, services.AllWeekdays, rules[2].Weekdays)
+
+	// Round trip
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, toAvailabilityRules(rules)[0].DaysOfWeek)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, toAvailabilityRules(rules)[1].DaysOfWeek)
+
+	rules, err = parseAvailabilityRules(nil)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestParseAvailabilityRules_Invalid(t *testing.T) {
+	tests := map[string]AvailabilityRule{
+		"day out of range":   {DaysOfWeek: []int{7}},
+		"start without end":  {StartTime: "11:00"},
+		"bad time":           {StartTime: "11am", EndTime: "15:00"},
+		"empty window":       {StartTime: "11:00", EndTime: "11:00"},
+		"bad date":           {StartDate: "01/12/2025"},
+		"end before start":   {StartDate: "2026-02-28", EndDate: "2025-12-01"},
+		"out of range month": {EndDate: "2025-13-01"},
+	}
+	for name, rule := range tests {
+		_, err := parseAvailabilityRules([]AvailabilityRule{rule})
+		assert.Error(t, err, name)
+	}
+}
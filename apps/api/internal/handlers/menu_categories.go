@@ -0,0 +1,728 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxCategoryDepth limits nesting to sections with sub-sections and one
+// further level, which is as deep as menus are printed
+const maxCategoryDepth = 3
+
+var (
+	errCategoryNotFound = errors.New("category not found")
+	errCategoryCycle    = errors.New("a category cannot be moved inside itself")
+	errCategoryDepth    = errors.New("categories can be nested at most 3 levels deep")
+)
+
+// Request/Response DTOs
+type CreateMenuCategoryRequest struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	ParentID    *string `json:"parentId,omitempty"`
+}
+
+type UpdateMenuCategoryRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// Moves the category; an empty string makes it top level
+	ParentID *string `json:"parentId,omitempty"`
+}
+
+// ReorderMenuCategoriesRequest places categories, in order, under ParentID
+// (top level when empty). Categories coming from another parent are moved.
+type ReorderMenuCategoriesRequest struct {
+	ParentID    string   `json:"parentId"`
+	CategoryIDs []string `json:"categoryIds" binding:"required"`
+}
+
+// ReorderMenusRequest places menu items, in order, in CategoryID
+// (uncategorized when empty). Items coming from another category are moved.
+type ReorderMenusRequest struct {
+	CategoryID string   `json:"categoryId"`
+	MenuIDs    []string `json:"menuIds" binding:"required"`
+}
+
+type MenuCategoryResponse struct {
+	ID          string                 `json:"id"`
+	ParentID    *string                `json:"parentId"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	SortOrder   int                    `json:"sortOrder"`
+	Items       []MenuResponse         `json:"items,omitempty"`
+	Children    []MenuCategoryResponse `json:"children"`
+}
+
+// ListMenuCategories godoc
+// @Summary List menu categories
+// @Description Get the restaurant's menu categories as a tree
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-categories [get]
+func (h *MenuHandler) ListMenuCategories(c *gin.Context) {
+	// Loaded and authorized by authz.RestaurantScope
+	restaurantUUID := authz.ScopedRestaurant(c).ID
+
+	categories, err := h.categories(restaurantUUID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch categories"})
+		return
+	}
+
+	tree, _ := buildMenuTree(categories, nil, false)
+	c.JSON(200, gin.H{"categories": tree})
+}
+
+// CreateMenuCategory godoc
+// @Summary Create a menu category
+// @Description Create a category, optionally inside another one. It is placed last among its siblings.
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param category body CreateMenuCategoryRequest true "Category"
+// @Success 201 {object} MenuCategoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-categories [post]
+func (h *MenuHandler) CreateMenuCategory(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	var req CreateMenuCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	categories, err := h.categories(restaurant.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch categories"})
+		return
+	}
+
+	category := db.MenuCategory{
+		ID:           uuid.New(),
+		RestaurantID: restaurant.ID,
+		Name:         strings.TrimSpace(req.Name),
+		Description:  req.Description,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if req.ParentID != nil && *req.ParentID != "" {
+		parentID, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid parent ID"})
+			return
+		}
+		category.ParentID = &parentID
+	}
+	if err := checkCategoryPlacement(categories, category.ID, category.ParentID); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	category.SortOrder = nextCategorySortOrder(categories, category.ParentID)
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuCategoryCreate,
+			EntityType: "menu_category",
+			EntityID:   category.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			After:      category,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create category"})
+		return
+	}
+
+	c.JSON(201, toMenuCategoryResponse(category))
+}
+
+// UpdateMenuCategory godoc
+// @Summary Update a menu category
+// @Description Rename, describe or move a category. A moved category is placed last under its new parent.
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param categoryId path string true "Category ID"
+// @Param category body UpdateMenuCategoryRequest true "Category update"
+// @Success 200 {object} MenuCategoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-categories/{categoryId} [put]
+func (h *MenuHandler) UpdateMenuCategory(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	categories, category, ok := h.categoryParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	var req UpdateMenuCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := *category
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			c.JSON(400, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		category.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+	}
+	if req.ParentID != nil {
+		var parentID *uuid.UUID
+		if *req.ParentID != "" {
+			id, err := uuid.Parse(*req.ParentID)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid parent ID"})
+				return
+			}
+			parentID = &id
+		}
+		if !sameID(parentID, category.ParentID) {
+			if err := checkCategoryPlacement(categories, category.ID, parentID); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			category.ParentID = parentID
+			category.SortOrder = nextCategorySortOrder(categories, parentID)
+		}
+	}
+	category.UpdatedAt = time.Now()
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuCategoryUpdate,
+			EntityType: "menu_category",
+			EntityID:   category.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      *category,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update category"})
+		return
+	}
+
+	c.JSON(200, toMenuCategoryResponse(*category))
+}
+
+// DeleteMenuCategory godoc
+// @Summary Delete a menu category
+// @Description Delete a category. Its sub-categories and items move up to its parent, after the parent's existing entries.
+// @Tags menus
+// @Param restaurantId path string true "Restaurant ID"
+// @Param categoryId path string true "Category ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-categories/{categoryId} [delete]
+func (h *MenuHandler) DeleteMenuCategory(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	categories, category, ok := h.categoryParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Sub-categories keep their relative order after the parent's own
+		next := nextCategorySortOrder(categories, category.ParentID)
+		var children []db.MenuCategory
+		for _, child := range categories {
+			if child.ParentID != nil && *child.ParentID == category.ID {
+				children = append(children, child)
+			}
+		}
+		sort.SliceStable(children, func(i, j int) bool { return children[i].SortOrder < children[j].SortOrder })
+		for i, child := range children {
+			if err := tx.Model(&db.MenuCategory{}).Where("id = ?", child.ID).Updates(map[string]interface{}{
+				"parent_id":  category.ParentID,
+				"sort_order": next + i,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		var maxOrder *int
+		q := tx.Model(&db.Menu{}).Where("restaurant_id = ?", restaurant.ID)
+		if category.ParentID == nil {
+			q = q.Where("category_id IS NULL")
+		} else {
+			q = q.Where("category_id = ?", *category.ParentID)
+		}
+		if err := q.Select("MAX(sort_order)").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+		offset := 0
+		if maxOrder != nil {
+			offset = *maxOrder + 1
+		}
+		if err := tx.Model(&db.Menu{}).Where("category_id = ?", category.ID).Updates(map[string]interface{}{
+			"category_id": category.ParentID,
+			"sort_order":  gorm.Expr("sort_order + ?", offset),
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(category).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuCategoryDelete,
+			EntityType: "menu_category",
+			EntityID:   category.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     *category,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to delete category"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReorderMenuCategories godoc
+// @Summary Reorder menu categories
+// @Description Set the order of categories under a parent, moving any listed category there (drag and drop)
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param order body ReorderMenuCategoriesRequest true "Parent and ordered category IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-categories/reorder [put]
+func (h *MenuHandler) ReorderMenuCategories(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	var req ReorderMenuCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var parentID *uuid.UUID
+	if req.ParentID != "" {
+		id, err := uuid.Parse(req.ParentID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid parent ID"})
+			return
+		}
+		parentID = &id
+	}
+	ids, err := parseIDList(req.CategoryIDs)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	categories, err := h.categories(restaurant.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch categories"})
+		return
+	}
+
+	// Validate every move against the tree as it will be after the
+	// previous moves, so that moving a group of siblings together works
+	byID := make(map[uuid.UUID]int, len(categories))
+	for i, category := range categories {
+		byID[category.ID] = i
+	}
+	for _, id := range ids {
+		i, ok := byID[id]
+		if !ok {
+			c.JSON(400, gin.H{"error": errCategoryNotFound.Error()})
+			return
+		}
+		if err := checkCategoryPlacement(categories, id, parentID); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		categories[i].ParentID = parentID
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Siblings not in the list keep their order after the listed ones
+		order := append([]uuid.UUID{}, ids...)
+		listed := make(map[uuid.UUID]bool, len(ids))
+		for _, id := range ids {
+			listed[id] = true
+		}
+		var rest []db.MenuCategory
+		for _, category := range categories {
+			if sameID(category.ParentID, parentID) && !listed[category.ID] {
+				rest = append(rest, category)
+			}
+		}
+		sort.SliceStable(rest, func(i, j int) bool { return rest[i].SortOrder < rest[j].SortOrder })
+		for _, category := range rest {
+			order = append(order, category.ID)
+		}
+
+		for i, id := range order {
+			if err := tx.Model(&db.MenuCategory{}).Where("id = ? AND restaurant_id = ?", id, restaurant.ID).Updates(map[string]interface{}{
+				"parent_id":  parentID,
+				"sort_order": i,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuCategoryReorder,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			After:      gin.H{"parentId": parentID, "categoryIds": order},
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to reorder categories"})
+		return
+	}
+
+	h.ListMenuCategories(c)
+}
+
+// ReorderMenus godoc
+// @Summary Reorder menu items
+// @Description Set the order of items in a category, moving any listed item there (drag and drop)
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param order body ReorderMenusRequest true "Category and ordered menu IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/reorder [put]
+func (h *MenuHandler) ReorderMenus(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	var req ReorderMenusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	categoryID, err := h.categoryInRestaurant(restaurant.ID, req.CategoryID)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	ids, err := parseIDList(req.MenuIDs)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid menu ID"})
+		return
+	}
+
+	var count int64
+	if err := h.DB.Model(&db.Menu{}).Where("id IN ? AND restaurant_id = ?", ids, restaurant.ID).Count(&count).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch menus"})
+		return
+	}
+	if int(count) != len(ids) {
+		c.JSON(400, gin.H{"error": "menu not found"})
+		return
+	}
+
+	var menus []db.Menu
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Items not in the list keep their order after the listed ones
+		var rest []db.Menu
+		q := tx.Where("restaurant_id = ?", restaurant.ID)
+		if categoryID == nil {
+			q = q.Where("category_id IS NULL")
+		} else {
+			q = q.Where("category_id = ?", *categoryID)
+		}
+		if len(ids) > 0 {
+			q = q.Where("id NOT IN ?", ids)
+		}
+		if err := q.Order("sort_order ASC, created_at ASC").Find(&rest).Error; err != nil {
+			return err
+		}
+		order := append([]uuid.UUID{}, ids...)
+		for _, menu := range rest {
+			order = append(order, menu.ID)
+		}
+
+		for i, id := range order {
+			if err := tx.Model(&db.Menu{}).Where("id = ?", id).Updates(map[string]interface{}{
+				"category_id": categoryID,
+				"sort_order":  i,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuReorder,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			After:      gin.H{"categoryId": categoryID, "menuIds": order},
+		}); err != nil {
+			return err
+		}
+		return withMenuOptions(tx).Where("id IN ?", order).Order("sort_order ASC").Find(&menus).Error
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to reorder menus"})
+		return
+	}
+
+	response := make([]MenuResponse, 0, len(menus))
+	for _, menu := range menus {
+		response = append(response, toMenuResponse(menu))
+	}
+	c.JSON(200, gin.H{"menus": response})
+}
+
+// categories loads all of a restaurant's categories
+func (h *MenuHandler) categories(restaurantID uuid.UUID) ([]db.MenuCategory, error) {
+	var categories []db.MenuCategory
+	err := h.DB.Where("restaurant_id = ?", restaurantID).Order("sort_order ASC, created_at ASC").Find(&categories).Error
+	return categories, err
+}
+
+// categoryParam resolves :categoryId among the restaurant's categories,
+// which it also returns
+func (h *MenuHandler) categoryParam(c *gin.Context, restaurantID uuid.UUID) ([]db.MenuCategory, *db.MenuCategory, bool) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid category ID"})
+		return nil, nil, false
+	}
+
+	categories, err := h.categories(restaurantID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch categories"})
+		return nil, nil, false
+	}
+	for i := range categories {
+		if categories[i].ID == categoryID {
+			category := categories[i]
+			return categories, &category, true
+		}
+	}
+	c.JSON(404, gin.H{"error": errCategoryNotFound.Error()})
+	return nil, nil, false
+}
+
+// categoryInRestaurant parses an optional category ID and checks that it
+// belongs to the restaurant. An empty id means no category.
+func (h *MenuHandler) categoryInRestaurant(restaurantID uuid.UUID, id string) (*uuid.UUID, error) {
+	if id == "" {
+		return nil, nil
+	}
+	categoryID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid category ID")
+	}
+	var count int64
+	if err := h.DB.Model(&db.MenuCategory{}).Where("id = ? AND restaurant_id = ?", categoryID, restaurantID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errCategoryNotFound
+	}
+	return &categoryID, nil
+}
+
+// nextMenuSortOrder returns the position after the last item of a category
+func (h *MenuHandler) nextMenuSortOrder(restaurantID uuid.UUID, categoryID *uuid.UUID) (int, error) {
+	var maxOrder *int
+	q := h.DB.Model(&db.Menu{}).Where("restaurant_id = ?", restaurantID)
+	if categoryID == nil {
+		q = q.Where("category_id IS NULL")
+	} else {
+		q = q.Where("category_id = ?", *categoryID)
+	}
+	if err := q.Select("MAX(sort_order)").Scan(&maxOrder).Error; err != nil {
+		return 0, err
+	}
+	if maxOrder == nil {
+		return 0, nil
+	}
+	return *maxOrder + 1, nil
+}
+
+// checkCategoryPlacement verifies that category id may live under parentID:
+// the parent exists, the move creates no cycle and the subtree stays within
+// maxCategoryDepth
+func checkCategoryPlacement(categories []db.MenuCategory, id uuid.UUID, parentID *uuid.UUID) error {
+	parents := make(map[uuid.UUID]*uuid.UUID, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	// Depth of the parent, walking up to the root
+	depth := 0
+	for p := parentID; p != nil; p = parents[*p] {
+		if *p == id {
+			return errCategoryCycle
+		}
+		if _, ok := parents[*p]; !ok {
+			return errCategoryNotFound
+		}
+		depth++
+		if depth > len(categories) {
+			return errCategoryCycle
+		}
+	}
+
+	// Height of the subtree being placed
+	var height func(uuid.UUID) int
+	height = func(node uuid.UUID) int {
+		h := 1
+		for child, parent := range parents {
+			if parent != nil && *parent == node {
+				if ch := height(child) + 1; ch > h {
+					h = ch
+				}
+			}
+		}
+		return h
+	}
+
+	if depth+height(id) > maxCategoryDepth {
+		return errCategoryDepth
+	}
+	return nil
+}
+
+// nextCategorySortOrder returns the position after the last child of parentID
+func nextCategorySortOrder(categories []db.MenuCategory, parentID *uuid.UUID) int {
+	next := 0
+	for _, category := range categories {
+		if sameID(category.ParentID, parentID) && category.SortOrder >= next {
+			next = category.SortOrder + 1
+		}
+	}
+	return next
+}
+
+// buildMenuTree arranges categories, and the given items, as a tree
+// ordered by SortOrder. Items without a (known) category are returned
+// separately. With prune set, categories without items anywhere below them
+// are left out.
+func buildMenuTree(categories []db.MenuCategory, menus []db.Menu, prune bool) ([]MenuCategoryResponse, []MenuResponse) {
+	children := make(map[uuid.UUID][]db.MenuCategory)
+	known := make(map[uuid.UUID]bool, len(categories))
+	var roots []db.MenuCategory
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+	for _, category := range categories {
+		if category.ParentID != nil && known[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	items := make(map[uuid.UUID][]MenuResponse)
+	uncategorized := []MenuResponse{}
+	sortedMenus := append([]db.Menu{}, menus...)
+	sort.SliceStable(sortedMenus, func(i, j int) bool { return sortedMenus[i].SortOrder < sortedMenus[j].SortOrder })
+	for _, menu := range sortedMenus {
+		if menu.CategoryID != nil && known[*menu.CategoryID] {
+			items[*menu.CategoryID] = append(items[*menu.CategoryID], toMenuResponse(menu))
+		} else {
+			uncategorized = append(uncategorized, toMenuResponse(menu))
+		}
+	}
+
+	var build func(level []db.MenuCategory) []MenuCategoryResponse
+	build = func(level []db.MenuCategory) []MenuCategoryResponse {
+		sort.SliceStable(level, func(i, j int) bool { return level[i].SortOrder < level[j].SortOrder })
+		nodes := []MenuCategoryResponse{}
+		for _, category := range level {
+			node := toMenuCategoryResponse(category)
+			node.Items = items[category.ID]
+			node.Children = build(children[category.ID])
+			if prune && len(node.Items) == 0 && len(node.Children) == 0 {
+				continue
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build(roots), uncategorized
+}
+
+func toMenuCategoryResponse(category db.MenuCategory) MenuCategoryResponse {
+	response := MenuCategoryResponse{
+		ID:          category.ID.String(),
+		Name:        category.Name,
+		Description: category.Description,
+		SortOrder:   category.SortOrder,
+		Children:    []MenuCategoryResponse{},
+	}
+	if category.ParentID != nil {
+		id := category.ParentID.String()
+		response.ParentID = &id
+	}
+	return response
+}
+
+// parseIDList parses a list of UUIDs, rejecting duplicates
+func parseIDList(values []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	seen := make(map[uuid.UUID]bool, len(values))
+	for _, v := range values {
+		id, err := uuid.Parse(v)
+		if err != nil || seen[id] {
+			return nil, errors.New("invalid ID list")
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func category(name string, parent *db.MenuCategory, sortOrder int) db.MenuCategory {
+	c := db.MenuCategory{ID: uuid.New(), Name: name, SortOrder: sortOrder}
+	if parent != nil {
+		c.ParentID = &parent.ID
+	}
+	return c
+}
+
+func menuIn(name string, c *db.MenuCategory, sortOrder int) db.Menu {
+	m := db.Menu{ID: uuid.New(), Name: name, SortOrder: sortOrder}
+	if c != nil {
+		m.CategoryID = &c.ID
+	}
+	return m
+}
+
+func TestBuildMenuTree(t *testing.T) {
+	drinks := category("Drinks", nil, 1)
+	food := category("Food", nil, 0)
+	starters := category("Starters", &food, 1)
+	mains := category("Mains", &food, 0)
+	desserts := category("Desserts", &food, 2)
+
+	menus := []db.Menu{
+		menuIn("Curry", &mains, 1),
+		menuIn("Momo", &mains, 0),
+		menuIn("Samosa", &starters, 0),
+		menuIn("Lassi", &drinks, 0),
+		menuIn("Special", nil, 0),
+	}
+	categories := []db.MenuCategory{drinks, starters, food, desserts, mains}
+
+	tree, uncategorized := buildMenuTree(categories, menus, false)
+	require.Len(t, tree, 2)
+	assert.Equal(t, "Food", tree[0].Name)
+	assert.Equal(t, "Drinks", tree[1].Name)
+	require.Len(t, tree[0].Children, 3)
+	assert.Equal(t, "Mains", tree[0].Children[0].Name)
+	assert.Equal(t, "Starters", tree[0].Children[1].Name)
+	assert.Equal(t, "Desserts", tree[0].Children[2].Name)
+	require.Len(t, tree[0].Children[0].Items, 2)
+	assert.Equal(t, "Momo", tree[0].Children[0].Items[0].Name)
+	assert.Equal(t, "Curry", tree[0].Children[0].Items[1].Name)
+	require.Len(t, uncategorized, 1)
+	assert.Equal(t, "Special", uncategorized[0].Name)
+
+	// Pruning drops categories with no items below them
+	tree, _ = buildMenuTree(categories, menus, true)
+	require.Len(t, tree[0].Children, 2)
+	assert.Equal(t, "Starters", tree[0].Children[1].Name)
+
+	// Items of unknown categories are listed as uncategorized
+	_, uncategorized = buildMenuTree(nil, menus, true)
+	assert.Len(t, uncategorized, len(menus))
+}
+
+func TestCheckCategoryPlacement(t *testing.T) {
+	food := category("Food", nil, 0)
+	mains := category("Mains", &food, 0)
+	curries := category("Curries", &mains, 0)
+	drinks := category("Drinks", nil, 1)
+	categories := []db.MenuCategory{food, mains, curries, drinks}
+
+	assert.NoError(t, checkCategoryPlacement(categories, drinks.ID, &food.ID))
+	assert.NoError(t, checkCategoryPlacement(categories, curries.ID, nil))
+	assert.NoError(t, checkCategoryPlacement(categories, uuid.New(), &mains.ID))
+
+	// Cycles
+	assert.ErrorIs(t, checkCategoryPlacement(categories, food.ID, &food.ID), errCategoryCycle)
+	assert.ErrorIs(t, checkCategoryPlacement(categories, food.ID, &curries.ID), errCategoryCycle)
+
+	// Depth
+	assert.ErrorIs(t, checkCategoryPlacement(categories, uuid.New(), &curries.ID), errCategoryDepth)
+	assert.ErrorIs(t, checkCategoryPlacement(categories, food.ID, &drinks.ID), errCategoryDepth)
+
+	// Unknown parent
+	unknown := uuid.New()
+	assert.ErrorIs(t, checkCategoryPlacement(categories, drinks.ID, &unknown), errCategoryNotFound)
+}
+
+func TestNextCategorySortOrder(t *testing.T) {
+	food := category("Food", nil, 0)
+	drinks := category("Drinks", nil, 4)
+	mains := category("Mains", &food, 2)
+	categories := []db.MenuCategory{food, drinks, mains}
+
+	assert.Equal(t, 5, nextCategorySortOrder(categories, nil))
+	assert.Equal(t, 3, nextCategorySortOrder(categories, &food.ID))
+	assert.Equal(t, 0, nextCategorySortOrder(categories, &mains.ID))
+}
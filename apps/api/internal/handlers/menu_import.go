@@ -0,0 +1,659 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Limits on one import
+const (
+	maxMenuImportBytes = 2 << 20
+	maxMenuImportRows  = 1000
+)
+
+// categoryPathSeparator joins nested category names in imports and exports,
+// e.g. "Food > Momo"
+const categoryPathSeparator = " > "
+
+// menuCSVColumns are the CSV columns, in export order. Prices are decimals
+// in the restaurant's currency, such as 250.50, and list values such as
+// dietaryTags are separated by "|". Variants and option groups are only
+// carried by JSON.
+var menuCSVColumns = []string{
+	"sku", "name", "shortDesc", "imageUrl", "price", "type", "mealType",
+	"category", "dietaryTags", "allergens", "spiceLevel",
+}
+
+// MenuImportRow is one menu item of an import or export. Rows with a SKU
+// update the restaurant's item with that SKU, if any.
+type MenuImportRow struct {
+	SKU         string      `json:"sku"`
+	Name        string      `json:"name"`
+	ShortDesc   string      `json:"shortDesc"`
+	ImageURL    string      `json:"imageUrl"`
+	Price       money.Money `json:"price"` // Currency defaults to the restaurant's
+	Type        string      `json:"type"`
+	MealType    string      `json:"mealType"`
+	Category    string      `json:"category"` // Category path, e.g. "Food > Momo"; created if missing
+	DietaryTags []string    `json:"dietaryTags"`
+	Allergens   []string    `json:"allergens"`
+	SpiceLevel  int         `json:"spiceLevel"`
+	// Replace the item's variants or option groups when present
+	Variants     *[]MenuVariantInput     `json:"variants,omitempty"`
+	OptionGroups *[]MenuOptionGroupInput `json:"optionGroups,omitempty"`
+}
+
+type MenuImportRequest struct {
+	Items []MenuImportRow `json:"items"`
+}
+
+// MenuImportError is a validation error of one row. Row numbers are 1-based:
+// the line of a CSV file (the header is line 1) or the index in a JSON list.
+type MenuImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type MenuImportResult struct {
+	DryRun        bool              `json:"dryRun"`
+	Created       int               `json:"created"`
+	Updated       int               `json:"updated"`
+	NewCategories []string          `json:"newCategories"`
+	Errors        []MenuImportError `json:"errors"`
+}
+
+// ImportMenus godoc
+// @Summary Import menu items
+// @Description Create or update menu items in bulk from CSV (text/csv, or a multipart "file" ending in .csv) or JSON ({"items": [...]}). Rows with a SKU update the item with that SKU. Nothing is written unless every row is valid; with dryRun=true nothing is written at all and the result shows what would happen.
+// @Tags menus
+// @Accept json
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param dryRun query bool false "Validate only"
+// @Success 200 {object} MenuImportResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} MenuImportResult
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/import [post]
+func (h *MenuHandler) ImportMenus(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	dryRun := c.Query("dryRun") == "true"
+
+	currency := restaurantCurrency(*restaurant)
+	rows, rowNumbers, parseErrors, err := readMenuImport(c, currency)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if len(parseErrors) > 0 {
+		c.JSON(422, MenuImportResult{DryRun: dryRun, NewCategories: []string{}, Errors: parseErrors})
+		return
+	}
+	if len(rows) > maxMenuImportRows {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("at most %d items can be imported at once", maxMenuImportRows)})
+		return
+	}
+
+	var result MenuImportResult
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		plan, err := planMenuImport(tx, restaurant.ID, currency, rows, rowNumbers)
+		if err != nil {
+			return err
+		}
+		result = plan.result
+		result.DryRun = dryRun
+		if dryRun || len(result.Errors) > 0 {
+			return nil
+		}
+		if err := plan.apply(tx); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuImport,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			After: gin.H{
+				"created":       result.Created,
+				"updated":       result.Updated,
+				"newCategories": result.NewCategories,
+			},
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to import menus"})
+		return
+	}
+
+	if len(result.Errors) > 0 && !dryRun {
+		c.JSON(422, result)
+		return
+	}
+	c.JSON(200, result)
+}
+
+// ExportMenus godoc
+// @Summary Export menu items
+// @Description Export all menu items as CSV or JSON, in the format accepted by the import endpoint. Only JSON includes variants and option groups.
+// @Tags menus
+// @Produce json
+// @Produce text/csv
+// @Param restaurantId path string true "Restaurant ID"
+// @Param format query string false "csv or json" default(json)
+// @Success 200 {object} MenuImportRequest
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/export [get]
+func (h *MenuHandler) ExportMenus(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(400, gin.H{"error": "format must be csv or json"})
+		return
+	}
+
+	categories, err := h.categories(restaurant.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch categories"})
+		return
+	}
+	var menus []db.Menu
+	if err := withMenuOptions(h.DB).Where("restaurant_id = ?", restaurant.ID).Order("sort_order ASC, created_at ASC").Find(&menus).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch menus"})
+		return
+	}
+
+	paths := categoryPaths(categories)
+	rows := make([]MenuImportRow, 0, len(menus))
+	for _, menu := range menus {
+		rows = append(rows, toMenuImportRow(menu, paths))
+	}
+
+	filename := fmt.Sprintf("%s-menu-%s.%s", restaurant.Slug, time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if format == "json" {
+		c.JSON(200, MenuImportRequest{Items: rows})
+		return
+	}
+	c.Status(200)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	if err := writeMenuCSV(c.Writer, rows); err != nil {
+		c.Error(err)
+	}
+}
+
+// menuImportPlan is the outcome of validating an import against the
+// restaurant's current menu
+type menuImportPlan struct {
+	restaurantID uuid.UUID
+	rows         []MenuImportRow
+	existing     []*db.Menu // Item each row updates, nil to create one
+	categories   []db.MenuCategory
+	paths        map[string]uuid.UUID // Lower-cased category path to ID
+	result       MenuImportResult
+}
+
+// planMenuImport validates rows and works out which items are created or
+// updated, and which categories are missing
+func planMenuImport(tx *gorm.DB, restaurantID uuid.UUID, currency string, rows []MenuImportRow, rowNumbers []int) (*menuImportPlan, error) {
+	plan := &menuImportPlan{
+		restaurantID: restaurantID,
+		rows:         rows,
+		existing:     make([]*db.Menu, len(rows)),
+		result:       MenuImportResult{NewCategories: []string{}, Errors: []MenuImportError{}},
+	}
+
+	if err := tx.Where("restaurant_id = ?", restaurantID).Find(&plan.categories).Error; err != nil {
+		return nil, err
+	}
+	plan.paths = make(map[string]uuid.UUID, len(plan.categories))
+	for id, path := range categoryPaths(plan.categories) {
+		plan.paths[strings.ToLower(path)] = id
+	}
+
+	var menus []db.Menu
+	if err := withMenuOptions(tx).Where("restaurant_id = ? AND sku <> ''", restaurantID).Find(&menus).Error; err != nil {
+		return nil, err
+	}
+	bySKU := make(map[string]*db.Menu, len(menus))
+	for i := range menus {
+		bySKU[menus[i].SKU] = &menus[i]
+	}
+
+	seenSKUs := make(map[string]int)
+	newCategories := make(map[string]bool)
+	for i := range rows {
+		row := &rows[i]
+		fail := func(field, message string) {
+			plan.result.Errors = append(plan.result.Errors, MenuImportError{Row: rowNumbers[i], Field: field, Message: message})
+		}
+
+		row.SKU = strings.TrimSpace(row.SKU)
+		row.Name = strings.TrimSpace(row.Name)
+		row.Type = strings.ToUpper(strings.TrimSpace(row.Type))
+		row.MealType = strings.ToUpper(strings.TrimSpace(row.MealType))
+
+		if row.SKU != "" {
+			if first, ok := seenSKUs[row.SKU]; ok {
+				fail("sku", fmt.Sprintf("duplicate of row %d", first))
+				continue
+			}
+			seenSKUs[row.SKU] = rowNumbers[i]
+			plan.existing[i] = bySKU[row.SKU]
+		}
+		if row.Name == "" {
+			fail("name", "name is required")
+		}
+		if row.Price.Amount < 0 {
+			fail("price", "price cannot be negative")
+		}
+		if row.Type != string(db.MenuTypeDrink) && row.Type != string(db.MenuTypeFood) {
+			fail("type", "type must be DRINK or FOOD")
+		}
+		if row.MealType != string(db.MealTypeLunch) && row.MealType != string(db.MealTypeDinner) && row.MealType != string(db.MealTypeBoth) {
+			fail("mealType", "mealType must be LUNCH, DINNER or BOTH")
+		}
+
+		tags, allergens, err := dietaryLabels(row.DietaryTags, row.Allergens, row.SpiceLevel)
+		if err != nil {
+			fail("dietary", err.Error())
+		} else {
+			row.DietaryTags, row.Allergens = tags, allergens
+		}
+
+		variants, groups := []MenuVariantInput(nil), []MenuOptionGroupInput(nil)
+		if existing := plan.existing[i]; existing != nil {
+			variants, groups = menuOptionsFromModel(*existing)
+		}
+		if row.Variants != nil {
+			variants = *row.Variants
+		}
+		if row.OptionGroups != nil {
+			groups = *row.OptionGroups
+		}
+		if err := menuPricesIn(currency, &row.Price, variants, groups); err != nil {
+			fail("price", err.Error())
+		} else if err := validateMenuOptions(row.Price, variants, groups); err != nil {
+			fail("options", err.Error())
+		}
+
+		path, err := normalizeCategoryPath(row.Category)
+		if err != nil {
+			fail("category", err.Error())
+			continue
+		}
+		row.Category = path
+		// Every missing level of the path is created
+		segments := strings.Split(path, categoryPathSeparator)
+		for depth := 1; path != "" && depth <= len(segments); depth++ {
+			prefix := strings.Join(segments[:depth], categoryPathSeparator)
+			if _, ok := plan.paths[strings.ToLower(prefix)]; !ok && !newCategories[strings.ToLower(prefix)] {
+				newCategories[strings.ToLower(prefix)] = true
+				plan.result.NewCategories = append(plan.result.NewCategories, prefix)
+			}
+		}
+	}
+
+	if len(plan.result.Errors) == 0 {
+		for _, existing := range plan.existing {
+			if existing != nil {
+				plan.result.Updated++
+			} else {
+				plan.result.Created++
+			}
+		}
+	}
+	return plan, nil
+}
+
+// apply writes a valid plan
+func (p *menuImportPlan) apply(tx *gorm.DB) error {
+	nextOrder := make(map[uuid.UUID]int) // Per category; uuid.Nil for uncategorized
+	var maxOrders []struct {
+		CategoryID *uuid.UUID
+		MaxOrder   int
+	}
+	if err := tx.Model(&db.Menu{}).Select("category_id, MAX(sort_order) AS max_order").
+		Where("restaurant_id = ?", p.restaurantID).Group("category_id").Scan(&maxOrders).Error; err != nil {
+		return err
+	}
+	for _, m := range maxOrders {
+		key := uuid.Nil
+		if m.CategoryID != nil {
+			key = *m.CategoryID
+		}
+		nextOrder[key] = m.MaxOrder + 1
+	}
+
+	now := time.Now()
+	for i, row := range p.rows {
+		categoryID, err := p.ensureCategory(tx, row.Category)
+		if err != nil {
+			return err
+		}
+
+		menu := p.existing[i]
+		if menu == nil {
+			menu = &db.Menu{ID: uuid.New(), RestaurantID: p.restaurantID, CreatedAt: now}
+		}
+		key := uuid.Nil
+		if categoryID != nil {
+			key = *categoryID
+		}
+		if p.existing[i] == nil || !sameID(menu.CategoryID, categoryID) {
+			menu.SortOrder = nextOrder[key]
+			nextOrder[key]++
+		}
+
+		menu.SKU = row.SKU
+		menu.Name = row.Name
+		menu.ShortDesc = row.ShortDesc
+		menu.ImageURL = row.ImageURL
+		menu.Price = row.Price
+		menu.Type = db.MenuType(row.Type)
+		menu.MealType = db.MealType(row.MealType)
+		menu.CategoryID = categoryID
+		menu.DietaryTags = row.DietaryTags
+		menu.Allergens = row.Allergens
+		menu.SpiceLevel = row.SpiceLevel
+		menu.UpdatedAt = now
+		if row.Variants != nil && len(*row.Variants) > 0 {
+			menu.Price = lowestVariantPrice(*row.Variants)
+		} else if row.Variants == nil && len(menu.Variants) > 0 {
+			variants, _ := menuOptionsFromModel(*menu)
+			menu.Price = lowestVariantPrice(variants)
+		}
+
+		if err := tx.Omit(clause.Associations).Save(menu).Error; err != nil {
+			return err
+		}
+		if err := replaceMenuOptions(tx, menu, row.Variants, row.OptionGroups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureCategory returns the ID of the category at path, creating any
+// missing levels. An empty path means no category.
+func (p *menuImportPlan) ensureCategory(tx *gorm.DB, path string) (*uuid.UUID, error) {
+	if path == "" {
+		return nil, nil
+	}
+	var parentID *uuid.UUID
+	segments := strings.Split(path, categoryPathSeparator)
+	for depth := 1; depth <= len(segments); depth++ {
+		prefix := strings.ToLower(strings.Join(segments[:depth], categoryPathSeparator))
+		if id, ok := p.paths[prefix]; ok {
+			id := id
+			parentID = &id
+			continue
+		}
+		category := db.MenuCategory{
+			ID:           uuid.New(),
+			RestaurantID: p.restaurantID,
+			ParentID:     parentID,
+			Name:         segments[depth-1],
+			SortOrder:    nextCategorySortOrder(p.categories, parentID),
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return nil, err
+		}
+		p.categories = append(p.categories, category)
+		p.paths[prefix] = category.ID
+		parentID = &category.ID
+	}
+	return parentID, nil
+}
+
+// skuTaken reports whether another of the restaurant's items, other than
+// exceptID, has the SKU
+func (h *MenuHandler) skuTaken(restaurantID uuid.UUID, sku string, exceptID uuid.UUID) (bool, error) {
+	if sku == "" {
+		return false, nil
+	}
+	var count int64
+	err := h.DB.Model(&db.Menu{}).Where("restaurant_id = ? AND sku = ? AND id <> ?", restaurantID, sku, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// readMenuImport reads the rows of a CSV or JSON import from the request,
+// with the row number of each. CSV prices are read in currency.
+func readMenuImport(c *gin.Context, currency string) ([]MenuImportRow, []int, []MenuImportError, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMenuImportBytes)
+
+	var body io.Reader = c.Request.Body
+	format := "json"
+	contentType := c.ContentType()
+	switch {
+	case contentType == "multipart/form-data":
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("file is required")
+		}
+		defer file.Close()
+		body = file
+		switch strings.ToLower(filepath.Ext(header.Filename)) {
+		case ".csv":
+			format = "csv"
+		case ".json":
+		default:
+			return nil, nil, nil, fmt.Errorf("file must be .csv or .json")
+		}
+	case contentType == "text/csv":
+		format = "csv"
+	case contentType == "application/json":
+	default:
+		return nil, nil, nil, fmt.Errorf("content type must be text/csv, application/json or multipart/form-data")
+	}
+
+	if format == "csv" {
+		rows, numbers, errs := parseMenuCSV(body, currency)
+		return rows, numbers, errs, nil
+	}
+
+	var req MenuImportRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	numbers := make([]int, len(req.Items))
+	for i := range numbers {
+		numbers[i] = i + 1
+	}
+	return req.Items, numbers, nil, nil
+}
+
+// parseMenuCSV reads rows from CSV with a header line naming the columns
+// (see menuCSVColumns, in any order). Parse errors are reported by line.
+func parseMenuCSV(r io.Reader, currency string) ([]MenuImportRow, []int, []MenuImportError) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, []MenuImportError{{Row: 1, Message: "missing header line"}}
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		known := false
+		for _, column := range menuCSVColumns {
+			if strings.EqualFold(name, column) {
+				columns[column] = i
+				known = true
+			}
+		}
+		if !known {
+			return nil, nil, []MenuImportError{{Row: 1, Field: name, Message: "unknown column"}}
+		}
+	}
+	for _, required := range []string{"name", "price", "type", "mealType"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, []MenuImportError{{Row: 1, Field: required, Message: "missing column"}}
+		}
+	}
+
+	var rows []MenuImportRow
+	var numbers []int
+	var errs []MenuImportError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			errs = append(errs, MenuImportError{Row: parseErr.StartLine, Message: parseErr.Err.Error()})
+			continue
+		} else if err != nil {
+			errs = append(errs, MenuImportError{Message: err.Error()})
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := MenuImportRow{
+			SKU:         get("sku"),
+			Name:        get("name"),
+			ShortDesc:   get("shortDesc"),
+			ImageURL:    get("imageUrl"),
+			Type:        get("type"),
+			MealType:    get("mealType"),
+			Category:    get("category"),
+			DietaryTags: splitCSVList(get("dietaryTags")),
+			Allergens:   splitCSVList(get("allergens")),
+		}
+		if row.Price, err = money.Parse(get("price"), currency); err != nil {
+			errs = append(errs, MenuImportError{Row: line, Field: "price", Message: "price must be a decimal amount"})
+			continue
+		}
+		if spice := get("spiceLevel"); spice != "" {
+			if row.SpiceLevel, err = strconv.Atoi(spice); err != nil {
+				errs = append(errs, MenuImportError{Row: line, Field: "spiceLevel", Message: "spiceLevel must be a number"})
+				continue
+			}
+		}
+		rows = append(rows, row)
+		numbers = append(numbers, line)
+	}
+	return rows, numbers, errs
+}
+
+// writeMenuCSV writes rows with a header line
+func writeMenuCSV(w io.Writer, rows []MenuImportRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(menuCSVColumns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.Write([]string{
+			row.SKU, row.Name, row.ShortDesc, row.ImageURL, row.Price.Decimal(), row.Type, row.MealType,
+			row.Category, strings.Join(row.DietaryTags, "|"), strings.Join(row.Allergens, "|"), strconv.Itoa(row.SpiceLevel),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func splitCSVList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, "|") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// normalizeCategoryPath trims the names of a category path and checks its
+// depth
+func normalizeCategoryPath(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", nil
+	}
+	segments := strings.Split(path, strings.TrimSpace(categoryPathSeparator))
+	for i, segment := range segments {
+		segments[i] = strings.TrimSpace(segment)
+		if segments[i] == "" {
+			return "", fmt.Errorf("category path %q has an empty name", path)
+		}
+	}
+	if len(segments) > maxCategoryDepth {
+		return "", errCategoryDepth
+	}
+	return strings.Join(segments, categoryPathSeparator), nil
+}
+
+// categoryPaths returns the full path of each category, e.g. "Food > Momo"
+func categoryPaths(categories []db.MenuCategory) map[uuid.UUID]string {
+	byID := make(map[uuid.UUID]db.MenuCategory, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	paths := make(map[uuid.UUID]string, len(categories))
+	for _, category := range categories {
+		names := []string{category.Name}
+		for parent := category.ParentID; parent != nil && len(names) <= len(categories); {
+			p, ok := byID[*parent]
+			if !ok {
+				break
+			}
+			names = append([]string{p.Name}, names...)
+			parent = p.ParentID
+		}
+		paths[category.ID] = strings.Join(names, categoryPathSeparator)
+	}
+	return paths
+}
+
+func toMenuImportRow(menu db.Menu, paths map[uuid.UUID]string) MenuImportRow {
+	variants, groups := menuOptionsFromModel(menu)
+	row := MenuImportRow{
+		SKU:          menu.SKU,
+		Name:         menu.Name,
+		ShortDesc:    menu.ShortDesc,
+		ImageURL:     menu.ImageURL,
+		Price:        menu.Price,
+		Type:         string(menu.Type),
+		MealType:     string(menu.MealType),
+		DietaryTags:  stringsOrEmpty(menu.DietaryTags),
+		Allergens:    stringsOrEmpty(menu.Allergens),
+		SpiceLevel:   menu.SpiceLevel,
+		Variants:     &variants,
+		OptionGroups: &groups,
+	}
+	if menu.CategoryID != nil {
+		row.Category = paths[*menu.CategoryID]
+	}
+	return row
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMenuCSV(t *testing.T) {
+	input := "Name,Price,Type,MealType,SKU,Category,DietaryTags\n" +
+		"Veg Momo,250,FOOD,BOTH,M-1,Food > Momo,VEGETARIAN|JAIN\n" +
+		"Lassi,abc,DRINK,BOTH,D-1,,\n" +
+		"\"Chai, masala\",80.50,DRINK,BOTH,,Drinks,\n"
+
+	rows, lines, errs := parseMenuCSV(strings.NewReader(input), "NPR")
+	require.Len(t, rows, 2)
+	assert.Equal(t, []int{2, 4}, lines)
+	assert.Equal(t, MenuImportRow{
+		SKU: "M-1", Name: "Veg Momo", Price: npr(250), Type: "FOOD", MealType: "BOTH",
+		Category: "Food > Momo", DietaryTags: []string{"VEGETARIAN", "JAIN"},
+	}, rows[0])
+	assert.Equal(t, "Chai, masala", rows[1].Name)
+	assert.Equal(t, money.New(8050, "NPR"), rows[1].Price)
+	assert.Equal(t, []MenuImportError{{Row: 3, Field: "price", Message: "price must be a decimal amount"}}, errs)
+}
+
+func TestParseMenuCSV_Header(t *testing.T) {
+	_, _, errs := parseMenuCSV(strings.NewReader("name,price,type,mealType,colour\n"), "NPR")
+	assert.Equal(t, []MenuImportError{{Row: 1, Field: "colour", Message: "unknown column"}}, errs)
+
+	_, _, errs = parseMenuCSV(strings.NewReader("name,type,mealType\n"), "NPR")
+	assert.Equal(t, []MenuImportError{{Row: 1, Field: "price", Message: "missing column"}}, errs)
+
+	_, _, errs = parseMenuCSV(strings.NewReader(""), "NPR")
+	assert.Len(t, errs, 1)
+}
+
+func TestMenuCSVRoundTrip(t *testing.T) {
+	rows := []MenuImportRow{{
+		SKU: "M-1", Name: "Chilli \"C\" Momo", ShortDesc: "Spicy, fried", Price: money.New(32050, "NPR"), Type: "FOOD", MealType: "DINNER",
+		Category: "Food > Momo", DietaryTags: []string{"VEGAN", "VEGETARIAN"}, Allergens: []string{"GLUTEN"}, SpiceLevel: 3,
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeMenuCSV(&buf, rows))
+	parsed, _, errs := parseMenuCSV(&buf, "NPR")
+	assert.Empty(t, errs)
+	assert.Equal(t, rows, parsed)
+}
+
+func TestNormalizeCategoryPath(t *testing.T) {
+	path, err := normalizeCategoryPath("  Food>Momo  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Food > Momo", path)
+
+	path, err = normalizeCategoryPath("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", path)
+
+	_, err = normalizeCategoryPath("Food > > Momo")
+	assert.Error(t, err)
+	_, err = normalizeCategoryPath("A > B > C > D")
+	assert.ErrorIs(t, err, errCategoryDepth)
+}
+
+func TestCategoryPaths(t *testing.T) {
+	food := category("Food", nil, 0)
+	momo := category("Momo", &food, 0)
+	fried := category("Fried", &momo, 0)
+
+	paths := categoryPaths([]db.MenuCategory{fried, momo, food})
+	assert.Equal(t, "Food", paths[food.ID])
+	assert.Equal(t, "Food > Momo", paths[momo.ID])
+	assert.Equal(t, "Food > Momo > Fried", paths[fried.ID])
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Limits on the size of an item's variants and options
+const (
+	maxMenuVariants     = 20
+	maxMenuOptionGroups = 20
+	maxMenuGroupOptions = 50
+)
+
+// Request/Response DTOs
+type MenuVariantInput struct {
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"`
+	IsDefault bool        `json:"isDefault"`
+}
+
+type MenuOptionInput struct {
+	Name       string      `json:"name"`
+	PriceDelta money.Money `json:"priceDelta"`
+	IsDefault  bool        `json:"isDefault"`
+}
+
+type MenuOptionGroupInput struct {
+	Name      string            `json:"name"`
+	MinSelect int               `json:"minSelect"`
+	MaxSelect int               `json:"maxSelect"`
+	Options   []MenuOptionInput `json:"options"`
+}
+
+type MenuVariantResponse struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"`
+	IsDefault bool        `json:"isDefault"`
+}
+
+type MenuOptionResponse struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	PriceDelta money.Money `json:"priceDelta"`
+	IsDefault  bool        `json:"isDefault"`
+}
+
+type MenuOptionGroupResponse struct {
+	ID        string               `json:"id"`
+	Name      string               `json:"name"`
+	MinSelect int                  `json:"minSelect"`
+	MaxSelect int                  `json:"maxSelect"`
+	Options   []MenuOptionResponse `json:"options"`
+}
+
+// validateMenuOptions checks an item's variants and option groups: names
+// are present and unique, at most one variant is the default, every group's
+// selection rule can be met, and no choice can bring the price below zero.
+// basePrice is the item price used when there are no variants. All prices
+// are in basePrice's currency (see menuPricesIn).
+func validateMenuOptions(basePrice money.Money, variants []MenuVariantInput, groups []MenuOptionGroupInput) error {
+	if len(variants) > maxMenuVariants {
+		return fmt.Errorf("an item can have at most %d variants", maxMenuVariants)
+	}
+	if len(groups) > maxMenuOptionGroups {
+		return fmt.Errorf("an item can have at most %d option groups", maxMenuOptionGroups)
+	}
+
+	lowest := basePrice.Amount
+	names := make(map[string]bool, len(variants))
+	defaults := 0
+	for i, v := range variants {
+		name := strings.ToLower(strings.TrimSpace(v.Name))
+		if name == "" {
+			return fmt.Errorf("variant %d: name is required", i+1)
+		}
+		if names[name] {
+			return fmt.Errorf("variant %q is listed twice", v.Name)
+		}
+		names[name] = true
+		if v.Price.Amount < 0 {
+			return fmt.Errorf("variant %q: price cannot be negative", v.Name)
+		}
+		if v.IsDefault {
+			defaults++
+		}
+		if i == 0 || v.Price.Amount < lowest {
+			lowest = v.Price.Amount
+		}
+	}
+	if defaults > 1 {
+		return fmt.Errorf("only one variant can be the default")
+	}
+
+	names = make(map[string]bool, len(groups))
+	for i, g := range groups {
+		name := strings.ToLower(strings.TrimSpace(g.Name))
+		if name == "" {
+			return fmt.Errorf("option group %d: name is required", i+1)
+		}
+		if names[name] {
+			return fmt.Errorf("option group %q is listed twice", g.Name)
+		}
+		names[name] = true
+
+		if len(g.Options) == 0 {
+			return fmt.Errorf("option group %q has no options", g.Name)
+		}
+		if len(g.Options) > maxMenuGroupOptions {
+			return fmt.Errorf("option group %q can have at most %d options", g.Name, maxMenuGroupOptions)
+		}
+		if g.MinSelect < 0 || g.MaxSelect < 1 || g.MinSelect > g.MaxSelect {
+			return fmt.Errorf("option group %q: need 0 <= minSelect <= maxSelect and maxSelect >= 1", g.Name)
+		}
+		if g.MaxSelect > len(g.Options) {
+			return fmt.Errorf("option group %q: maxSelect is larger than the number of options", g.Name)
+		}
+
+		options := make(map[string]bool, len(g.Options))
+		defaults := 0
+		for j, o := range g.Options {
+			optionName := strings.ToLower(strings.TrimSpace(o.Name))
+			if optionName == "" {
+				return fmt.Errorf("option group %q: option %d: name is required", g.Name, j+1)
+			}
+			if options[optionName] {
+				return fmt.Errorf("option group %q: option %q is listed twice", g.Name, o.Name)
+			}
+			options[optionName] = true
+			if lowest+o.PriceDelta.Amount < 0 {
+				return fmt.Errorf("option group %q: option %q would make the price negative", g.Name, o.Name)
+			}
+			if o.IsDefault {
+				defaults++
+			}
+		}
+		if defaults > g.MaxSelect {
+			return fmt.Errorf("option group %q has more defaults than maxSelect", g.Name)
+		}
+	}
+	return nil
+}
+
+// lowestVariantPrice returns the cheapest variant price, which is what
+// listings show as the item price
+func lowestVariantPrice(variants []MenuVariantInput) money.Money {
+	lowest := variants[0].Price
+	for _, v := range variants[1:] {
+		if v.Price.Amount < lowest.Amount {
+			lowest = v.Price
+		}
+	}
+	return lowest
+}
+
+// menuPricesIn checks that an item's price, variant prices and option
+// price deltas are in currency, filling it in where it was left out
+func menuPricesIn(currency string, price *money.Money, variants []MenuVariantInput, groups []MenuOptionGroupInput) error {
+	if err := inCurrency(price, currency); err != nil {
+		return err
+	}
+	for i := range variants {
+		if err := inCurrency(&variants[i].Price, currency); err != nil {
+			return fmt.Errorf("variant %q: %v", variants[i].Name, err)
+		}
+	}
+	for _, g := range groups {
+		for j := range g.Options {
+			if err := inCurrency(&g.Options[j].PriceDelta, currency); err != nil {
+				return fmt.Errorf("option group %q: option %q: %v", g.Name, g.Options[j].Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// replaceMenuOptions swaps the item's variants and option groups for the
+// given ones, in order. A nil slice leaves that part unchanged.
+func replaceMenuOptions(tx *gorm.DB, menu *db.Menu, variants *[]MenuVariantInput, groups *[]MenuOptionGroupInput) error {
+	if variants != nil {
+		if err := tx.Where("menu_id = ?", menu.ID).Delete(&db.MenuVariant{}).Error; err != nil {
+			return err
+		}
+		menu.Variants = make([]db.MenuVariant, 0, len(*variants))
+		for i, v := range *variants {
+			menu.Variants = append(menu.Variants, db.MenuVariant{
+				ID:        uuid.New(),
+				MenuID:    menu.ID,
+				Name:      strings.TrimSpace(v.Name),
+				Price:     v.Price,
+				IsDefault: v.IsDefault,
+				SortOrder: i,
+			})
+		}
+		if len(menu.Variants) > 0 {
+			if err := tx.Create(&menu.Variants).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if groups != nil {
+		// Options go with their groups through the foreign key
+		if err := tx.Where("menu_id = ?", menu.ID).Delete(&db.MenuOptionGroup{}).Error; err != nil {
+			return err
+		}
+		menu.OptionGroups = make([]db.MenuOptionGroup, 0, len(*groups))
+		for i, g := range *groups {
+			group := db.MenuOptionGroup{
+				ID:        uuid.New(),
+				MenuID:    menu.ID,
+				Name:      strings.TrimSpace(g.Name),
+				MinSelect: g.MinSelect,
+				MaxSelect: g.MaxSelect,
+				SortOrder: i,
+				Options:   make([]db.MenuOption, 0, len(g.Options)),
+			}
+			for j, o := range g.Options {
+				group.Options = append(group.Options, db.MenuOption{
+					ID:         uuid.New(),
+					GroupID:    group.ID,
+					Name:       strings.TrimSpace(o.Name),
+					PriceDelta: o.PriceDelta,
+					IsDefault:  o.IsDefault,
+					SortOrder:  j,
+				})
+			}
+			menu.OptionGroups = append(menu.OptionGroups, group)
+		}
+		if len(menu.OptionGroups) > 0 {
+			// Creates the groups' options as well
+			if err := tx.Create(&menu.OptionGroups).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// withMenuOptions preloads variants and option groups, in display order
+func withMenuOptions(q *gorm.DB) *gorm.DB {
+	return q.
+		Preload("Variants", func(tx *gorm.DB) *gorm.DB { return tx.Order("sort_order ASC") }).
+		Preload("OptionGroups", func(tx *gorm.DB) *gorm.DB { return tx.Order("sort_order ASC") }).
+		Preload("OptionGroups.Options", func(tx *gorm.DB) *gorm.DB { return tx.Order("sort_order ASC") })
+}
+
+// menuOptionsFromModel converts stored variants and option groups back to
+// inputs, so that a partial update can be validated as a whole
+func menuOptionsFromModel(menu db.Menu) ([]MenuVariantInput, []MenuOptionGroupInput) {
+	variants := make([]MenuVariantInput, 0, len(menu.Variants))
+	for _, v := range menu.Variants {
+		variants = append(variants, MenuVariantInput{Name: v.Name, Price: v.Price, IsDefault: v.IsDefault})
+	}
+	groups := make([]MenuOptionGroupInput, 0, len(menu.OptionGroups))
+	for _, g := range menu.OptionGroups {
+		group := MenuOptionGroupInput{Name: g.Name, MinSelect: g.MinSelect, MaxSelect: g.MaxSelect}
+		for _, o := range g.Options {
+			group.Options = append(group.Options, MenuOptionInput{Name: o.Name, PriceDelta: o.PriceDelta, IsDefault: o.IsDefault})
+		}
+		groups = append(groups, group)
+	}
+	return variants, groups
+}
+
+func toMenuVariantResponses(variants []db.MenuVariant) []MenuVariantResponse {
+	response := make([]MenuVariantResponse, 0, len(variants))
+	for _, v := range variants {
+		response = append(response, MenuVariantResponse{
+			ID:        v.ID.String(),
+			Name:      v.Name,
+			Price:     v.Price,
+			IsDefault: v.IsDefault,
+		})
+	}
+	return response
+}
+
+func toMenuOptionGroupResponses(groups []db.MenuOptionGroup) []MenuOptionGroupResponse {
+	response := make([]MenuOptionGroupResponse, 0, len(groups))
+	for _, g := range groups {
+		group := MenuOptionGroupResponse{
+			ID:        g.ID.String(),
+			Name:      g.Name,
+			MinSelect: g.MinSelect,
+			MaxSelect: g.MaxSelect,
+			Options:   make([]MenuOptionResponse, 0, len(g.Options)),
+		}
+		for _, o := range g.Options {
+			group.Options = append(group.Options, MenuOptionResponse{
+				ID:         o.ID.String(),
+				Name:       o.Name,
+				PriceDelta: o.PriceDelta,
+				IsDefault:  o.IsDefault,
+			})
+		}
+		response = append(response, group)
+	}
+	return response
+}
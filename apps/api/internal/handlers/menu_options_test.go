@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func npr(rupees int64) money.Money {
+	return money.FromMajor(rupees, "NPR")
+}
+
+func momoOptions() ([]MenuVariantInput, []MenuOptionGroupInput) {
+	variants := []MenuVariantInput{
+		{Name: "10 pcs", Price: npr(300), IsDefault: true},
+		{Name: "20 pcs", Price: npr(550)},
+	}
+	groups := []MenuOptionGroupInput{
+		{Name: "Style", MinSelect: 1, MaxSelect: 1, Options: []MenuOptionInput{
+			{Name: "Steamed", IsDefault: true},
+			{Name: "Fried", PriceDelta: npr(50)},
+			{Name: "Jhol", PriceDelta: npr(80)},
+		}},
+		{Name: "Extras", MinSelect: 0, MaxSelect: 2, Options: []MenuOptionInput{
+			{Name: "Extra achar", PriceDelta: npr(30)},
+			{Name: "Extra soup", PriceDelta: npr(40)},
+		}},
+	}
+	return variants, groups
+}
+
+func TestValidateMenuOptions_Valid(t *testing.T) {
+	variants, groups := momoOptions()
+	assert.NoError(t, validateMenuOptions(npr(300), variants, groups))
+	assert.NoError(t, validateMenuOptions(npr(300), nil, nil))
+	assert.NoError(t, validateMenuOptions(npr(300), nil, groups))
+	assert.Equal(t, npr(300), lowestVariantPrice(variants))
+}
+
+func TestValidateMenuOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput)
+	}{
+		{"empty variant name", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*v)[0].Name = " " }},
+		{"duplicate variant", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*v)[1].Name = "10 PCS" }},
+		{"negative variant price", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*v)[1].Price = npr(-1) }},
+		{"two default variants", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*v)[1].IsDefault = true }},
+		{"duplicate group", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*g)[1].Name = "style" }},
+		{"group without options", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*g)[0].Options = nil }},
+		{"min above max", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*g)[1].MinSelect = 3 }},
+		{"zero max", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*g)[1].MaxSelect = 0 }},
+		{"max above option count", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*g)[0].MaxSelect = 4 }},
+		{"duplicate option", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*g)[0].Options[1].Name = "steamed" }},
+		{"too many defaults", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*g)[0].Options[1].IsDefault = true }},
+		{"negative price", func(v *[]MenuVariantInput, g *[]MenuOptionGroupInput) { (*g)[1].Options[0].PriceDelta = npr(-301) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants, groups := momoOptions()
+			tt.modify(&variants, &groups)
+			assert.Error(t, validateMenuOptions(npr(300), variants, groups))
+		})
+	}
+}
+
+func TestValidateMenuOptions_BasePrice(t *testing.T) {
+	_, groups := momoOptions()
+	groups[1].Options[0].PriceDelta = npr(-100)
+
+	// Without variants the item price is the base
+	assert.NoError(t, validateMenuOptions(npr(100), nil, groups))
+	assert.Error(t, validateMenuOptions(npr(99), nil, groups))
+
+	// With variants the cheapest variant is the base
+	assert.Error(t, validateMenuOptions(npr(500), []MenuVariantInput{{Name: "Half", Price: npr(80)}}, groups))
+}
+
+func TestMenuPricesIn(t *testing.T) {
+	price := money.Money{Amount: 30000}
+	variants, groups := momoOptions()
+	variants[0].Price.Currency = ""
+	assert.NoError(t, menuPricesIn("NPR", &price, variants, groups))
+	assert.Equal(t, npr(300), price, "missing currency is filled in")
+	assert.Equal(t, npr(300), variants[0].Price)
+
+	groups[0].Options[1].PriceDelta = money.FromMajor(50, "USD")
+	assert.Error(t, menuPricesIn("NPR", &price, variants, groups))
+	assert.Error(t, menuPricesIn("JPY", &price, nil, nil))
+}
@@ -0,0 +1,1256 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MenuSnapshot is the content of a menu version: the restaurant's
+// categories and items as they are, or will be, live. Availability rules
+// and sold-out flags are operational and not versioned.
+type MenuSnapshot struct {
+	Categories []MenuSnapshotCategory `json:"categories"`
+	Items      []MenuSnapshotItem     `json:"items"`
+}
+
+// MenuSnapshotCategory is a category of a snapshot. New categories may
+// use any UUID so that items can refer to them; IDs that are not live
+// categories are replaced when the version is published.
+type MenuSnapshotCategory struct {
+	ID          string  `json:"id"`
+	ParentID    *string `json:"parentId"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	SortOrder   int     `json:"sortOrder"`
+}
+
+// MenuSnapshotItem is a menu item of a snapshot. Items keep their ID
+// across versions; new items may leave it empty.
+type MenuSnapshotItem struct {
+	ID           string                 `json:"id"`
+	CategoryID   *string                `json:"categoryId"`
+	SKU          string                 `json:"sku"`
+	Name         string                 `json:"name"`
+	ShortDesc    string                 `json:"shortDesc"`
+	ImageURL     string                 `json:"imageUrl"`
+	Price        money.Money            `json:"price"`
+	Type         string                 `json:"type"`
+	MealType     string                 `json:"mealType"`
+	SortOrder    int                    `json:"sortOrder"`
+	DietaryTags  []string               `json:"dietaryTags"`
+	Allergens    []string               `json:"allergens"`
+	SpiceLevel   int                    `json:"spiceLevel"`
+	Variants     []MenuVariantInput     `json:"variants"`
+	OptionGroups []MenuOptionGroupInput `json:"optionGroups"`
+}
+
+type CreateMenuVersionRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Version to start from; the live menu when empty
+	FromVersionID string `json:"fromVersionId"`
+}
+
+type UpdateMenuVersionRequest struct {
+	Name     *string       `json:"name,omitempty"`
+	Snapshot *MenuSnapshot `json:"snapshot,omitempty"`
+}
+
+type PublishMenuVersionRequest struct {
+	// Publish later, by the background job; now when empty or past
+	PublishAt *time.Time `json:"publishAt"`
+}
+
+type MenuVersionResponse struct {
+	ID           string        `json:"id"`
+	RestaurantID string        `json:"restaurantId"`
+	Number       int           `json:"number"`
+	Name         string        `json:"name"`
+	Status       string        `json:"status"`
+	PublishAt    *time.Time    `json:"publishAt"`
+	PublishedAt  *time.Time    `json:"publishedAt"`
+	CreatedBy    *string       `json:"createdBy"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+	Snapshot     *MenuSnapshot `json:"snapshot,omitempty"` // Single versions only
+}
+
+// MenuDiff lists what changes from one menu snapshot to another
+type MenuDiff struct {
+	From       string          `json:"from"` // Version ID, or "live"
+	To         string          `json:"to"`
+	Categories []MenuDiffEntry `json:"categories"`
+	Items      []MenuDiffEntry `json:"items"`
+}
+
+type MenuDiffEntry struct {
+	ID     string                     `json:"id"`
+	Name   string                     `json:"name"`
+	Change string                     `json:"change"`           // added, removed or changed
+	Fields map[string]MenuFieldChange `json:"fields,omitempty"` // For changed entries
+}
+
+type MenuFieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+var (
+	errMenuVersionNotDraft       = errors.New("only draft versions can be changed; unschedule it first")
+	errMenuVersionNotFound       = errors.New("menu version not found")
+	errMenuVersionNotPublishable = errors.New("only draft and scheduled versions can be published; roll back to archived ones")
+)
+
+// ListMenuVersions godoc
+// @Summary List menu versions
+// @Description List a restaurant's menu versions, newest first, without their snapshots
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param status query string false "DRAFT, SCHEDULED, PUBLISHED or ARCHIVED"
+// @Success 200 {array} MenuVersionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions [get]
+func (h *MenuHandler) ListMenuVersions(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	q := h.DB.Omit("snapshot").Where("restaurant_id = ?", restaurant.ID)
+	if status := c.Query("status"); status != "" {
+		q = q.Where("status = ?", strings.ToUpper(status))
+	}
+	var versions []db.MenuVersion
+	if err := q.Order("number DESC").Find(&versions).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch menu versions"})
+		return
+	}
+
+	response := make([]MenuVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		response = append(response, toMenuVersionResponse(version, nil))
+	}
+	c.JSON(200, response)
+}
+
+// CreateMenuVersion godoc
+// @Summary Create a draft menu version
+// @Description Create a draft from the live menu, or from another version, to edit without changing the live menu
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param version body CreateMenuVersionRequest true "Draft"
+// @Success 201 {object} MenuVersionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions [post]
+func (h *MenuHandler) CreateMenuVersion(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	var req CreateMenuVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var version db.MenuVersion
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var snapshot db.JSON
+		if req.FromVersionID != "" {
+			from, err := menuVersionByID(tx, restaurant.ID, req.FromVersionID)
+			if err != nil {
+				return err
+			}
+			snapshot = from.Snapshot
+		} else {
+			live, err := liveMenuSnapshot(tx, restaurant.ID)
+			if err != nil {
+				return err
+			}
+			if snapshot, err = json.Marshal(live); err != nil {
+				return err
+			}
+		}
+
+		number, err := nextMenuVersionNumber(tx, restaurant.ID)
+		if err != nil {
+			return err
+		}
+		version = db.MenuVersion{
+			ID:           uuid.New(),
+			RestaurantID: restaurant.ID,
+			Number:       number,
+			Name:         strings.TrimSpace(req.Name),
+			Status:       db.MenuVersionDraft,
+			Snapshot:     snapshot,
+			CreatedBy:    actorID(c),
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuVersionCreate,
+			EntityType: "menu_version",
+			EntityID:   version.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			After:      toMenuVersionResponse(version, nil),
+		})
+	})
+	if err == errMenuVersionNotFound {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create menu version"})
+		return
+	}
+
+	c.JSON(201, h.menuVersionWithSnapshot(c, version))
+}
+
+// GetMenuVersion godoc
+// @Summary Get a menu version
+// @Description Get a menu version with its snapshot
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param versionId path string true "Version ID"
+// @Success 200 {object} MenuVersionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions/{versionId} [get]
+func (h *MenuHandler) GetMenuVersion(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	version, ok := h.menuVersionParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	c.JSON(200, h.menuVersionWithSnapshot(c, *version))
+}
+
+// UpdateMenuVersion godoc
+// @Summary Update a draft menu version
+// @Description Rename a draft or replace its snapshot. Snapshots are validated like menu items and categories.
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param versionId path string true "Version ID"
+// @Param version body UpdateMenuVersionRequest true "Changes"
+// @Success 200 {object} MenuVersionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions/{versionId} [put]
+func (h *MenuHandler) UpdateMenuVersion(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	version, ok := h.menuVersionParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	if version.Status != db.MenuVersionDraft {
+		c.JSON(409, gin.H{"error": errMenuVersionNotDraft.Error()})
+		return
+	}
+
+	var req UpdateMenuVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := toMenuVersionResponse(*version, nil)
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			c.JSON(400, gin.H{"error": "name is required"})
+			return
+		}
+		version.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Snapshot != nil {
+		if err := validateMenuSnapshot(req.Snapshot, restaurantCurrency(*restaurant)); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		snapshot, err := json.Marshal(req.Snapshot)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "failed to update menu version"})
+			return
+		}
+		version.Snapshot = snapshot
+	}
+	version.UpdatedAt = time.Now()
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(version).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuVersionUpdate,
+			EntityType: "menu_version",
+			EntityID:   version.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      toMenuVersionResponse(*version, nil),
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update menu version"})
+		return
+	}
+
+	c.JSON(200, h.menuVersionWithSnapshot(c, *version))
+}
+
+// DeleteMenuVersion godoc
+// @Summary Delete a draft menu version
+// @Description Delete a draft or scheduled version. Published and archived versions are kept as history.
+// @Tags menus
+// @Param restaurantId path string true "Restaurant ID"
+// @Param versionId path string true "Version ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions/{versionId} [delete]
+func (h *MenuHandler) DeleteMenuVersion(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	version, ok := h.menuVersionParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	if version.Status != db.MenuVersionDraft && version.Status != db.MenuVersionScheduled {
+		c.JSON(409, gin.H{"error": "published versions cannot be deleted"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(version).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuVersionDelete,
+			EntityType: "menu_version",
+			EntityID:   version.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     toMenuVersionResponse(*version, nil),
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to delete menu version"})
+		return
+	}
+
+	c.Status(204)
+}
+
+// PublishMenuVersion godoc
+// @Summary Publish a menu version
+// @Description Replace the live menu with the version's snapshot now, or schedule it for publishAt. The live menu is kept as an archived version first when it has changes of its own, so it can be rolled back to.
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param versionId path string true "Version ID"
+// @Param publish body PublishMenuVersionRequest false "Schedule"
+// @Success 200 {object} MenuVersionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions/{versionId}/publish [post]
+func (h *MenuHandler) PublishMenuVersion(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	version, ok := h.menuVersionParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	if version.Status != db.MenuVersionDraft && version.Status != db.MenuVersionScheduled {
+		c.JSON(409, gin.H{"error": errMenuVersionNotPublishable.Error()})
+		return
+	}
+
+	var req PublishMenuVersionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	now := time.Now()
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// The background job may be publishing it right now
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status IN ?", version.ID, []db.MenuVersionStatus{db.MenuVersionDraft, db.MenuVersionScheduled}).
+			First(version).Error
+		if err == gorm.ErrRecordNotFound {
+			return errMenuVersionNotPublishable
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.PublishAt != nil && req.PublishAt.After(now) {
+			before := toMenuVersionResponse(*version, nil)
+			version.Status = db.MenuVersionScheduled
+			version.PublishAt = req.PublishAt
+			version.UpdatedAt = now
+			if err := tx.Save(version).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, c, audit.Entry{
+				Action:     audit.ActionMenuVersionSchedule,
+				EntityType: "menu_version",
+				EntityID:   version.ID.String(),
+				OrgID:      &restaurant.OrgID,
+				Before:     before,
+				After:      toMenuVersionResponse(*version, nil),
+			})
+		}
+		return publishMenuVersion(tx, c, restaurant, version, now)
+	})
+	if err == errMenuVersionNotPublishable {
+		c.JSON(409, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to publish menu version"})
+		return
+	}
+
+	c.JSON(200, h.menuVersionWithSnapshot(c, *version))
+}
+
+// UnscheduleMenuVersion godoc
+// @Summary Unschedule a menu version
+// @Description Turn a scheduled version back into a draft
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param versionId path string true "Version ID"
+// @Success 200 {object} MenuVersionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions/{versionId}/schedule [delete]
+func (h *MenuHandler) UnscheduleMenuVersion(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	version, ok := h.menuVersionParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	if version.Status != db.MenuVersionScheduled {
+		c.JSON(409, gin.H{"error": "version is not scheduled"})
+		return
+	}
+
+	before := toMenuVersionResponse(*version, nil)
+	version.Status = db.MenuVersionDraft
+	version.PublishAt = nil
+	version.UpdatedAt = time.Now()
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// The background job may be publishing it right now
+		result := tx.Model(&db.MenuVersion{}).
+			Where("id = ? AND status = ?", version.ID, db.MenuVersionScheduled).
+			Updates(map[string]interface{}{"status": version.Status, "publish_at": nil, "updated_at": version.UpdatedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errMenuVersionNotDraft
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuVersionSchedule,
+			EntityType: "menu_version",
+			EntityID:   version.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      toMenuVersionResponse(*version, nil),
+		})
+	})
+	if err == errMenuVersionNotDraft {
+		c.JSON(409, gin.H{"error": "version is not scheduled"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to unschedule menu version"})
+		return
+	}
+
+	c.JSON(200, toMenuVersionResponse(*version, nil))
+}
+
+// RollbackMenuVersion godoc
+// @Summary Roll back to a menu version
+// @Description Publish the snapshot of a previously published version again, as a new version
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param versionId path string true "Version ID"
+// @Success 200 {object} MenuVersionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions/{versionId}/rollback [post]
+func (h *MenuHandler) RollbackMenuVersion(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	target, ok := h.menuVersionParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	if target.Status != db.MenuVersionArchived {
+		c.JSON(409, gin.H{"error": "only previously published versions can be rolled back to"})
+		return
+	}
+
+	var version db.MenuVersion
+	now := time.Now()
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		number, err := nextMenuVersionNumber(tx, restaurant.ID)
+		if err != nil {
+			return err
+		}
+		version = db.MenuVersion{
+			ID:           uuid.New(),
+			RestaurantID: restaurant.ID,
+			Number:       number,
+			Name:         fmt.Sprintf("Rollback to version %d (%s)", target.Number, target.Name),
+			Status:       db.MenuVersionDraft,
+			Snapshot:     target.Snapshot,
+			CreatedBy:    actorID(c),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuVersionRollback,
+			EntityType: "menu_version",
+			EntityID:   version.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     gin.H{"versionId": target.ID.String(), "number": target.Number},
+			After:      toMenuVersionResponse(version, nil),
+		}); err != nil {
+			return err
+		}
+		return publishMenuVersion(tx, c, restaurant, &version, now)
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to roll back menu"})
+		return
+	}
+
+	c.JSON(200, h.menuVersionWithSnapshot(c, version))
+}
+
+// DiffMenuVersion godoc
+// @Summary Compare menu versions
+// @Description List the categories and items added, removed or changed from another version, or the live menu, to this version
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param versionId path string true "Version ID"
+// @Param against query string false "Version ID to compare with, or live" default(live)
+// @Success 200 {object} MenuDiff
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menu-versions/{versionId}/diff [get]
+func (h *MenuHandler) DiffMenuVersion(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	version, ok := h.menuVersionParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	var to MenuSnapshot
+	if err := json.Unmarshal(version.Snapshot, &to); err != nil {
+		c.JSON(500, gin.H{"error": "failed to read menu version"})
+		return
+	}
+
+	against := c.DefaultQuery("against", "live")
+	var from MenuSnapshot
+	if against == "live" {
+		live, err := liveMenuSnapshot(h.DB, restaurant.ID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "failed to fetch menus"})
+			return
+		}
+		from = live
+	} else {
+		other, err := menuVersionByID(h.DB, restaurant.ID, against)
+		if err == errMenuVersionNotFound {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": "failed to fetch menu version"})
+			return
+		}
+		if err := json.Unmarshal(other.Snapshot, &from); err != nil {
+			c.JSON(500, gin.H{"error": "failed to read menu version"})
+			return
+		}
+		against = other.ID.String()
+	}
+
+	diff := diffMenuSnapshots(from, to)
+	diff.From = against
+	diff.To = version.ID.String()
+	c.JSON(200, diff)
+}
+
+// PublishDueMenuVersions publishes scheduled versions whose time has come.
+// It is run periodically by a background job; versions being published by
+// another instance are skipped.
+func PublishDueMenuVersions(gdb *gorm.DB, now time.Time) (int, error) {
+	var due []db.MenuVersion
+	if err := gdb.Omit("snapshot").
+		Where("status = ? AND publish_at <= ?", db.MenuVersionScheduled, now).
+		Order("publish_at ASC").Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, candidate := range due {
+		// Counted once the transaction commits; another worker may have
+		// published the version first
+		done := false
+		err := gdb.Transaction(func(tx *gorm.DB) error {
+			var version db.MenuVersion
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status = ?", candidate.ID, db.MenuVersionScheduled).
+				First(&version).Error
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			var restaurant db.Restaurant
+			if err := tx.Where("id = ?", version.RestaurantID).First(&restaurant).Error; err != nil {
+				return err
+			}
+			if err := publishMenuVersion(tx, nil, &restaurant, &version, now); err != nil {
+				return err
+			}
+			done = true
+			return nil
+		})
+		if err != nil {
+			log.Printf("menu versions: failed to publish %s: %v", candidate.ID, err)
+			continue
+		}
+		if done {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// publishMenuVersion replaces the live menu with the version's snapshot and
+// makes it the published version. c is nil when the background job
+// publishes it.
+func publishMenuVersion(tx *gorm.DB, c *gin.Context, restaurant *db.Restaurant, version *db.MenuVersion, now time.Time) error {
+	// One publish at a time per restaurant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", restaurant.ID).First(&db.Restaurant{}).Error; err != nil {
+		return err
+	}
+
+	var snapshot MenuSnapshot
+	if err := json.Unmarshal(version.Snapshot, &snapshot); err != nil {
+		return err
+	}
+	rescaleMenuSnapshot(&snapshot, restaurantCurrency(*restaurant))
+	live, err := liveMenuSnapshot(tx, restaurant.ID)
+	if err != nil {
+		return err
+	}
+
+	// Keep the live menu as history unless it is exactly the published
+	// version, so that edits made outside versions can be rolled back to
+	var current db.MenuVersion
+	err = tx.Where("restaurant_id = ? AND status = ?", restaurant.ID, db.MenuVersionPublished).First(&current).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	keepLive := err == gorm.ErrRecordNotFound
+	if !keepLive {
+		var published MenuSnapshot
+		if err := json.Unmarshal(current.Snapshot, &published); err != nil {
+			return err
+		}
+		keepLive = !diffMenuSnapshots(published, live).empty()
+		if err := tx.Model(&current).Updates(map[string]interface{}{"status": db.MenuVersionArchived, "updated_at": now}).Error; err != nil {
+			return err
+		}
+	}
+	if keepLive && (len(live.Categories) > 0 || len(live.Items) > 0) {
+		raw, err := json.Marshal(live)
+		if err != nil {
+			return err
+		}
+		number, err := nextMenuVersionNumber(tx, restaurant.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&db.MenuVersion{
+			ID:           uuid.New(),
+			RestaurantID: restaurant.ID,
+			Number:       number,
+			Name:         fmt.Sprintf("Live menu before version %d", version.Number),
+			Status:       db.MenuVersionArchived,
+			Snapshot:     raw,
+			PublishedAt:  &now,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	applied, err := applyMenuSnapshot(tx, restaurant.ID, snapshot, now)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(applied)
+	if err != nil {
+		return err
+	}
+
+	before := toMenuVersionResponse(*version, nil)
+	version.Snapshot = raw
+	version.Status = db.MenuVersionPublished
+	version.PublishAt = nil
+	version.PublishedAt = &now
+	version.UpdatedAt = now
+	if err := tx.Save(version).Error; err != nil {
+		return err
+	}
+	return audit.Record(tx, c, audit.Entry{
+		Action:     audit.ActionMenuVersionPublish,
+		EntityType: "menu_version",
+		EntityID:   version.ID.String(),
+		OrgID:      &restaurant.OrgID,
+		Before:     before,
+		After:      toMenuVersionResponse(*version, nil),
+	})
+}
+
+// applyMenuSnapshot makes the restaurant's live categories and items match
+// the snapshot. Live rows keep their IDs, sold-out flags and availability
+// rules; rows missing from the snapshot are deleted. It returns the
+// snapshot with the IDs given to new rows.
+func applyMenuSnapshot(tx *gorm.DB, restaurantID uuid.UUID, snapshot MenuSnapshot, now time.Time) (MenuSnapshot, error) {
+	var categories []db.MenuCategory
+	if err := tx.Where("restaurant_id = ?", restaurantID).Find(&categories).Error; err != nil {
+		return snapshot, err
+	}
+	var menus []db.Menu
+	if err := tx.Where("restaurant_id = ?", restaurantID).Find(&menus).Error; err != nil {
+		return snapshot, err
+	}
+	liveCategories := make(map[string]db.MenuCategory, len(categories))
+	for _, category := range categories {
+		liveCategories[category.ID.String()] = category
+	}
+	liveMenus := make(map[string]db.Menu, len(menus))
+	for _, menu := range menus {
+		liveMenus[menu.ID.String()] = menu
+	}
+
+	// IDs that are not live rows of this restaurant get fresh ones
+	categoryIDs := make(map[string]string, len(snapshot.Categories))
+	for i, category := range snapshot.Categories {
+		id := category.ID
+		if _, ok := liveCategories[id]; !ok {
+			id = uuid.New().String()
+		}
+		categoryIDs[category.ID] = id
+		snapshot.Categories[i].ID = id
+	}
+	remap := func(id *string) *string {
+		if id == nil {
+			return nil
+		}
+		mapped := categoryIDs[*id]
+		return &mapped
+	}
+	for i := range snapshot.Categories {
+		snapshot.Categories[i].ParentID = remap(snapshot.Categories[i].ParentID)
+	}
+	for i := range snapshot.Items {
+		if _, ok := liveMenus[snapshot.Items[i].ID]; !ok {
+			snapshot.Items[i].ID = uuid.New().String()
+		}
+		snapshot.Items[i].CategoryID = remap(snapshot.Items[i].CategoryID)
+	}
+
+	// Categories, parents first
+	ordered := make([]db.MenuCategory, 0, len(snapshot.Categories))
+	for _, sc := range snapshot.Categories {
+		category, ok := liveCategories[sc.ID]
+		if !ok {
+			category = db.MenuCategory{ID: uuid.MustParse(sc.ID), RestaurantID: restaurantID, CreatedAt: now}
+		}
+		category.ParentID = parseOptionalID(sc.ParentID)
+		category.Name = sc.Name
+		category.Description = sc.Description
+		category.SortOrder = sc.SortOrder
+		category.UpdatedAt = now
+		ordered = append(ordered, category)
+	}
+	sortParentsFirst(ordered)
+	for i := range ordered {
+		if err := tx.Save(&ordered[i]).Error; err != nil {
+			return snapshot, err
+		}
+	}
+
+	// SKUs may move between items, so they are cleared before saving
+	if err := tx.Model(&db.Menu{}).Where("restaurant_id = ? AND sku <> ''", restaurantID).Update("sku", "").Error; err != nil {
+		return snapshot, err
+	}
+	keepMenus := make(map[string]bool, len(snapshot.Items))
+	for _, item := range snapshot.Items {
+		menu, ok := liveMenus[item.ID]
+		if !ok {
+			menu = db.Menu{ID: uuid.MustParse(item.ID), RestaurantID: restaurantID, CreatedAt: now}
+		}
+		menu.CategoryID = parseOptionalID(item.CategoryID)
+		menu.SKU = item.SKU
+		menu.Name = item.Name
+		menu.ShortDesc = item.ShortDesc
+		menu.ImageURL = item.ImageURL
+		menu.Price = item.Price
+		menu.Type = db.MenuType(item.Type)
+		menu.MealType = db.MealType(item.MealType)
+		menu.SortOrder = item.SortOrder
+		menu.DietaryTags = item.DietaryTags
+		menu.Allergens = item.Allergens
+		menu.SpiceLevel = item.SpiceLevel
+		menu.UpdatedAt = now
+		if err := tx.Omit(clause.Associations).Save(&menu).Error; err != nil {
+			return snapshot, err
+		}
+		if err := replaceMenuOptions(tx, &menu, &item.Variants, &item.OptionGroups); err != nil {
+			return snapshot, err
+		}
+		keepMenus[item.ID] = true
+	}
+
+	for id, menu := range liveMenus {
+		if !keepMenus[id] {
+			if err := tx.Delete(&menu).Error; err != nil {
+				return snapshot, err
+			}
+		}
+	}
+	for id, category := range liveCategories {
+		if _, ok := categoryIDs[id]; !ok {
+			if err := tx.Delete(&category).Error; err != nil {
+				return snapshot, err
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// liveMenuSnapshot captures the restaurant's live categories and items
+func liveMenuSnapshot(tx *gorm.DB, restaurantID uuid.UUID) (MenuSnapshot, error) {
+	var categories []db.MenuCategory
+	if err := tx.Where("restaurant_id = ?", restaurantID).Order("sort_order ASC, created_at ASC").Find(&categories).Error; err != nil {
+		return MenuSnapshot{}, err
+	}
+	var menus []db.Menu
+	if err := withMenuOptions(tx).Where("restaurant_id = ?", restaurantID).Order("sort_order ASC, created_at ASC").Find(&menus).Error; err != nil {
+		return MenuSnapshot{}, err
+	}
+	return menuSnapshotOf(categories, menus), nil
+}
+
+func menuSnapshotOf(categories []db.MenuCategory, menus []db.Menu) MenuSnapshot {
+	snapshot := MenuSnapshot{
+		Categories: make([]MenuSnapshotCategory, 0, len(categories)),
+		Items:      make([]MenuSnapshotItem, 0, len(menus)),
+	}
+	for _, category := range categories {
+		snapshot.Categories = append(snapshot.Categories, MenuSnapshotCategory{
+			ID:          category.ID.String(),
+			ParentID:    formatOptionalID(category.ParentID),
+			Name:        category.Name,
+			Description: category.Description,
+			SortOrder:   category.SortOrder,
+		})
+	}
+	for _, menu := range menus {
+		variants, groups := menuOptionsFromModel(menu)
+		item := MenuSnapshotItem{
+			ID:           menu.ID.String(),
+			CategoryID:   formatOptionalID(menu.CategoryID),
+			SKU:          menu.SKU,
+			Name:         menu.Name,
+			ShortDesc:    menu.ShortDesc,
+			ImageURL:     menu.ImageURL,
+			Price:        menu.Price,
+			Type:         string(menu.Type),
+			MealType:     string(menu.MealType),
+			SortOrder:    menu.SortOrder,
+			DietaryTags:  stringsOrEmpty(menu.DietaryTags),
+			Allergens:    stringsOrEmpty(menu.Allergens),
+			SpiceLevel:   menu.SpiceLevel,
+			Variants:     variants,
+			OptionGroups: groups,
+		}
+		normalizeSnapshotItem(&item)
+		snapshot.Items = append(snapshot.Items, item)
+	}
+	return snapshot
+}
+
+// validateMenuSnapshot checks a snapshot the way menu items and categories
+// are checked when edited directly, and normalizes it: missing IDs and
+// currencies are filled in, labels are canonical and items with variants
+// take the lowest variant price.
+func validateMenuSnapshot(snapshot *MenuSnapshot, currency string) error {
+	if snapshot.Categories == nil {
+		snapshot.Categories = []MenuSnapshotCategory{}
+	}
+	if snapshot.Items == nil {
+		snapshot.Items = []MenuSnapshotItem{}
+	}
+
+	categories := make([]db.MenuCategory, 0, len(snapshot.Categories))
+	ids := make(map[string]bool, len(snapshot.Categories))
+	for i := range snapshot.Categories {
+		category := &snapshot.Categories[i]
+		category.Name = strings.TrimSpace(category.Name)
+		if category.Name == "" {
+			return fmt.Errorf("category %d: name is required", i+1)
+		}
+		if category.ID == "" {
+			category.ID = uuid.New().String()
+		}
+		id, err := uuid.Parse(category.ID)
+		if err != nil {
+			return fmt.Errorf("category %q: invalid ID", category.Name)
+		}
+		category.ID = id.String()
+		if ids[category.ID] {
+			return fmt.Errorf("category %q: duplicate ID", category.Name)
+		}
+		ids[category.ID] = true
+		categories = append(categories, db.MenuCategory{ID: id})
+	}
+	for i, category := range snapshot.Categories {
+		if category.ParentID == nil {
+			continue
+		}
+		parentID := parseOptionalID(category.ParentID)
+		if parentID == nil || !ids[parentID.String()] {
+			return fmt.Errorf("category %q: %v", category.Name, errCategoryNotFound)
+		}
+		snapshot.Categories[i].ParentID = formatOptionalID(parentID)
+		categories[i].ParentID = parentID
+	}
+	for i, category := range snapshot.Categories {
+		if err := checkCategoryPlacement(categories, categories[i].ID, categories[i].ParentID); err != nil {
+			return fmt.Errorf("category %q: %v", category.Name, err)
+		}
+	}
+
+	itemIDs := make(map[string]bool, len(snapshot.Items))
+	skus := make(map[string]bool, len(snapshot.Items))
+	for i := range snapshot.Items {
+		item := &snapshot.Items[i]
+		item.Name = strings.TrimSpace(item.Name)
+		item.SKU = strings.TrimSpace(item.SKU)
+		fail := func(err error) error {
+			return fmt.Errorf("item %d (%s): %v", i+1, item.Name, err)
+		}
+
+		if item.Name == "" {
+			return fail(errors.New("name is required"))
+		}
+		if item.ID == "" {
+			item.ID = uuid.New().String()
+		}
+		id, err := uuid.Parse(item.ID)
+		if err != nil {
+			return fail(errors.New("invalid ID"))
+		}
+		item.ID = id.String()
+		if itemIDs[item.ID] {
+			return fail(errors.New("duplicate ID"))
+		}
+		itemIDs[item.ID] = true
+		if item.SKU != "" {
+			if skus[item.SKU] {
+				return fail(fmt.Errorf("duplicate SKU %q", item.SKU))
+			}
+			skus[item.SKU] = true
+		}
+		if item.CategoryID != nil {
+			categoryID := parseOptionalID(item.CategoryID)
+			if categoryID == nil || !ids[categoryID.String()] {
+				return fail(errCategoryNotFound)
+			}
+			item.CategoryID = formatOptionalID(categoryID)
+		}
+		if err := menuPricesIn(currency, &item.Price, item.Variants, item.OptionGroups); err != nil {
+			return fail(err)
+		}
+		if item.Price.Amount < 0 {
+			return fail(errors.New("price cannot be negative"))
+		}
+		if item.Type != string(db.MenuTypeDrink) && item.Type != string(db.MenuTypeFood) {
+			return fail(errors.New("type must be DRINK or FOOD"))
+		}
+		if item.MealType != string(db.MealTypeLunch) && item.MealType != string(db.MealTypeDinner) && item.MealType != string(db.MealTypeBoth) {
+			return fail(errors.New("mealType must be LUNCH, DINNER or BOTH"))
+		}
+		tags, allergens, err := dietaryLabels(item.DietaryTags, item.Allergens, item.SpiceLevel)
+		if err != nil {
+			return fail(err)
+		}
+		item.DietaryTags, item.Allergens = tags, allergens
+		if err := validateMenuOptions(item.Price, item.Variants, item.OptionGroups); err != nil {
+			return fail(err)
+		}
+		if len(item.Variants) > 0 {
+			item.Price = lowestVariantPrice(item.Variants)
+		}
+		normalizeSnapshotItem(item)
+	}
+	return nil
+}
+
+// rescaleMenuSnapshot moves prices saved before the restaurant changed
+// currency to currency, keeping their face value as the live menu did
+func rescaleMenuSnapshot(snapshot *MenuSnapshot, currency string) {
+	for i := range snapshot.Items {
+		item := &snapshot.Items[i]
+		item.Price = item.Price.Rescale(currency)
+		for j := range item.Variants {
+			item.Variants[j].Price = item.Variants[j].Price.Rescale(currency)
+		}
+		for _, group := range item.OptionGroups {
+			for j := range group.Options {
+				group.Options[j].PriceDelta = group.Options[j].PriceDelta.Rescale(currency)
+			}
+		}
+	}
+}
+
+// normalizeSnapshotItem replaces nil lists with empty ones, so that
+// snapshots compare and serialize the same however they were made
+func normalizeSnapshotItem(item *MenuSnapshotItem) {
+	if item.DietaryTags == nil {
+		item.DietaryTags = []string{}
+	}
+	if item.Allergens == nil {
+		item.Allergens = []string{}
+	}
+	if item.Variants == nil {
+		item.Variants = []MenuVariantInput{}
+	}
+	if item.OptionGroups == nil {
+		item.OptionGroups = []MenuOptionGroupInput{}
+	}
+	for i := range item.OptionGroups {
+		if item.OptionGroups[i].Options == nil {
+			item.OptionGroups[i].Options = []MenuOptionInput{}
+		}
+	}
+}
+
+// diffMenuSnapshots compares categories and items by ID
+func diffMenuSnapshots(from, to MenuSnapshot) MenuDiff {
+	diff := MenuDiff{Categories: []MenuDiffEntry{}, Items: []MenuDiffEntry{}}
+
+	fromCategories := make(map[string]MenuSnapshotCategory, len(from.Categories))
+	for _, category := range from.Categories {
+		fromCategories[category.ID] = category
+	}
+	toCategories := make(map[string]bool, len(to.Categories))
+	for _, category := range to.Categories {
+		toCategories[category.ID] = true
+		old, ok := fromCategories[category.ID]
+		if !ok {
+			diff.Categories = append(diff.Categories, MenuDiffEntry{ID: category.ID, Name: category.Name, Change: "added"})
+		} else if fields := changedFields(old, category); len(fields) > 0 {
+			diff.Categories = append(diff.Categories, MenuDiffEntry{ID: category.ID, Name: category.Name, Change: "changed", Fields: fields})
+		}
+	}
+	for _, category := range from.Categories {
+		if !toCategories[category.ID] {
+			diff.Categories = append(diff.Categories, MenuDiffEntry{ID: category.ID, Name: category.Name, Change: "removed"})
+		}
+	}
+
+	fromItems := make(map[string]MenuSnapshotItem, len(from.Items))
+	for _, item := range from.Items {
+		fromItems[item.ID] = item
+	}
+	toItems := make(map[string]bool, len(to.Items))
+	for _, item := range to.Items {
+		toItems[item.ID] = true
+		old, ok := fromItems[item.ID]
+		if !ok {
+			diff.Items = append(diff.Items, MenuDiffEntry{ID: item.ID, Name: item.Name, Change: "added"})
+		} else if fields := changedFields(old, item); len(fields) > 0 {
+			diff.Items = append(diff.Items, MenuDiffEntry{ID: item.ID, Name: item.Name, Change: "changed", Fields: fields})
+		}
+	}
+	for _, item := range from.Items {
+		if !toItems[item.ID] {
+			diff.Items = append(diff.Items, MenuDiffEntry{ID: item.ID, Name: item.Name, Change: "removed"})
+		}
+	}
+	return diff
+}
+
+func (d MenuDiff) empty() bool {
+	return len(d.Categories) == 0 && len(d.Items) == 0
+}
+
+// changedFields returns the JSON fields that differ between two values of
+// the same type
+func changedFields(from, to interface{}) map[string]MenuFieldChange {
+	var a, b map[string]interface{}
+	rawA, _ := json.Marshal(from)
+	rawB, _ := json.Marshal(to)
+	_ = json.Unmarshal(rawA, &a)
+	_ = json.Unmarshal(rawB, &b)
+
+	fields := make(map[string]MenuFieldChange)
+	for key, value := range b {
+		if !reflect.DeepEqual(a[key], value) {
+			fields[key] = MenuFieldChange{From: a[key], To: value}
+		}
+	}
+	return fields
+}
+
+func (h *MenuHandler) menuVersionParam(c *gin.Context, restaurantID uuid.UUID) (*db.MenuVersion, bool) {
+	version, err := menuVersionByID(h.DB, restaurantID, c.Param("versionId"))
+	if err == errMenuVersionNotFound {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch menu version"})
+		return nil, false
+	}
+	return version, true
+}
+
+func menuVersionByID(tx *gorm.DB, restaurantID uuid.UUID, id string) (*db.MenuVersion, error) {
+	versionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errMenuVersionNotFound
+	}
+	var version db.MenuVersion
+	if err := tx.Where("id = ? AND restaurant_id = ?", versionID, restaurantID).First(&version).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errMenuVersionNotFound
+		}
+		return nil, err
+	}
+	return &version, nil
+}
+
+func nextMenuVersionNumber(tx *gorm.DB, restaurantID uuid.UUID) (int, error) {
+	var max *int
+	if err := tx.Model(&db.MenuVersion{}).Select("MAX(number)").Where("restaurant_id = ?", restaurantID).Scan(&max).Error; err != nil {
+		return 0, err
+	}
+	if max == nil {
+		return 1, nil
+	}
+	return *max + 1, nil
+}
+
+// menuVersionWithSnapshot responds with the version's snapshot decoded;
+// a snapshot that cannot be decoded is left out
+func (h *MenuHandler) menuVersionWithSnapshot(c *gin.Context, version db.MenuVersion) MenuVersionResponse {
+	var snapshot MenuSnapshot
+	if err := json.Unmarshal(version.Snapshot, &snapshot); err != nil {
+		c.Error(err)
+		return toMenuVersionResponse(version, nil)
+	}
+	return toMenuVersionResponse(version, &snapshot)
+}
+
+func toMenuVersionResponse(version db.MenuVersion, snapshot *MenuSnapshot) MenuVersionResponse {
+	response := MenuVersionResponse{
+		ID:           version.ID.String(),
+		RestaurantID: version.RestaurantID.String(),
+		Number:       version.Number,
+		Name:         version.Name,
+		Status:       string(version.Status),
+		PublishAt:    version.PublishAt,
+		PublishedAt:  version.PublishedAt,
+		CreatedAt:    version.CreatedAt,
+		UpdatedAt:    version.UpdatedAt,
+		Snapshot:     snapshot,
+	}
+	if version.CreatedBy != nil {
+		id := version.CreatedBy.String()
+		response.CreatedBy = &id
+	}
+	return response
+}
+
+// actorID returns the user, or API key, making the request
+func actorID(c *gin.Context) *uuid.UUID {
+	if id, err := uuid.Parse(c.GetString("uid")); err == nil {
+		return &id
+	}
+	return nil
+}
+
+func parseOptionalID(id *string) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+	parsed, err := uuid.Parse(*id)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+func formatOptionalID(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotItem(name string, price int64) MenuSnapshotItem {
+	return MenuSnapshotItem{Name: name, Price: npr(price), Type: "FOOD", MealType: "BOTH"}
+}
+
+func TestValidateMenuSnapshot(t *testing.T) {
+	food := MenuSnapshotCategory{ID: uuid.NewString(), Name: " Food "}
+	momoID := uuid.NewString()
+	momo := MenuSnapshotCategory{ID: momoID, ParentID: &food.ID, Name: "Momo"}
+
+	item := snapshotItem("Momo", 100)
+	item.CategoryID = &momoID
+	item.DietaryTags = []string{"vegan"}
+	item.Variants = []MenuVariantInput{{Name: "10 pcs", Price: npr(250)}, {Name: "6 pcs", Price: money.Money{Amount: 18000}}}
+	snapshot := MenuSnapshot{Categories: []MenuSnapshotCategory{food, momo}, Items: []MenuSnapshotItem{item}}
+
+	require.NoError(t, validateMenuSnapshot(&snapshot, "NPR"))
+	assert.Equal(t, "Food", snapshot.Categories[0].Name)
+	got := snapshot.Items[0]
+	assert.NotEmpty(t, got.ID, "new items get an ID")
+	assert.Equal(t, npr(180), got.Price, "lowest variant price, currency filled in")
+	assert.Equal(t, []string{"VEGETARIAN", "VEGAN"}, got.DietaryTags)
+	assert.Equal(t, []string{}, got.Allergens)
+	assert.Equal(t, []MenuOptionGroupInput{}, got.OptionGroups)
+
+	missing := uuid.NewString()
+	tests := []struct {
+		name     string
+		snapshot MenuSnapshot
+	}{
+		{"unknown parent", MenuSnapshot{Categories: []MenuSnapshotCategory{{ID: momoID, ParentID: &missing, Name: "Momo"}}}},
+		{"cycle", MenuSnapshot{Categories: []MenuSnapshotCategory{{ID: food.ID, ParentID: &momoID, Name: "Food"}, {ID: momoID, ParentID: &food.ID, Name: "Momo"}}}},
+		{"unknown category", MenuSnapshot{Items: []MenuSnapshotItem{func() MenuSnapshotItem { i := snapshotItem("Momo", 1); i.CategoryID = &missing; return i }()}}},
+		{"no name", MenuSnapshot{Items: []MenuSnapshotItem{snapshotItem(" ", 1)}}},
+		{"bad type", MenuSnapshot{Items: []MenuSnapshotItem{{Name: "Momo", Type: "SNACK", MealType: "BOTH"}}}},
+		{"duplicate SKU", MenuSnapshot{Items: []MenuSnapshotItem{
+			func() MenuSnapshotItem { i := snapshotItem("A", 1); i.SKU = "X"; return i }(),
+			func() MenuSnapshotItem { i := snapshotItem("B", 1); i.SKU = "X"; return i }(),
+		}}},
+		{"other currency", MenuSnapshot{Items: []MenuSnapshotItem{func() MenuSnapshotItem {
+			i := snapshotItem("A", 1)
+			i.Price = money.FromMajor(1, "USD")
+			return i
+		}()}}},
+		{"bad option group", MenuSnapshot{Items: []MenuSnapshotItem{func() MenuSnapshotItem {
+			i := snapshotItem("A", 1)
+			i.OptionGroups = []MenuOptionGroupInput{{Name: "Sauce", MinSelect: 2, MaxSelect: 1}}
+			return i
+		}()}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, validateMenuSnapshot(&tt.snapshot, "NPR"))
+		})
+	}
+}
+
+func TestDiffMenuSnapshots(t *testing.T) {
+	food := db.MenuCategory{ID: uuid.New(), Name: "Food"}
+	momo := db.Menu{ID: uuid.New(), CategoryID: &food.ID, Name: "Momo", Price: npr(250), Type: db.MenuTypeFood, MealType: db.MealTypeBoth}
+	lassi := db.Menu{ID: uuid.New(), Name: "Lassi", Price: npr(120), Type: db.MenuTypeDrink, MealType: db.MealTypeBoth}
+	from := menuSnapshotOf([]db.MenuCategory{food}, []db.Menu{momo, lassi})
+
+	// Unchanged snapshots, even when built separately, have no differences
+	assert.True(t, diffMenuSnapshots(from, menuSnapshotOf([]db.MenuCategory{food}, []db.Menu{momo, lassi})).empty())
+
+	momo.Price = npr(280)
+	drinks := db.MenuCategory{ID: uuid.New(), Name: "Drinks"}
+	tea := db.Menu{ID: uuid.New(), CategoryID: &drinks.ID, Name: "Tea", Price: npr(60), Type: db.MenuTypeDrink, MealType: db.MealTypeBoth}
+	to := menuSnapshotOf([]db.MenuCategory{food, drinks}, []db.Menu{momo, tea})
+
+	diff := diffMenuSnapshots(from, to)
+	assert.Equal(t, []MenuDiffEntry{{ID: drinks.ID.String(), Name: "Drinks", Change: "added"}}, diff.Categories)
+	require.Len(t, diff.Items, 3)
+	assert.Equal(t, MenuDiffEntry{
+		ID: momo.ID.String(), Name: "Momo", Change: "changed",
+		Fields: map[string]MenuFieldChange{"price": {
+			From: map[string]interface{}{"amount": float64(25000), "currency": "NPR"},
+			To:   map[string]interface{}{"amount": float64(28000), "currency": "NPR"},
+		}},
+	}, diff.Items[0])
+	assert.Equal(t, "added", diff.Items[1].Change)
+	assert.Equal(t, "Tea", diff.Items[1].Name)
+	assert.Equal(t, MenuDiffEntry{ID: lassi.ID.String(), Name: "Lassi", Change: "removed"}, diff.Items[2])
+}
@@ -2,12 +2,18 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
 	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/i18n"
+	"github.com/example/restosaas/apps/api/internal/money"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MenuHandler struct{ DB *gorm.DB }
@@ -17,31 +23,98 @@ type CreateMenuRequest struct {
 	Name      string `json:"name" binding:"required"`
 	ShortDesc string `json:"shortDesc"`
 	ImageURL  string `json:"imageUrl"`
-	Price     int    `json:"price" binding:"required,min=0"`
 	Type      string `json:"type" binding:"required,oneof=DRINK FOOD"`
 	MealType  string `json:"mealType" binding:"required,oneof=LUNCH DINNER BOTH"`
+	// In the restaurant's currency, which may be left out
+	Price money.Money `json:"price"`
+	// Optional code of the restaurant's own, unique within the restaurant
+	SKU string `json:"sku"`
+	// Optional category; the item is placed last in it
+	CategoryID string `json:"categoryId"`
+	// With variants, the item is sold at a variant's price and Price is
+	// set to the lowest one
+	Variants     []MenuVariantInput     `json:"variants"`
+	OptionGroups []MenuOptionGroupInput `json:"optionGroups"`
+	// Dietary labels from the dietary package, e.g. ["VEGAN"] and ["NUTS"]
+	DietaryTags []string `json:"dietaryTags"`
+	Allergens   []string `json:"allergens"`
+	SpiceLevel  int      `json:"spiceLevel" binding:"min=0,max=3"`
 }
 
 type UpdateMenuRequest struct {
-	Name      *string `json:"name,omitempty"`
-	ShortDesc *string `json:"shortDesc,omitempty"`
-	ImageURL  *string `json:"imageUrl,omitempty"`
-	Price     *int    `json:"price,omitempty"`
-	Type      *string `json:"type,omitempty"`
-	MealType  *string `json:"mealType,omitempty"`
+	Name      *string      `json:"name,omitempty"`
+	ShortDesc *string      `json:"shortDesc,omitempty"`
+	ImageURL  *string      `json:"imageUrl,omitempty"`
+	Price     *money.Money `json:"price,omitempty"`
+	Type      *string      `json:"type,omitempty"`
+	MealType  *string      `json:"mealType,omitempty"`
+	SKU       *string      `json:"sku,omitempty"`
+	// Moves the item to the end of another category; an empty string
+	// makes it uncategorized
+	CategoryID *string `json:"categoryId,omitempty"`
+	// Replace all variants or option groups when present; an empty list
+	// removes them
+	Variants     *[]MenuVariantInput     `json:"variants,omitempty"`
+	OptionGroups *[]MenuOptionGroupInput `json:"optionGroups,omitempty"`
+	DietaryTags  *[]string               `json:"dietaryTags,omitempty"`
+	Allergens    *[]string               `json:"allergens,omitempty"`
+	SpiceLevel   *int                    `json:"spiceLevel,omitempty" binding:"omitempty,min=0,max=3"`
 }
 
 type MenuResponse struct {
-	ID           string    `json:"id"`
-	RestaurantID string    `json:"restaurantId"`
-	Name         string    `json:"name"`
-	ShortDesc    string    `json:"shortDesc"`
-	ImageURL     string    `json:"imageUrl"`
-	Price        int       `json:"price"`
-	Type         string    `json:"type"`
-	MealType     string    `json:"mealType"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID           string      `json:"id"`
+	RestaurantID string      `json:"restaurantId"`
+	CategoryID   *string     `json:"categoryId"`
+	SKU          string      `json:"sku"`
+	Name         string      `json:"name"`
+	ShortDesc    string      `json:"shortDesc"`
+	ImageURL     string      `json:"imageUrl"`
+	Price        money.Money `json:"price"`
+	Type         string      `json:"type"`
+	MealType     string      `json:"mealType"`
+	SortOrder    int         `json:"sortOrder"`
+	DietaryTags  []string    `json:"dietaryTags"`
+	Allergens    []string    `json:"allergens"`
+	SpiceLevel   int         `json:"spiceLevel"`
+	SoldOutUntil *time.Time  `json:"soldOutUntil"`
+	Available    *bool       `json:"available,omitempty"` // Orderable at the requested time (public endpoints)
+	CreatedAt    time.Time   `json:"createdAt"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+
+	// What a customer pays, formatted for the request locale (public
+	// endpoints)
+	CustomerPrice *PriceDisplay `json:"customerPrice,omitempty"`
+
+	Variants     []MenuVariantResponse     `json:"variants"`
+	OptionGroups []MenuOptionGroupResponse `json:"optionGroups"`
+}
+
+func toMenuResponse(menu db.Menu) MenuResponse {
+	response := MenuResponse{
+		ID:           menu.ID.String(),
+		RestaurantID: menu.RestaurantID.String(),
+		SKU:          menu.SKU,
+		Name:         menu.Name,
+		ShortDesc:    menu.ShortDesc,
+		ImageURL:     menu.ImageURL,
+		Price:        menu.Price,
+		Type:         string(menu.Type),
+		MealType:     string(menu.MealType),
+		SortOrder:    menu.SortOrder,
+		DietaryTags:  stringsOrEmpty(menu.DietaryTags),
+		Allergens:    stringsOrEmpty(menu.Allergens),
+		SpiceLevel:   menu.SpiceLevel,
+		SoldOutUntil: menu.SoldOutUntil,
+		CreatedAt:    menu.CreatedAt,
+		UpdatedAt:    menu.UpdatedAt,
+		Variants:     toMenuVariantResponses(menu.Variants),
+		OptionGroups: toMenuOptionGroupResponses(menu.OptionGroups),
+	}
+	if menu.CategoryID != nil {
+		id := menu.CategoryID.String()
+		response.CategoryID = &id
+	}
+	return response
 }
 
 // CreateMenu godoc
@@ -55,63 +128,101 @@ type MenuResponse struct {
 // @Success 201 {object} MenuResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/menus [post]
 func (h *MenuHandler) CreateMenu(c *gin.Context) {
-	restaurantID := c.Param("id")
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
+	// Loaded and authorized by authz.RestaurantScope
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
+
+	var req CreateMenuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Check if restaurant exists
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ?", restaurantUUID).First(&restaurant).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "restaurant not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": "failed to fetch restaurant"})
+	if err := menuPricesIn(restaurantCurrency(*restaurant), &req.Price, req.Variants, req.OptionGroups); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Price.Amount < 0 {
+		c.JSON(400, gin.H{"error": "price cannot be negative"})
+		return
+	}
+	if err := validateMenuOptions(req.Price, req.Variants, req.OptionGroups); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	tags, allergens, err := dietaryLabels(req.DietaryTags, req.Allergens, req.SpiceLevel)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	price := req.Price
+	if len(req.Variants) > 0 {
+		price = lowestVariantPrice(req.Variants)
+	}
 
-	var req CreateMenuRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	categoryID, err := h.categoryInRestaurant(restaurantUUID, req.CategoryID)
+	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	req.SKU = strings.TrimSpace(req.SKU)
+	if taken, err := h.skuTaken(restaurantUUID, req.SKU, uuid.Nil); err != nil {
+		c.JSON(500, gin.H{"error": "failed to create menu"})
+		return
+	} else if taken {
+		c.JSON(409, gin.H{"error": "another menu item has this SKU"})
+		return
+	}
+	sortOrder, err := h.nextMenuSortOrder(restaurantUUID, categoryID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create menu"})
+		return
+	}
 
 	menu := db.Menu{
 		ID:           uuid.New(),
 		RestaurantID: restaurantUUID,
+		SKU:          req.SKU,
 		Name:         req.Name,
 		ShortDesc:    req.ShortDesc,
 		ImageURL:     req.ImageURL,
-		Price:        req.Price,
+		Price:        price,
 		Type:         db.MenuType(req.Type),
 		MealType:     db.MealType(req.MealType),
+		CategoryID:   categoryID,
+		SortOrder:    sortOrder,
+		DietaryTags:  tags,
+		Allergens:    allergens,
+		SpiceLevel:   req.SpiceLevel,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
-	if err := h.DB.Create(&menu).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&menu).Error; err != nil {
+			return err
+		}
+		if err := replaceMenuOptions(tx, &menu, &req.Variants, &req.OptionGroups); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuCreate,
+			EntityType: "menu",
+			EntityID:   menu.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			After:      menu,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to create menu"})
 		return
 	}
 
-	response := MenuResponse{
-		ID:           menu.ID.String(),
-		RestaurantID: menu.RestaurantID.String(),
-		Name:         menu.Name,
-		ShortDesc:    menu.ShortDesc,
-		ImageURL:     menu.ImageURL,
-		Price:        menu.Price,
-		Type:         string(menu.Type),
-		MealType:     string(menu.MealType),
-		CreatedAt:    menu.CreatedAt,
-		UpdatedAt:    menu.UpdatedAt,
-	}
+	response := toMenuResponse(menu)
 
 	c.JSON(201, response)
 }
@@ -125,29 +236,15 @@ func (h *MenuHandler) CreateMenu(c *gin.Context) {
 // @Param restaurantId path string true "Restaurant ID"
 // @Param type query string false "Filter by type (DRINK or FOOD)"
 // @Param mealType query string false "Filter by meal type (LUNCH, DINNER, or BOTH)"
+// @Param categoryId query string false "Filter by category, or \"none\" for uncategorized items"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/menus [get]
 func (h *MenuHandler) ListMenus(c *gin.Context) {
-	restaurantID := c.Param("id")
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
-	// Check if restaurant exists
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ?", restaurantUUID).First(&restaurant).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "restaurant not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": "failed to fetch restaurant"})
-		return
-	}
+	// Loaded and authorized by authz.RestaurantScope
+	restaurantUUID := authz.ScopedRestaurant(c).ID
 
 	// Get filter parameters
 	menuType := c.Query("type")
@@ -161,27 +258,28 @@ func (h *MenuHandler) ListMenus(c *gin.Context) {
 	if mealType != "" {
 		query = query.Where("meal_type = ?", mealType)
 	}
+	switch categoryID := c.Query("categoryId"); categoryID {
+	case "":
+	case "none":
+		query = query.Where("category_id IS NULL")
+	default:
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid category ID"})
+			return
+		}
+		query = query.Where("category_id = ?", id)
+	}
 
 	var menus []db.Menu
-	if err := query.Order("created_at DESC").Find(&menus).Error; err != nil {
+	if err := withMenuOptions(query).Order("sort_order ASC, created_at DESC").Find(&menus).Error; err != nil {
 		c.JSON(500, gin.H{"error": "failed to fetch menus"})
 		return
 	}
 
-	var response []MenuResponse
+	response := make([]MenuResponse, 0, len(menus))
 	for _, menu := range menus {
-		response = append(response, MenuResponse{
-			ID:           menu.ID.String(),
-			RestaurantID: menu.RestaurantID.String(),
-			Name:         menu.Name,
-			ShortDesc:    menu.ShortDesc,
-			ImageURL:     menu.ImageURL,
-			Price:        menu.Price,
-			Type:         string(menu.Type),
-			MealType:     string(menu.MealType),
-			CreatedAt:    menu.CreatedAt,
-			UpdatedAt:    menu.UpdatedAt,
-		})
+		response = append(response, toMenuResponse(menu))
 	}
 
 	c.JSON(200, gin.H{"menus": response})
@@ -201,15 +299,9 @@ func (h *MenuHandler) ListMenus(c *gin.Context) {
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/menus/{menuId} [get]
 func (h *MenuHandler) GetMenu(c *gin.Context) {
-	restaurantID := c.Param("id")
+	restaurantUUID := authz.ScopedRestaurant(c).ID
 	menuID := c.Param("menuId")
 
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
 	menuUUID, err := uuid.Parse(menuID)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "invalid menu ID"})
@@ -217,7 +309,7 @@ func (h *MenuHandler) GetMenu(c *gin.Context) {
 	}
 
 	var menu db.Menu
-	if err := h.DB.Where("id = ? AND restaurant_id = ?", menuUUID, restaurantUUID).First(&menu).Error; err != nil {
+	if err := withMenuOptions(h.DB).Where("id = ? AND restaurant_id = ?", menuUUID, restaurantUUID).First(&menu).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(404, gin.H{"error": "menu not found"})
 			return
@@ -226,18 +318,7 @@ func (h *MenuHandler) GetMenu(c *gin.Context) {
 		return
 	}
 
-	response := MenuResponse{
-		ID:           menu.ID.String(),
-		RestaurantID: menu.RestaurantID.String(),
-		Name:         menu.Name,
-		ShortDesc:    menu.ShortDesc,
-		ImageURL:     menu.ImageURL,
-		Price:        menu.Price,
-		Type:         string(menu.Type),
-		MealType:     string(menu.MealType),
-		CreatedAt:    menu.CreatedAt,
-		UpdatedAt:    menu.UpdatedAt,
-	}
+	response := toMenuResponse(menu)
 
 	c.JSON(200, response)
 }
@@ -254,18 +335,14 @@ func (h *MenuHandler) GetMenu(c *gin.Context) {
 // @Success 200 {object} MenuResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/menus/{menuId} [put]
 func (h *MenuHandler) UpdateMenu(c *gin.Context) {
-	restaurantID := c.Param("id")
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
 	menuID := c.Param("menuId")
 
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
 	menuUUID, err := uuid.Parse(menuID)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "invalid menu ID"})
@@ -273,7 +350,7 @@ func (h *MenuHandler) UpdateMenu(c *gin.Context) {
 	}
 
 	var menu db.Menu
-	if err := h.DB.Where("id = ? AND restaurant_id = ?", menuUUID, restaurantUUID).First(&menu).Error; err != nil {
+	if err := withMenuOptions(h.DB).Where("id = ? AND restaurant_id = ?", menuUUID, restaurantUUID).First(&menu).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(404, gin.H{"error": "menu not found"})
 			return
@@ -289,6 +366,7 @@ func (h *MenuHandler) UpdateMenu(c *gin.Context) {
 	}
 
 	// Update fields
+	before := menu
 	if req.Name != nil {
 		menu.Name = *req.Name
 	}
@@ -299,6 +377,10 @@ func (h *MenuHandler) UpdateMenu(c *gin.Context) {
 		menu.ImageURL = *req.ImageURL
 	}
 	if req.Price != nil {
+		if req.Price.Amount < 0 {
+			c.JSON(400, gin.H{"error": "price cannot be negative"})
+			return
+		}
 		menu.Price = *req.Price
 	}
 	if req.Type != nil {
@@ -307,26 +389,93 @@ func (h *MenuHandler) UpdateMenu(c *gin.Context) {
 	if req.MealType != nil {
 		menu.MealType = db.MealType(*req.MealType)
 	}
+	if req.SKU != nil {
+		menu.SKU = strings.TrimSpace(*req.SKU)
+		if taken, err := h.skuTaken(restaurantUUID, menu.SKU, menu.ID); err != nil {
+			c.JSON(500, gin.H{"error": "failed to update menu"})
+			return
+		} else if taken {
+			c.JSON(409, gin.H{"error": "another menu item has this SKU"})
+			return
+		}
+	}
+	if req.DietaryTags != nil || req.Allergens != nil || req.SpiceLevel != nil {
+		tags, allergens := []string(menu.DietaryTags), []string(menu.Allergens)
+		if req.DietaryTags != nil {
+			tags = *req.DietaryTags
+		}
+		if req.Allergens != nil {
+			allergens = *req.Allergens
+		}
+		if req.SpiceLevel != nil {
+			menu.SpiceLevel = *req.SpiceLevel
+		}
+		menu.DietaryTags, menu.Allergens, err = dietaryLabels(tags, allergens, menu.SpiceLevel)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	variants, groups := menuOptionsFromModel(menu)
+	if req.Variants != nil {
+		variants = *req.Variants
+	}
+	if req.OptionGroups != nil {
+		groups = *req.OptionGroups
+	}
+	if err := menuPricesIn(restaurantCurrency(*restaurant), &menu.Price, variants, groups); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateMenuOptions(menu.Price, variants, groups); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if len(variants) > 0 {
+		menu.Price = lowestVariantPrice(variants)
+	}
+	if req.CategoryID != nil {
+		categoryID, err := h.categoryInRestaurant(restaurantUUID, *req.CategoryID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if !sameID(categoryID, menu.CategoryID) {
+			sortOrder, err := h.nextMenuSortOrder(restaurantUUID, categoryID)
+			if err != nil {
+				c.JSON(500, gin.H{"error": "failed to update menu"})
+				return
+			}
+			menu.CategoryID = categoryID
+			menu.SortOrder = sortOrder
+		}
+	}
 
 	menu.UpdatedAt = time.Now()
 
-	if err := h.DB.Save(&menu).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(&menu).Error; err != nil {
+			return err
+		}
+		if err := replaceMenuOptions(tx, &menu, req.Variants, req.OptionGroups); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuUpdate,
+			EntityType: "menu",
+			EntityID:   menu.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      menu,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to update menu"})
 		return
 	}
 
-	response := MenuResponse{
-		ID:           menu.ID.String(),
-		RestaurantID: menu.RestaurantID.String(),
-		Name:         menu.Name,
-		ShortDesc:    menu.ShortDesc,
-		ImageURL:     menu.ImageURL,
-		Price:        menu.Price,
-		Type:         string(menu.Type),
-		MealType:     string(menu.MealType),
-		CreatedAt:    menu.CreatedAt,
-		UpdatedAt:    menu.UpdatedAt,
-	}
+	response := toMenuResponse(menu)
 
 	c.JSON(200, response)
 }
@@ -345,15 +494,10 @@ func (h *MenuHandler) UpdateMenu(c *gin.Context) {
 // @Failure 500 {object} map[string]string
 // @Router /owner/restaurants/{restaurantId}/menus/{menuId} [delete]
 func (h *MenuHandler) DeleteMenu(c *gin.Context) {
-	restaurantID := c.Param("id")
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
 	menuID := c.Param("menuId")
 
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
 	menuUUID, err := uuid.Parse(menuID)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "invalid menu ID"})
@@ -361,7 +505,7 @@ func (h *MenuHandler) DeleteMenu(c *gin.Context) {
 	}
 
 	var menu db.Menu
-	if err := h.DB.Where("id = ? AND restaurant_id = ?", menuUUID, restaurantUUID).First(&menu).Error; err != nil {
+	if err := withMenuOptions(h.DB).Where("id = ? AND restaurant_id = ?", menuUUID, restaurantUUID).First(&menu).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(404, gin.H{"error": "menu not found"})
 			return
@@ -370,7 +514,19 @@ func (h *MenuHandler) DeleteMenu(c *gin.Context) {
 		return
 	}
 
-	if err := h.DB.Delete(&menu).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&menu).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuDelete,
+			EntityType: "menu",
+			EntityID:   menu.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     menu,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to delete menu"})
 		return
 	}
@@ -380,13 +536,18 @@ func (h *MenuHandler) DeleteMenu(c *gin.Context) {
 
 // PublicGetMenus godoc
 // @Summary Get menu items for a restaurant (public)
-// @Description Get all menu items for a specific restaurant (public endpoint)
+// @Description Get all menu items for a specific restaurant (public endpoint). Besides the flat list, items are grouped in the category tree; empty categories are left out.
 // @Tags public
 // @Accept json
 // @Produce json
 // @Param slug path string true "Restaurant slug"
 // @Param type query string false "Filter by type (DRINK or FOOD)"
 // @Param mealType query string false "Filter by meal type (LUNCH, DINNER, or BOTH)"
+// @Param diet query string false "Comma separated diet tags the items must all have, e.g. VEGAN,HALAL"
+// @Param excludeAllergens query string false "Comma separated allergens the items must not contain, e.g. NUTS,DAIRY"
+// @Param maxSpice query int false "Highest spice level, 0 (not spicy) to 3 (hot)"
+// @Param at query string false "Only items orderable at this time (RFC3339); every item's availability is reported for now otherwise"
+// @Param lang query string false "Locale to show text in, overriding Accept-Language: en, ne, ja or zh"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -418,30 +579,65 @@ func (h *MenuHandler) PublicGetMenus(c *gin.Context) {
 	if mealType != "" {
 		query = query.Where("meal_type = ?", mealType)
 	}
+	query, err := applyDietaryFilters(c, query)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	at, onlyAvailable, err := availabilityTime(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
 
 	var menus []db.Menu
-	if err := query.Order("created_at DESC").Find(&menus).Error; err != nil {
+	if err := withMenuOptions(query).Order("sort_order ASC, created_at DESC").Find(&menus).Error; err != nil {
 		c.JSON(500, gin.H{"error": "failed to fetch menus"})
 		return
 	}
+	locales := requestLocales(c)
+	for i := range menus {
+		i18n.LocalizeMenu(&menus[i], locales)
+	}
+
+	categories, err := h.categories(restaurant.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch categories"})
+		return
+	}
+	schedule, err := h.menuSchedule(restaurant, categories)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch availability"})
+		return
+	}
 
-	var response []MenuResponse
+	available := make(map[uuid.UUID]bool, len(menus))
+	shown := make([]db.Menu, 0, len(menus))
 	for _, menu := range menus {
-		response = append(response, MenuResponse{
-			ID:           menu.ID.String(),
-			RestaurantID: menu.RestaurantID.String(),
-			Name:         menu.Name,
-			ShortDesc:    menu.ShortDesc,
-			ImageURL:     menu.ImageURL,
-			Price:        menu.Price,
-			Type:         string(menu.Type),
-			MealType:     string(menu.MealType),
-			CreatedAt:    menu.CreatedAt,
-			UpdatedAt:    menu.UpdatedAt,
-		})
+		available[menu.ID] = schedule.Available(menu, at)
+		if available[menu.ID] || !onlyAvailable {
+			shown = append(shown, menu)
+		}
 	}
 
-	c.JSON(200, gin.H{"menus": response})
+	pricing := restaurantPricing(restaurant)
+	response := make([]MenuResponse, 0, len(shown))
+	for _, menu := range shown {
+		item := toMenuResponse(menu)
+		isAvailable := available[menu.ID]
+		item.Available = &isAvailable
+		item.CustomerPrice = toPriceDisplay(pricing, menu.Price, locales[0])
+		response = append(response, item)
+	}
+	tree, uncategorized := buildMenuTree(categories, shown, true)
+	markAvailable(tree, uncategorized, available)
+	markCustomerPrices(tree, uncategorized, pricing, locales[0])
+
+	c.JSON(200, gin.H{
+		"menus":         response,
+		"categories":    tree,
+		"uncategorized": uncategorized,
+	})
 }
 
 // PublicGetMenu godoc
@@ -452,6 +648,7 @@ func (h *MenuHandler) PublicGetMenus(c *gin.Context) {
 // @Produce json
 // @Param slug path string true "Restaurant slug"
 // @Param menuId path string true "Menu ID"
+// @Param lang query string false "Locale to show text in, overriding Accept-Language: en, ne, ja or zh"
 // @Success 200 {object} MenuResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -479,7 +676,7 @@ func (h *MenuHandler) PublicGetMenu(c *gin.Context) {
 	}
 
 	var menu db.Menu
-	if err := h.DB.Where("id = ? AND restaurant_id = ?", menuUUID, restaurant.ID).First(&menu).Error; err != nil {
+	if err := withMenuOptions(h.DB).Where("id = ? AND restaurant_id = ?", menuUUID, restaurant.ID).First(&menu).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(404, gin.H{"error": "menu not found"})
 			return
@@ -488,18 +685,23 @@ func (h *MenuHandler) PublicGetMenu(c *gin.Context) {
 		return
 	}
 
-	response := MenuResponse{
-		ID:           menu.ID.String(),
-		RestaurantID: menu.RestaurantID.String(),
-		Name:         menu.Name,
-		ShortDesc:    menu.ShortDesc,
-		ImageURL:     menu.ImageURL,
-		Price:        menu.Price,
-		Type:         string(menu.Type),
-		MealType:     string(menu.MealType),
-		CreatedAt:    menu.CreatedAt,
-		UpdatedAt:    menu.UpdatedAt,
+	categories, err := h.categories(restaurant.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch categories"})
+		return
 	}
+	schedule, err := h.menuSchedule(restaurant, categories)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch availability"})
+		return
+	}
+
+	locales := requestLocales(c)
+	i18n.LocalizeMenu(&menu, locales)
+	response := toMenuResponse(menu)
+	available := schedule.Available(menu, time.Now())
+	response.Available = &available
+	response.CustomerPrice = toPriceDisplay(restaurantPricing(restaurant), menu.Price, locales[0])
 
 	c.JSON(200, response)
 }
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/example/restosaas/apps/api/internal/auth"
@@ -12,14 +15,19 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type OAuthHandler struct{ DB *gorm.DB }
+type OAuthHandler struct {
+	DB *gorm.DB
+	// Links identities of OpenID Connect providers, when configured
+	OIDC *OIDCHandler
+}
 
 // OAuth request/response types
 type OAuthCallbackRequest struct {
 	Code  string `json:"code" binding:"required"`
-	State string `json:"state,omitempty"`
+	State string `json:"state" binding:"required"`
 }
 
 type OAuthTokenResponse struct {
@@ -28,6 +36,23 @@ type OAuthTokenResponse struct {
 	ExpiresIn   int    `json:"expires_in"`
 }
 
+// OAuthAuthorizeResponse is returned when the server issues a new state
+type OAuthAuthorizeResponse struct {
+	AuthorizationURL string    `json:"authorizationUrl"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
+// UserIdentityResponse represents a linked login provider
+type UserIdentityResponse struct {
+	Provider      string     `json:"provider"`
+	Email         string     `json:"email"`
+	EmailVerified bool       `json:"emailVerified"`
+	AvatarURL     string     `json:"avatarUrl"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	LastLoginAt   *time.Time `json:"lastLoginAt,omitempty"`
+}
+
 type GoogleUserInfo struct {
 	ID            string `json:"id"`
 	Email         string `json:"email"`
@@ -51,138 +76,389 @@ type FacebookUserInfo struct {
 
 type TwitterUserInfo struct {
 	ID              string `json:"id"`
-	Email           string `json:"email"`
+	Email           string `json:"confirmed_email"` // With the users.email scope`
 	Name            string `json:"name"`
 	Username        string `json:"username"`
 	ProfileImageURL string `json:"profile_image_url"`
 }
 
+// oauthProfile is the provider-independent view of an authenticated user
+type oauthProfile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+}
+
+// oauthProviderConfig describes how to start and complete a provider's flow
+type oauthProviderConfig struct {
+	AuthURL  string
+	TokenURL string
+	Scopes   []string
+	EnvKey   string // Prefix of the CLIENT_ID/CLIENT_SECRET/REDIRECT_URI variables
+	// Twitter expects client credentials as HTTP basic auth
+	BasicAuth bool
+}
+
+var oauthProviders = map[string]oauthProviderConfig{
+	string(db.OAuthGoogle): {
+		AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL: "https://oauth2.googleapis.com/token",
+		Scopes:   []string{"openid", "email", "profile"},
+		EnvKey:   "GOOGLE",
+	},
+	string(db.OAuthFacebook): {
+		AuthURL:  "https://www.facebook.com/v18.0/dialog/oauth",
+		TokenURL: "https://graph.facebook.com/v18.0/oauth/access_token",
+		Scopes:   []string{"email", "public_profile"},
+		EnvKey:   "FACEBOOK",
+	},
+	string(db.OAuthTwitter): {
+		AuthURL:   "https://twitter.com/i/oauth2/authorize",
+		TokenURL:  "https://api.twitter.com/2/oauth2/token",
+		Scopes:    []string{"tweet.read", "users.read", "users.email"},
+		EnvKey:    "TWITTER",
+		BasicAuth: true,
+	},
+}
+
+// oauthStateTTL bounds how long a user may take to complete the provider's
+// consent screen
+const oauthStateTTL = 10 * time.Minute
+
+var (
+	errOAuthState         = errors.New("invalid or expired state")
+	errOAuthLinkRequired  = errors.New("an account with this email already exists; sign in and link this provider from your profile")
+	errOAuthNoEmail       = errors.New("the provider did not share an email address")
+	errOAuthLinkedElse    = errors.New("this provider account is already linked to another user")
+	errOAuthAlreadyLinked = errors.New("a different account from this provider is already linked")
+)
+
+// Authorize godoc
+// @Summary Start an OAuth login
+// @Description Issue a single-use state and PKCE challenge and return the provider authorization URL
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider (google, facebook, twitter)"
+// @Param redirectUri query string false "Redirect URI registered with the provider"
+// @Success 200 {object} OAuthAuthorizeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/oauth/{provider}/authorize [get]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	h.authorize(c, nil)
+}
+
 // Google OAuth
 func (h *OAuthHandler) GoogleCallback(c *gin.Context) {
+	h.loginCallback(c, string(db.OAuthGoogle))
+}
+
+// Facebook OAuth
+func (h *OAuthHandler) FacebookCallback(c *gin.Context) {
+	h.loginCallback(c, string(db.OAuthFacebook))
+}
+
+// Twitter OAuth
+func (h *OAuthHandler) TwitterCallback(c *gin.Context) {
+	h.loginCallback(c, string(db.OAuthTwitter))
+}
+
+// ListIdentities godoc
+// @Summary List linked login providers
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /users/me/identities [get]
+func (h *OAuthHandler) ListIdentities(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var identities []db.UserIdentity
+	if err := h.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch identities"})
+		return
+	}
+
+	response := make([]UserIdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		response = append(response, toUserIdentityResponse(identity))
+	}
+
+	c.JSON(200, gin.H{"identities": response})
+}
+
+// AuthorizeLink godoc
+// @Summary Start linking a login provider
+// @Description Issue a state bound to the signed-in user for linking another provider
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider (google, facebook, twitter or an OpenID Connect provider name)"
+// @Param redirectUri query string false "Redirect URI registered with the provider"
+// @Success 200 {object} OAuthAuthorizeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /users/me/identities/{provider}/authorize [post]
+func (h *OAuthHandler) AuthorizeLink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if h.OIDC.handles(c.Param("provider")) {
+		if provider, ok := h.OIDC.provider(c); ok {
+			h.OIDC.authorize(c, provider, &userID)
+		}
+		return
+	}
+	h.authorize(c, &userID)
+}
+
+// LinkIdentity godoc
+// @Summary Link a login provider
+// @Description Complete a link flow started with AuthorizeLink and attach the provider account to the signed-in user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider (google, facebook, twitter or an OpenID Connect provider name)"
+// @Param callback body OAuthCallbackRequest true "Authorization code and state"
+// @Success 201 {object} UserIdentityResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /users/me/identities/{provider} [post]
+func (h *OAuthHandler) LinkIdentity(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	provider := c.Param("provider")
+
 	var req OAuthCallbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{"error": "Invalid request"})
 		return
 	}
 
-	// Exchange code for access token
-	token, err := h.exchangeGoogleCode(req.Code)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "Failed to exchange code for token"})
+	state, err := consumeOAuthState(h.DB, req.State, provider)
+	if err != nil || state.UserID == nil || *state.UserID != userID {
+		c.JSON(400, gin.H{"error": errOAuthState.Error()})
 		return
 	}
 
-	// Get user info from Google
-	userInfo, err := h.getGoogleUserInfo(token.AccessToken)
-	if err != nil {
+	var profile *oauthProfile
+	if h.OIDC.handles(provider) {
+		oidcProvider, ok := h.OIDC.provider(c)
+		if !ok {
+			return
+		}
+		if profile, ok = h.OIDC.fetchProfile(c, oidcProvider, req.Code, state); !ok {
+			return
+		}
+	} else if profile, err = h.fetchProfile(provider, req.Code, state); err != nil {
 		c.JSON(400, gin.H{"error": "Failed to get user info"})
 		return
 	}
 
-	// Create or update user
-	user, err := h.createOrUpdateOAuthUser(userInfo.Email, userInfo.Name, userInfo.Picture, string(db.OAuthGoogle), userInfo.ID)
+	var identity db.UserIdentity
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var existing db.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, profile.Subject).First(&existing).Error
+		if err == nil {
+			if existing.UserID != userID {
+				return errOAuthLinkedElse
+			}
+			identity = existing
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&db.UserIdentity{}).Where("user_id = ? AND provider = ?", userID, provider).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errOAuthAlreadyLinked
+		}
+
+		identity = newUserIdentity(userID, provider, profile)
+		return tx.Create(&identity).Error
+	})
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to create/update user"})
+		if err == errOAuthLinkedElse || err == errOAuthAlreadyLinked {
+			c.JSON(409, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": "failed to link identity"})
 		return
 	}
 
-	// Generate JWT token
-	jwtToken, err := auth.IssueToken(user.ID.String(), string(user.Role))
-	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to generate token"})
+	c.JSON(201, toUserIdentityResponse(identity))
+}
+
+// UnlinkIdentity godoc
+// @Summary Unlink a login provider
+// @Description Remove a linked provider. The last sign-in method of an account cannot be removed.
+// @Tags auth
+// @Param provider path string true "Provider (google, facebook, twitter)"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /users/me/identities/{provider} [delete]
+func (h *OAuthHandler) UnlinkIdentity(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
+	provider := c.Param("provider")
 
-	c.JSON(200, gin.H{
-		"user": gin.H{
-			"id":          user.ID.String(),
-			"email":       user.Email,
-			"displayName": user.DisplayName,
-			"role":        string(user.Role),
-			"avatarUrl":   user.AvatarURL,
-		},
-		"token": jwtToken,
-	})
+	var user db.User
+	if err := h.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(404, gin.H{"error": "user not found"})
+		return
+	}
+
+	var identities []db.UserIdentity
+	if err := h.DB.Where("user_id = ?", userID).Find(&identities).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch identities"})
+		return
+	}
+
+	var target *db.UserIdentity
+	for i := range identities {
+		if identities[i].Provider == provider {
+			target = &identities[i]
+		}
+	}
+	if target == nil {
+		c.JSON(404, gin.H{"error": "identity not found"})
+		return
+	}
+
+	// Keep at least one way to sign in
+	if user.Password == "" && len(identities) == 1 {
+		c.JSON(409, gin.H{"error": "cannot unlink the only sign-in method; set a password or link another provider first"})
+		return
+	}
+
+	if err := h.DB.Delete(target).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to unlink identity"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
-// Facebook OAuth
-func (h *OAuthHandler) FacebookCallback(c *gin.Context) {
-	var req OAuthCallbackRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid request"})
+// authorize issues a state for provider, bound to userID when linking
+func (h *OAuthHandler) authorize(c *gin.Context, userID *uuid.UUID) {
+	provider := c.Param("provider")
+	cfg, ok := oauthProviders[provider]
+	if !ok {
+		c.JSON(400, gin.H{"error": "unsupported provider"})
 		return
 	}
 
-	// Exchange code for access token
-	token, err := h.exchangeFacebookCode(req.Code)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "Failed to exchange code for token"})
+	clientID := os.Getenv(cfg.EnvKey + "_CLIENT_ID")
+	redirectURI := c.Query("redirectUri")
+	if redirectURI == "" {
+		redirectURI = os.Getenv(cfg.EnvKey + "_REDIRECT_URI")
+	}
+	if clientID == "" || redirectURI == "" {
+		c.JSON(400, gin.H{"error": "provider is not configured"})
 		return
 	}
 
-	// Get user info from Facebook
-	userInfo, err := h.getFacebookUserInfo(token.AccessToken)
+	stateValue, err := auth.RandomToken(32)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Failed to get user info"})
+		c.JSON(500, gin.H{"error": "failed to create state"})
 		return
 	}
-
-	// Create or update user
-	user, err := h.createOrUpdateOAuthUser(userInfo.Email, userInfo.Name, userInfo.Picture.Data.URL, string(db.OAuthFacebook), userInfo.ID)
+	verifier, challenge, err := auth.NewPKCE()
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to create/update user"})
+		c.JSON(500, gin.H{"error": "failed to create state"})
 		return
 	}
 
-	// Generate JWT token
-	jwtToken, err := auth.IssueToken(user.ID.String(), string(user.Role))
-	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to generate token"})
+	state := db.OAuthState{
+		ID:           uuid.New(),
+		State:        stateValue,
+		Provider:     provider,
+		CodeVerifier: verifier,
+		RedirectURI:  redirectURI,
+		UserID:       userID,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+		CreatedAt:    time.Now(),
+	}
+
+	// Opportunistically drop abandoned states
+	h.DB.Where("expires_at < ?", time.Now()).Delete(&db.OAuthState{})
+
+	if err := h.DB.Create(&state).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to create state"})
 		return
 	}
 
-	c.JSON(200, gin.H{
-		"user": gin.H{
-			"id":          user.ID.String(),
-			"email":       user.Email,
-			"displayName": user.DisplayName,
-			"role":        string(user.Role),
-			"avatarUrl":   user.AvatarURL,
-		},
-		"token": jwtToken,
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("response_type", "code")
+	params.Set("scope", strings.Join(cfg.Scopes, " "))
+	params.Set("state", stateValue)
+	params.Set("code_challenge", challenge)
+	params.Set("code_challenge_method", "S256")
+
+	c.JSON(200, OAuthAuthorizeResponse{
+		AuthorizationURL: cfg.AuthURL + "?" + params.Encode(),
+		State:            stateValue,
+		ExpiresAt:        state.ExpiresAt,
 	})
 }
 
-// Twitter OAuth
-func (h *OAuthHandler) TwitterCallback(c *gin.Context) {
+// loginCallback completes a sign-in flow for provider
+func (h *OAuthHandler) loginCallback(c *gin.Context, provider string) {
 	var req OAuthCallbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{"error": "Invalid request"})
 		return
 	}
 
-	// Exchange code for access token
-	token, err := h.exchangeTwitterCode(req.Code)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "Failed to exchange code for token"})
+	state, err := consumeOAuthState(h.DB, req.State, provider)
+	if err != nil || state.UserID != nil {
+		c.JSON(400, gin.H{"error": errOAuthState.Error()})
 		return
 	}
 
-	// Get user info from Twitter
-	userInfo, err := h.getTwitterUserInfo(token.AccessToken)
+	profile, err := h.fetchProfile(provider, req.Code, state)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "Failed to get user info"})
 		return
 	}
 
-	// Create or update user
-	user, err := h.createOrUpdateOAuthUser(userInfo.Email, userInfo.Name, userInfo.ProfileImageURL, string(db.OAuthTwitter), userInfo.ID)
+	completeOAuthLogin(c, h.DB, provider, profile)
+}
+
+// completeOAuthLogin resolves the user for an external login and responds
+// with a JWT in the OAuth login response shape
+func completeOAuthLogin(c *gin.Context, gdb *gorm.DB, provider string, profile *oauthProfile) {
+	user, err := resolveOAuthUser(gdb, provider, profile)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to create/update user"})
+		switch err {
+		case errOAuthLinkRequired:
+			c.JSON(409, gin.H{"error": err.Error(), "code": "link_required"})
+		case errOAuthNoEmail:
+			c.JSON(400, gin.H{"error": err.Error()})
+		default:
+			c.JSON(500, gin.H{"error": "Failed to create/update user"})
+		}
 		return
 	}
 
-	// Generate JWT token
 	jwtToken, err := auth.IssueToken(user.ID.String(), string(user.Role))
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to generate token"})
@@ -201,70 +477,106 @@ func (h *OAuthHandler) TwitterCallback(c *gin.Context) {
 	})
 }
 
-// Helper functions
-func (h *OAuthHandler) exchangeGoogleCode(code string) (*OAuthTokenResponse, error) {
-	clientID := os.Getenv("GOOGLE_CLIENT_ID")
-	clientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
-	redirectURI := os.Getenv("GOOGLE_REDIRECT_URI")
+// consumeOAuthState atomically deletes and returns an unexpired state so it can
+// be used at most once
+func consumeOAuthState(gdb *gorm.DB, value, provider string) (*db.OAuthState, error) {
+	var states []db.OAuthState
+	result := gdb.Clauses(clause.Returning{}).
+		Where("state = ? AND provider = ?", value, provider).
+		Delete(&states)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(states) == 0 || time.Now().After(states[0].ExpiresAt) {
+		return nil, errOAuthState
+	}
+	return &states[0], nil
+}
 
-	url := fmt.Sprintf("https://oauth2.googleapis.com/token?client_id=%s&client_secret=%s&code=%s&grant_type=authorization_code&redirect_uri=%s",
-		clientID, clientSecret, code, redirectURI)
+// fetchProfile exchanges the code and loads the provider's user profile
+func (h *OAuthHandler) fetchProfile(provider, code string, state *db.OAuthState) (*oauthProfile, error) {
+	cfg, ok := oauthProviders[provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider %q", provider)
+	}
 
-	resp, err := http.Post(url, "application/x-www-form-urlencoded", nil)
+	token, err := h.exchangeCode(cfg, code, state.CodeVerifier, state.RedirectURI)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var tokenResp OAuthTokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return nil, err
+	switch provider {
+	case string(db.OAuthGoogle):
+		info, err := h.getGoogleUserInfo(token.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		return &oauthProfile{Subject: info.ID, Email: info.Email, EmailVerified: info.VerifiedEmail, Name: info.Name, AvatarURL: info.Picture}, nil
+	case string(db.OAuthFacebook):
+		info, err := h.getFacebookUserInfo(token.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		// Facebook does not report whether the email was verified
+		return &oauthProfile{Subject: info.ID, Email: info.Email, Name: info.Name, AvatarURL: info.Picture.Data.URL}, nil
+	case string(db.OAuthTwitter):
+		info, err := h.getTwitterUserInfo(token.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		// Twitter only shares an email address once it has been confirmed
+		return &oauthProfile{Subject: info.ID, Email: info.Email, EmailVerified: info.Email != "", Name: info.Name, AvatarURL: info.ProfileImageURL}, nil
 	}
-
-	return &tokenResp, nil
+	return nil, fmt.Errorf("unsupported provider %q", provider)
 }
 
-func (h *OAuthHandler) getGoogleUserInfo(accessToken string) (*GoogleUserInfo, error) {
-	url := fmt.Sprintf("https://www.googleapis.com/oauth2/v2/userinfo?access_token=%s", accessToken)
+// Helper functions
+func (h *OAuthHandler) exchangeCode(cfg oauthProviderConfig, code, verifier, redirectURI string) (*OAuthTokenResponse, error) {
+	clientID := os.Getenv(cfg.EnvKey + "_CLIENT_ID")
+	clientSecret := os.Getenv(cfg.EnvKey + "_CLIENT_SECRET")
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", clientID)
+	if !cfg.BasicAuth {
+		form.Set("client_secret", clientSecret)
+	}
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequest("POST", cfg.TokenURL, strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	var userInfo GoogleUserInfo
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-		return nil, err
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cfg.BasicAuth {
+		req.SetBasicAuth(clientID, clientSecret)
 	}
 
-	return &userInfo, nil
-}
-
-func (h *OAuthHandler) exchangeFacebookCode(code string) (*OAuthTokenResponse, error) {
-	clientID := os.Getenv("FACEBOOK_CLIENT_ID")
-	clientSecret := os.Getenv("FACEBOOK_CLIENT_SECRET")
-	redirectURI := os.Getenv("FACEBOOK_REDIRECT_URI")
-
-	url := fmt.Sprintf("https://graph.facebook.com/v18.0/oauth/access_token?client_id=%s&client_secret=%s&code=%s&redirect_uri=%s",
-		clientID, clientSecret, code, redirectURI)
-
-	resp, err := http.Post(url, "application/x-www-form-urlencoded", nil)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
 	var tokenResp OAuthTokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
 		return nil, err
 	}
+	if tokenResp.AccessToken == "" {
+		return nil, errors.New("token endpoint returned no access token")
+	}
 
 	return &tokenResp, nil
 }
 
-func (h *OAuthHandler) getFacebookUserInfo(accessToken string) (*FacebookUserInfo, error) {
-	url := fmt.Sprintf("https://graph.facebook.com/v18.0/me?fields=id,name,email,picture&access_token=%s", accessToken)
+func (h *OAuthHandler) getGoogleUserInfo(accessToken string) (*GoogleUserInfo, error) {
+	url := fmt.Sprintf("https://www.googleapis.com/oauth2/v2/userinfo?access_token=%s", accessToken)
 
 	resp, err := http.Get(url)
 	if err != nil {
@@ -272,7 +584,7 @@ func (h *OAuthHandler) getFacebookUserInfo(accessToken string) (*FacebookUserInf
 	}
 	defer resp.Body.Close()
 
-	var userInfo FacebookUserInfo
+	var userInfo GoogleUserInfo
 	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
 		return nil, err
 	}
@@ -280,32 +592,25 @@ func (h *OAuthHandler) getFacebookUserInfo(accessToken string) (*FacebookUserInf
 	return &userInfo, nil
 }
 
-func (h *OAuthHandler) exchangeTwitterCode(code string) (*OAuthTokenResponse, error) {
-	clientID := os.Getenv("TWITTER_CLIENT_ID")
-	clientSecret := os.Getenv("TWITTER_CLIENT_SECRET")
-	redirectURI := os.Getenv("TWITTER_REDIRECT_URI")
-
-	// Twitter uses OAuth 2.0 PKCE flow
-	// This is a simplified implementation - in production, you'd need to implement PKCE
-	url := fmt.Sprintf("https://api.twitter.com/2/oauth2/token?client_id=%s&client_secret=%s&code=%s&grant_type=authorization_code&redirect_uri=%s",
-		clientID, clientSecret, code, redirectURI)
+func (h *OAuthHandler) getFacebookUserInfo(accessToken string) (*FacebookUserInfo, error) {
+	url := fmt.Sprintf("https://graph.facebook.com/v18.0/me?fields=id,name,email,picture&access_token=%s", accessToken)
 
-	resp, err := http.Post(url, "application/x-www-form-urlencoded", nil)
+	resp, err := http.Get(url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var tokenResp OAuthTokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+	var userInfo FacebookUserInfo
+	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
 		return nil, err
 	}
 
-	return &tokenResp, nil
+	return &userInfo, nil
 }
 
 func (h *OAuthHandler) getTwitterUserInfo(accessToken string) (*TwitterUserInfo, error) {
-	url := "https://api.twitter.com/2/users/me?user.fields=profile_image_url"
+	url := "https://api.twitter.com/2/users/me?user.fields=profile_image_url,confirmed_email"
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -330,40 +635,115 @@ func (h *OAuthHandler) getTwitterUserInfo(accessToken string) (*TwitterUserInfo,
 	return &response.Data, nil
 }
 
-func (h *OAuthHandler) createOrUpdateOAuthUser(email, displayName, avatarURL, provider, oauthID string) (*db.User, error) {
+// resolveOAuthUser finds the user for a provider login, creating one when
+// neither the identity nor the email is known. An existing account is only
+// linked automatically when the provider verified the email and the account
+// is a password-less customer; anything else must be linked explicitly by
+// the signed-in account holder.
+func resolveOAuthUser(gdb *gorm.DB, provider string, profile *oauthProfile) (*db.User, error) {
+	if profile.Subject == "" {
+		return nil, errors.New("provider returned no subject")
+	}
+
 	var user db.User
+	err := gdb.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Known identity: sign in its user
+		var identity db.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, profile.Subject).First(&identity).Error
+		if err == nil {
+			if err := tx.Where("id = ?", identity.UserID).First(&user).Error; err != nil {
+				return err
+			}
+			identity.Email = profile.Email
+			identity.EmailVerified = profile.EmailVerified
+			identity.AvatarURL = profile.AvatarURL
+			identity.LastLoginAt = &now
+			if err := tx.Save(&identity).Error; err != nil {
+				return err
+			}
+			if user.AvatarURL == "" && profile.AvatarURL != "" {
+				user.AvatarURL = profile.AvatarURL
+				return tx.Model(&user).Update("avatar_url", user.AvatarURL).Error
+			}
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
 
-	// Check if user exists by email
-	err := h.DB.Where("email = ?", email).First(&user).Error
-	if err == gorm.ErrRecordNotFound {
-		// Create new user
-		user = db.User{
-			ID:            uuid.New(),
-			Email:         email,
-			DisplayName:   displayName,
-			Role:          db.RoleCustomer, // Default role for OAuth users
-			OAuthProvider: provider,
-			OAuthID:       oauthID,
-			AvatarURL:     avatarURL,
-			CreatedAt:     time.Now(),
+		if profile.Email == "" {
+			return errOAuthNoEmail
 		}
 
-		if err := h.DB.Create(&user).Error; err != nil {
-			return nil, err
+		// Existing account with the same email
+		err = tx.Where("email = ?", profile.Email).First(&user).Error
+		if err == nil {
+			if !profile.EmailVerified || user.Password != "" || user.Role != db.RoleCustomer {
+				return errOAuthLinkRequired
+			}
+			identity = newUserIdentity(user.ID, provider, profile)
+			identity.LastLoginAt = &now
+			return tx.Create(&identity).Error
 		}
-	} else if err != nil {
-		return nil, err
-	} else {
-		// Update existing user with OAuth info
-		user.OAuthProvider = provider
-		user.OAuthID = oauthID
-		user.AvatarURL = avatarURL
-		user.DisplayName = displayName
-
-		if err := h.DB.Save(&user).Error; err != nil {
-			return nil, err
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		// New customer account
+		user = db.User{
+			ID:          uuid.New(),
+			Email:       profile.Email,
+			DisplayName: profile.Name,
+			Role:        db.RoleCustomer, // Default role for OAuth users
+			AvatarURL:   profile.AvatarURL,
+			CreatedAt:   now,
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
 		}
+		identity = newUserIdentity(user.ID, provider, profile)
+		identity.LastLoginAt = &now
+		return tx.Create(&identity).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &user, nil
 }
+
+func newUserIdentity(userID uuid.UUID, provider string, profile *oauthProfile) db.UserIdentity {
+	return db.UserIdentity{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Provider:      provider,
+		Subject:       profile.Subject,
+		Email:         profile.Email,
+		EmailVerified: profile.EmailVerified,
+		AvatarURL:     profile.AvatarURL,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func toUserIdentityResponse(identity db.UserIdentity) UserIdentityResponse {
+	return UserIdentityResponse{
+		Provider:      identity.Provider,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		AvatarURL:     identity.AvatarURL,
+		CreatedAt:     identity.CreatedAt,
+		LastLoginAt:   identity.LastLoginAt,
+	}
+}
+
+// currentUserID reads the authenticated user's ID, writing a 401 when absent
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("uid"))
+	if err != nil {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/auth"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OIDCHandler signs users in with configured OpenID Connect providers
+type OIDCHandler struct {
+	DB        *gorm.DB
+	Providers *oidc.Registry
+}
+
+// OIDCProviderResponse describes a provider for login buttons
+type OIDCProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// ListProviders godoc
+// @Summary List OpenID Connect providers
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /auth/oidc/providers [get]
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	providers := []OIDCProviderResponse{}
+	if h.Providers != nil {
+		for _, cfg := range h.Providers.Configs() {
+			if _, builtin := oauthProviders[cfg.Name]; builtin {
+				continue
+			}
+			name := cfg.DisplayName
+			if name == "" {
+				name = cfg.Name
+			}
+			providers = append(providers, OIDCProviderResponse{Name: cfg.Name, DisplayName: name})
+		}
+	}
+	c.JSON(200, gin.H{"providers": providers})
+}
+
+// Authorize godoc
+// @Summary Start an OpenID Connect login
+// @Description Issue a single-use state, nonce and PKCE challenge and return the provider authorization URL
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param redirectUri query string false "Redirect URI registered with the provider"
+// @Success 200 {object} OAuthAuthorizeResponse
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /auth/oidc/{provider}/authorize [get]
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+	h.authorize(c, provider, nil)
+}
+
+// Callback godoc
+// @Summary Complete an OpenID Connect login
+// @Description Exchange the authorization code, verify the ID token and sign the user in
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param callback body OAuthCallbackRequest true "Authorization code and state"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /auth/oidc/{provider}/callback [post]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	state, err := consumeOAuthState(h.DB, req.State, provider.Config.Name)
+	if err != nil || state.UserID != nil {
+		c.JSON(400, gin.H{"error": errOAuthState.Error()})
+		return
+	}
+
+	profile, ok := h.fetchProfile(c, provider, req.Code, state)
+	if !ok {
+		return
+	}
+
+	completeOAuthLogin(c, h.DB, provider.Config.Name, profile)
+}
+
+// authorize issues a state and nonce for provider, bound to userID when
+// linking
+func (h *OIDCHandler) authorize(c *gin.Context, provider *oidc.Provider, userID *uuid.UUID) {
+	redirectURI := c.Query("redirectUri")
+	if redirectURI == "" {
+		redirectURI = provider.Config.RedirectURL
+	}
+	if redirectURI == "" {
+		c.JSON(400, gin.H{"error": "redirectUri is required"})
+		return
+	}
+
+	stateValue, err := auth.RandomToken(32)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create state"})
+		return
+	}
+	nonce, err := auth.RandomToken(32)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create state"})
+		return
+	}
+	verifier, challenge, err := auth.NewPKCE()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to create state"})
+		return
+	}
+
+	state := db.OAuthState{
+		ID:           uuid.New(),
+		State:        stateValue,
+		Provider:     provider.Config.Name,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		RedirectURI:  redirectURI,
+		UserID:       userID,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+		CreatedAt:    time.Now(),
+	}
+
+	h.DB.Where("expires_at < ?", time.Now()).Delete(&db.OAuthState{})
+
+	if err := h.DB.Create(&state).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to create state"})
+		return
+	}
+
+	c.JSON(200, OAuthAuthorizeResponse{
+		AuthorizationURL: provider.AuthCodeURL(stateValue, nonce, challenge, redirectURI),
+		State:            stateValue,
+		ExpiresAt:        state.ExpiresAt,
+	})
+}
+
+// fetchProfile exchanges the code of a consumed state, verifies the ID token
+// and loads the user's profile, writing an error when it fails
+func (h *OIDCHandler) fetchProfile(c *gin.Context, provider *oidc.Provider, code string, state *db.OAuthState) (*oauthProfile, bool) {
+	if state.Nonce == "" {
+		c.JSON(400, gin.H{"error": errOAuthState.Error()})
+		return nil, false
+	}
+
+	ctx := c.Request.Context()
+	token, err := provider.Exchange(ctx, code, state.CodeVerifier, state.RedirectURI)
+	if err != nil {
+		log.Printf("oidc %s: %v", provider.Config.Name, err)
+		c.JSON(400, gin.H{"error": "failed to exchange authorization code"})
+		return nil, false
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		log.Printf("oidc %s: %v", provider.Config.Name, err)
+		c.JSON(400, gin.H{"error": "invalid id token"})
+		return nil, false
+	}
+
+	profile := &oauthProfile{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		AvatarURL:     claims.Picture,
+	}
+
+	// Some providers only return profile claims from userinfo
+	if profile.Email == "" && token.AccessToken != "" && provider.Discovery.UserinfoEndpoint != "" {
+		if info, err := provider.UserInfo(ctx, token.AccessToken, claims.Subject); err == nil {
+			profile.Email = info.Email
+			profile.EmailVerified = info.EmailVerified
+			if profile.Name == "" {
+				profile.Name = info.Name
+			}
+			if profile.AvatarURL == "" {
+				profile.AvatarURL = info.Picture
+			}
+		}
+	}
+	return profile, true
+}
+
+// handles reports whether name is a configured OpenID Connect provider
+// rather than a built-in one
+func (h *OIDCHandler) handles(name string) bool {
+	if _, builtin := oauthProviders[name]; builtin {
+		return false
+	}
+	return h != nil && h.Providers != nil && h.Providers.Has(name)
+}
+
+// provider resolves the :provider parameter, writing an error when it is
+// unknown or cannot be discovered
+func (h *OIDCHandler) provider(c *gin.Context) (*oidc.Provider, bool) {
+	name := c.Param("provider")
+	// Built-in providers keep their own flows and identity namespace
+	if _, builtin := oauthProviders[name]; builtin || h.Providers == nil {
+		c.JSON(404, gin.H{"error": "unknown provider"})
+		return nil, false
+	}
+
+	provider, err := h.Providers.Provider(c.Request.Context(), name)
+	if err != nil {
+		if errors.Is(err, oidc.ErrUnknownProvider) {
+			c.JSON(404, gin.H{"error": "unknown provider"})
+			return nil, false
+		}
+		log.Printf("oidc %s: %v", name, err)
+		c.JSON(502, gin.H{"error": "identity provider is unavailable"})
+		return nil, false
+	}
+	return provider, true
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/oidc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCHandles(t *testing.T) {
+	h := &OIDCHandler{Providers: oidc.NewRegistry([]oidc.ProviderConfig{
+		{Name: "microsoft", Issuer: "https://login.example.com", ClientID: "id"},
+		{Name: "google", Issuer: "https://accounts.google.com", ClientID: "id"},
+	}, nil)}
+
+	// Configured providers are linked through OpenID Connect
+	assert.True(t, h.handles("microsoft"))
+	// Built-in providers keep their own flows, even when configured
+	assert.False(t, h.handles("google"))
+	assert.False(t, h.handles("okta"))
+
+	var none *OIDCHandler
+	assert.False(t, none.handles("microsoft"))
+}
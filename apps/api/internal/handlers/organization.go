@@ -3,8 +3,11 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
 	"github.com/example/restosaas/apps/api/internal/db"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -36,6 +39,9 @@ type AssignOwnerRequest struct {
 
 type AssignMultipleUsersRequest struct {
 	UserIDs []string `json:"userIds" binding:"required"`
+	// Role within the organization (OWNER, MANAGER, HOST, KITCHEN, MARKETING).
+	// Defaults to OWNER for owner accounts and HOST for everyone else.
+	Role string `json:"role"`
 }
 
 // CreateOrganization godoc
@@ -282,7 +288,19 @@ func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
 	}
 
 	// Delete organization (cascade will handle restaurants)
-	if err := h.DB.Delete(&org).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&org).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionOrganizationDelete,
+			EntityType: "organization",
+			EntityID:   org.ID.String(),
+			OrgID:      &org.ID,
+			Before:     org,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to delete organization"})
 		return
 	}
@@ -345,22 +363,29 @@ func (h *OrganizationHandler) AssignOwner(c *gin.Context) {
 		return
 	}
 
-	// Remove owner from any other organization first (allow reassignment)
-	if err := h.DB.Where("user_id = ?", ownerID).Delete(&db.OrgMember{}).Error; err != nil {
-		c.JSON(500, gin.H{"error": "failed to remove existing memberships"})
-		return
-	}
-
-	// Create new organization membership
-	member := db.OrgMember{
-		ID:     uuid.New(),
-		UserID: ownerID,
-		OrgID:  orgID,
-		Role:   db.RoleOwner,
-	}
-
-	if err := h.DB.Create(&member).Error; err != nil {
-		c.JSON(500, gin.H{"error": "failed to assign owner to organization"})
+	// Owners may belong to several organizations, so keep their other
+	// memberships and only add or promote the one for this organization
+	var member db.OrgMember
+	err = h.DB.Where("user_id = ? AND org_id = ?", ownerID, orgID).First(&member).Error
+	switch {
+	case err == nil:
+		if err := h.DB.Model(&member).Update("role", db.OrgRoleOwner).Error; err != nil {
+			c.JSON(500, gin.H{"error": "failed to assign owner to organization"})
+			return
+		}
+	case err == gorm.ErrRecordNotFound:
+		member = db.OrgMember{
+			ID:     uuid.New(),
+			UserID: ownerID,
+			OrgID:  orgID,
+			Role:   db.OrgRoleOwner,
+		}
+		if err := h.DB.Create(&member).Error; err != nil {
+			c.JSON(500, gin.H{"error": "failed to assign owner to organization"})
+			return
+		}
+	default:
+		c.JSON(500, gin.H{"error": "failed to fetch existing membership"})
 		return
 	}
 
@@ -427,6 +452,12 @@ func (h *OrganizationHandler) AssignMultipleUsers(c *gin.Context) {
 		return
 	}
 
+	role := db.OrgRole(strings.ToUpper(req.Role))
+	if req.Role != "" && !role.Valid() {
+		c.JSON(400, gin.H{"error": "invalid role: " + req.Role})
+		return
+	}
+
 	// Remove existing memberships for this organization
 	if err := h.DB.Where("org_id = ?", orgUUID).Delete(&db.OrgMember{}).Error; err != nil {
 		c.JSON(500, gin.H{"error": "failed to remove existing memberships"})
@@ -440,7 +471,10 @@ func (h *OrganizationHandler) AssignMultipleUsers(c *gin.Context) {
 			ID:     uuid.New(),
 			UserID: user.ID,
 			OrgID:  orgUUID,
-			Role:   user.Role, // Use the user's role
+			Role:   role,
+		}
+		if req.Role == "" {
+			orgMember.Role = defaultOrgRole(user.Role)
 		}
 		orgMembers = append(orgMembers, orgMember)
 	}
@@ -658,3 +692,72 @@ func (h *OrganizationHandler) UpdateMyOrganization(c *gin.Context) {
 
 	c.JSON(200, response)
 }
+
+// defaultOrgRole maps a user's global role to the organization role used
+// when none is given
+func defaultOrgRole(role db.Role) db.OrgRole {
+	if role == db.RoleOwner || role == db.RoleSuper {
+		return db.OrgRoleOwner
+	}
+	return db.OrgRoleHost
+}
+
+// MyOrganizationResponse is an organization the caller belongs to
+type MyOrganizationResponse struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	SubscriptionStatus string   `json:"subscriptionStatus"`
+	Role               string   `json:"role"`
+	Permissions        []string `json:"permissions"`
+}
+
+// ListMyOrganizations godoc
+// @Summary List my organizations
+// @Description List the organizations the caller belongs to, with their role and permissions in each
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/organizations [get]
+func (h *OrganizationHandler) ListMyOrganizations(c *gin.Context) {
+	userID, exists := c.Get("uid")
+	if !exists {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var rows []struct {
+		ID                 uuid.UUID
+		Name               string
+		SubscriptionStatus string
+		Role               db.OrgRole
+	}
+	if err := h.DB.Table("organizations").
+		Select("organizations.id, organizations.name, organizations.subscription_status, org_members.role").
+		Joins("JOIN org_members ON org_members.org_id = organizations.id").
+		Where("org_members.user_id = ?", userID).
+		Order("organizations.name ASC").
+		Scan(&rows).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch organizations"})
+		return
+	}
+
+	organizations := make([]MyOrganizationResponse, 0, len(rows))
+	for _, row := range rows {
+		perms := authz.Permissions(row.Role)
+		names := make([]string, 0, len(perms))
+		for _, p := range perms {
+			names = append(names, string(p))
+		}
+		organizations = append(organizations, MyOrganizationResponse{
+			ID:                 row.ID.String(),
+			Name:               row.Name,
+			SubscriptionStatus: row.SubscriptionStatus,
+			Role:               string(row.Role),
+			Permissions:        names,
+		})
+	}
+
+	c.JSON(200, gin.H{"organizations": organizations})
+}
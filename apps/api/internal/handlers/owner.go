@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
 	"github.com/example/restosaas/apps/api/internal/db"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -30,23 +32,11 @@ func (h *OwnerHandler) UpdateRestaurant(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "Restaurant updated successfully"})
 }
 
+// GET /api/owner/restaurants/:id/reservations - List a restaurant's reservations (reservations:read)
 func (h *OwnerHandler) ListReservations(c *gin.Context) {
-	uid := c.GetString("uid")
-	if uid == "" {
-		c.JSON(400, gin.H{"error": "missing user ID"})
-		return
-	}
-	var member db.OrgMember
-	if err := h.DB.Where("user_id = ?", uid).First(&member).Error; err != nil {
-		c.JSON(404, gin.H{"error": "organization member not found"})
-		return
-	}
-	var r db.Restaurant
-	if err := h.DB.Where("org_id = ?", member.OrgID).First(&r).Error; err != nil {
-		c.JSON(404, gin.H{"error": "restaurant not found"})
-		return
-	}
-	
+	// Loaded and authorized by authz.RestaurantScope
+	r := authz.ScopedRestaurant(c)
+
 	// Parse pagination parameters
 	page := 1
 	limit := 10
@@ -99,29 +89,19 @@ func (h *OwnerHandler) ListReservations(c *gin.Context) {
 	})
 }
 
+// POST /api/owner/restaurants/:id/reviews/:reviewId/approve - Approve a review (reviews:moderate)
 func (h *OwnerHandler) ApproveReview(c *gin.Context) {
-	// Check authentication
-	uid := c.GetString("uid")
-	if uid == "" {
-		c.JSON(400, gin.H{"error": "missing user ID"})
-		return
-	}
-	
-	id := c.Param("id")
-	if id == "" {
-		c.JSON(400, gin.H{"error": "invalid review ID"})
-		return
-	}
-	
-	// Validate UUID format
+	restaurant := authz.ScopedRestaurant(c)
+
+	id := c.Param("reviewId")
 	if _, err := uuid.Parse(id); err != nil {
 		c.JSON(400, gin.H{"error": "invalid review ID format"})
 		return
 	}
-	
-	// First check if the review exists
+
+	// The review must belong to the authorized restaurant
 	var review db.Review
-	if err := h.DB.Where("id = ?", id).First(&review).Error; err != nil {
+	if err := h.DB.Where("id = ? AND restaurant_id = ?", id, restaurant.ID).First(&review).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(404, gin.H{"error": "review not found"})
 			return
@@ -129,9 +109,23 @@ func (h *OwnerHandler) ApproveReview(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
-	
+
 	// Update the review
-	if err := h.DB.Model(&review).Update("is_approved", true).Error; err != nil {
+	before := review
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&review).Update("is_approved", true).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionReviewApprove,
+			EntityType: "review",
+			EntityID:   review.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      review,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
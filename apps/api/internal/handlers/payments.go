@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"github.com/example/restosaas/apps/api/internal/audit"
 	"github.com/example/restosaas/apps/api/internal/db"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -23,15 +24,33 @@ func (h *PaymentHandler) ActivateSubscription(c *gin.Context) {
 		return
 	}
 
-	result := h.DB.Model(&db.Organization{}).Where("id = ?", orgID).Update("subscription_status", "ACTIVE")
-	if err := result.Error; err != nil {
+	var org db.Organization
+	if err := h.DB.Where("id = ?", orgID).First(&org).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(404, gin.H{"error": "organization not found"})
+			return
+		}
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Check if any records were affected
-	if result.RowsAffected == 0 {
-		c.JSON(404, gin.H{"error": "organization not found"})
+	before := org
+	org.SubscriptionStatus = "ACTIVE"
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&db.Organization{}).Where("id = ?", org.ID).Update("subscription_status", org.SubscriptionStatus).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionSubscriptionActivate,
+			EntityType: "organization",
+			EntityID:   org.ID.String(),
+			OrgID:      &org.ID,
+			Before:     before,
+			After:      org,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
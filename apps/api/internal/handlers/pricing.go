@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/budget"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RestaurantPricing is the currency of a restaurant's prices and what it
+// charges on top of them. Rates are in basis points, 1300 = 13%.
+type RestaurantPricing struct {
+	Currency         string `json:"currency"`
+	TaxRate          int    `json:"taxRate"`
+	ServiceCharge    int    `json:"serviceCharge"`
+	PricesIncludeTax bool   `json:"pricesIncludeTax"` // Menu prices already include tax and service charge
+}
+
+// UpdateRestaurantPricingRequest changes the fields that are present.
+// Changing the currency keeps the face value of existing prices, so
+// Rs 250.00 becomes ¥250.
+type UpdateRestaurantPricingRequest struct {
+	Currency         *string `json:"currency,omitempty"`
+	TaxRate          *int    `json:"taxRate,omitempty" binding:"omitempty,min=0,max=10000"`
+	ServiceCharge    *int    `json:"serviceCharge,omitempty" binding:"omitempty,min=0,max=10000"`
+	PricesIncludeTax *bool   `json:"pricesIncludeTax,omitempty"`
+}
+
+func toRestaurantPricing(restaurant db.Restaurant) RestaurantPricing {
+	return RestaurantPricing{
+		Currency:         restaurantCurrency(restaurant),
+		TaxRate:          restaurant.TaxRate,
+		ServiceCharge:    restaurant.ServiceCharge,
+		PricesIncludeTax: restaurant.PricesIncludeTax,
+	}
+}
+
+// GET /api/owner/restaurants/:id/pricing - Get currency, tax and service charge (restaurant:read)
+func (h *RestaurantHandler) GetRestaurantPricing(c *gin.Context) {
+	c.JSON(200, toRestaurantPricing(*authz.ScopedRestaurant(c)))
+}
+
+// PUT /api/owner/restaurants/:id/pricing - Set currency, tax and service charge (restaurant:write)
+func (h *RestaurantHandler) UpdateRestaurantPricing(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	var req UpdateRestaurantPricingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := toRestaurantPricing(*restaurant)
+	restaurant.Currency = before.Currency
+	if req.Currency != nil {
+		currency, err := money.ParseCurrency(*req.Currency)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		restaurant.Currency = currency
+		// The budget keeps its face value, like menu prices
+		restaurant.BudgetMin = restaurant.BudgetMin.Rescale(currency)
+		restaurant.BudgetMax = restaurant.BudgetMax.Rescale(currency)
+		restaurant.PriceLevel = budget.Level(budget.Range{Min: restaurant.BudgetMin, Max: restaurant.BudgetMax})
+	}
+	if req.TaxRate != nil {
+		restaurant.TaxRate = *req.TaxRate
+	}
+	if req.ServiceCharge != nil {
+		restaurant.ServiceCharge = *req.ServiceCharge
+	}
+	if req.PricesIncludeTax != nil {
+		restaurant.PricesIncludeTax = *req.PricesIncludeTax
+	}
+	restaurant.UpdatedAt = time.Now()
+	after := toRestaurantPricing(*restaurant)
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if after.Currency != before.Currency {
+			if err := rescalePrices(tx, restaurant.ID, before.Currency, after.Currency); err != nil {
+				return err
+			}
+		}
+		err := tx.Model(restaurant).
+			Select("currency", "tax_rate", "service_charge", "prices_include_tax", "budget_min_amount", "budget_min_currency", "budget_max_amount", "budget_max_currency", "price_level", "updated_at").
+			Updates(restaurant).Error
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantPricing,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      after,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update pricing"})
+		return
+	}
+
+	c.JSON(200, after)
+}
+
+// rescalePrices moves every price of the restaurant's menus and courses to
+// another currency, keeping their face value (see money.Money.Rescale)
+func rescalePrices(tx *gorm.DB, restaurantID uuid.UUID, from, to string) error {
+	factor := strconv.FormatFloat(math.Pow10(money.Exponent(to)-money.Exponent(from)), 'f', -1, 64)
+	prices := []struct {
+		model  interface{}
+		column string
+		where  string
+	}{
+		{&db.Menu{}, "price", "restaurant_id = ?"},
+		{&db.MenuVariant{}, "price", "menu_id IN (SELECT id FROM menus WHERE restaurant_id = ?)"},
+		{&db.MenuOption{}, "price_delta", "group_id IN (SELECT g.id FROM menu_option_groups g JOIN menus m ON m.id = g.menu_id WHERE m.restaurant_id = ?)"},
+		{&db.Course{}, "course_price", "restaurant_id = ?"},
+		{&db.Course{}, "original_price", "restaurant_id = ?"},
+	}
+	for _, p := range prices {
+		err := tx.Model(p.model).Where(p.where, restaurantID).Updates(map[string]interface{}{
+			p.column + "_amount":   gorm.Expr("ROUND("+p.column+"_amount * CAST(? AS numeric))", factor),
+			p.column + "_currency": to,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restaurantCurrency returns the currency of the restaurant's prices
+func restaurantCurrency(restaurant db.Restaurant) string {
+	if restaurant.Currency == "" {
+		return money.Default
+	}
+	return restaurant.Currency
+}
+
+func restaurantPricing(restaurant db.Restaurant) money.Pricing {
+	return money.Pricing{
+		TaxRate:       restaurant.TaxRate,
+		ServiceCharge: restaurant.ServiceCharge,
+		TaxIncluded:   restaurant.PricesIncludeTax,
+	}
+}
+
+// inCurrency checks that a price given in a request is in currency, which
+// is filled in when left out
+func inCurrency(price *money.Money, currency string) error {
+	if price.Currency == "" {
+		price.Currency = currency
+		return nil
+	}
+	if price.Currency != currency {
+		return fmt.Errorf("prices must be in %s, not %s", currency, price.Currency)
+	}
+	return nil
+}
+
+// PriceDisplay is a price as a customer pays it, for public endpoints:
+// service charge and tax included, and formatted for the request locale
+type PriceDisplay struct {
+	Total   money.Money `json:"total"`
+	Display string      `json:"display"`
+}
+
+func toPriceDisplay(pricing money.Pricing, price money.Money, locale string) *PriceDisplay {
+	total := pricing.Total(price)
+	return &PriceDisplay{Total: total, Display: money.Format(total, locale)}
+}
+
+// markCustomerPrices sets the customer price of every item of a menu tree
+func markCustomerPrices(tree []MenuCategoryResponse, items []MenuResponse, pricing money.Pricing, locale string) {
+	for i := range items {
+		items[i].CustomerPrice = toPriceDisplay(pricing, items[i].Price, locale)
+	}
+	for i := range tree {
+		markCustomerPrices(tree[i].Children, tree[i].Items, pricing, locale)
+	}
+}
@@ -3,9 +3,15 @@ package handlers
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/example/restosaas/apps/api/internal/budget"
 	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/dietary"
+	"github.com/example/restosaas/apps/api/internal/geo"
+	"github.com/example/restosaas/apps/api/internal/i18n"
+	"github.com/example/restosaas/apps/api/internal/money"
 	"github.com/example/restosaas/apps/api/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -256,8 +262,11 @@ func (h *PublicHandler) ListRestaurants(c *gin.Context) {
 	}
 
 	// Convert to response format
+	locales := requestLocales(c)
 	var response []gin.H
 	for _, restaurant := range restaurants {
+		i18n.LocalizeRestaurant(&restaurant, locales)
+
 		// Get approved reviews for average rating
 		var reviews []db.Review
 		h.DB.Where("restaurant_id = ? AND is_approved = ?", restaurant.ID, true).Find(&reviews)
@@ -306,21 +315,21 @@ func (h *PublicHandler) ListRestaurants(c *gin.Context) {
 	})
 }
 
-// Advanced search endpoint
+// Advanced search endpoint. q is full-text searched in websearch syntax
+// ("momo -fried", "\"set lunch\"", "thakali or newari") across restaurant
+// text, menu items and courses.
 func (h *PublicHandler) AdvancedSearch(c *gin.Context) {
 	query := c.Query("q")
 	// Make query optional - if no query provided, search all restaurants
 
 	// Parse search parameters
 	filters := services.SearchFilters{
-		Area:    c.Query("area"),
-		Cuisine: c.Query("cuisine"),
 		Budget:  c.Query("budget"),
 		People:  c.Query("people"),
 		Date:    c.Query("date"),
 		Time:    c.Query("time"),
-		SortBy:  c.DefaultQuery("sort_by", "rating"),
-		SortDir: c.DefaultQuery("sort_dir", "desc"),
+		SortBy:  c.Query("sort_by"),  // By relevance when q is given, else rating
+		SortDir: c.Query("sort_dir"), // Nearest first for distance, else highest first
 	}
 
 	// Parse pagination parameters
@@ -335,6 +344,51 @@ func (h *PublicHandler) AdvancedSearch(c *gin.Context) {
 		}
 	}
 
+	// Facet values, any of them, e.g. cuisine=Newari&cuisine=Thakali or
+	// rating=4&rating=3
+	if err := facetFilters(c, &filters); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Diet tags, e.g. diet=vegan,halal for restaurants with vegan halal dishes
+	if diet := dietary.SplitQuery(c.Query("diet")); len(diet) > 0 {
+		tags, err := dietary.ParseTags(diet)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		filters.Diet = tags
+	}
+
+	// Budget per person, e.g. price_min=500&price_max=1500 or price_level=$$,$$$
+	if err := budgetFilters(c, &filters); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A free table, e.g. date=2025-03-14&time=19:00&people=4&window=60
+	if err := availabilityFilters(c, &filters); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Open at a time, e.g. open_now=true, open_at=2025-03-14T19:00,
+	// open_late=true or open_on=friday
+	if err := hoursFilters(c, &filters); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Near a point, e.g. lat=27.71&lng=85.31&radius=2, or in a map view,
+	// e.g. bounds=27.6,85.2,27.8,85.4
+	if err := locationFilters(c, &filters); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	filters.Locales = requestLocales(c)
+
 	// Use advanced search service
 	result, err := h.SearchService.AdvancedSearch(query, filters)
 	if err != nil {
@@ -345,53 +399,233 @@ func (h *PublicHandler) AdvancedSearch(c *gin.Context) {
 	c.JSON(200, result)
 }
 
-// Search suggestions endpoint
-func (h *PublicHandler) SearchSuggestions(c *gin.Context) {
-	query := c.Query("q")
-	if len(query) < 2 {
-		c.JSON(200, gin.H{"suggestions": []string{}})
-		return
+// facetFilters reads the multi-select filters of a search: cuisine, area
+// and place, each repeated for several values, and rating, whole stars from
+// 0 (not rated) to 4 (4.0 and up) as a list such as "4,3"
+func facetFilters(c *gin.Context, filters *services.SearchFilters) error {
+	filters.Cuisines = queryValues(c, "cuisine")
+	filters.Areas = queryValues(c, "area")
+	filters.Places = queryValues(c, "place")
+
+	for _, value := range c.QueryArray("rating") {
+		for _, part := range strings.Split(value, ",") {
+			band, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || band < 0 || band > 4 {
+				return fmt.Errorf("rating must be 0 (not rated) to 4")
+			}
+			filters.RatingBands = append(filters.RatingBands, band)
+		}
 	}
+	return nil
+}
 
-	// Get restaurant names and cuisines that match the query
-	var suggestions []string
+// queryValues returns the non-empty values of a repeated query parameter
+func queryValues(c *gin.Context, param string) []string {
+	var values []string
+	for _, v := range c.QueryArray(param) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
 
-	// Search restaurant names
-	var restaurants []db.Restaurant
-	h.DB.Select("name, genre").Where("name LIKE ?", "%"+query+"%").Limit(5).Find(&restaurants)
+// budgetFilters reads the budget filters of a search: price_min and
+// price_max, decimal amounts per person in currency (NPR by default), and
+// price_level, a list of levels such as "2,3" or "$$,$$$"
+func budgetFilters(c *gin.Context, filters *services.SearchFilters) error {
+	currency := money.Default
+	if code := c.Query("currency"); code != "" {
+		parsed, err := money.ParseCurrency(code)
+		if err != nil {
+			return err
+		}
+		currency = parsed
+		filters.Currency = currency
+	}
+
+	bounds := []struct {
+		param string
+		into  **int64
+	}{
+		{"price_min", &filters.PriceMin},
+		{"price_max", &filters.PriceMax},
+	}
+	for _, bound := range bounds {
+		if value := c.Query(bound.param); value != "" {
+			price, err := money.Parse(value, currency)
+			if err != nil {
+				return fmt.Errorf("%s: %v", bound.param, err)
+			}
+			*bound.into = &price.Amount
+		}
+	}
 
-	for _, restaurant := range restaurants {
-		suggestions = append(suggestions, restaurant.Name)
+	if value := c.Query("price_level"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			level, ok := budget.ParseLevel(part)
+			if !ok {
+				return fmt.Errorf("price_level must be 1 to 4 or $ to $$$$")
+			}
+			filters.PriceLevels = append(filters.PriceLevels, level)
+		}
 	}
+	return nil
+}
 
-	// Search unique cuisines
-	var cuisines []string
-	h.DB.Model(&db.Restaurant{}).Distinct("genre").Where("genre LIKE ?", "%"+query+"%").Limit(5).Pluck("genre", &cuisines)
+// availabilityFilters checks the table a search is for: people, date
+// (YYYY-MM-DD), time (HH:MM) and window, the minutes either side of time a
+// slot may start
+func availabilityFilters(c *gin.Context, filters *services.SearchFilters) error {
+	if filters.People != "" {
+		if people, err := strconv.Atoi(filters.People); err != nil || people < 1 {
+			return fmt.Errorf("people must be a number of at least 1")
+		}
+	}
+	if filters.Date != "" {
+		if _, err := time.Parse("2006-01-02", filters.Date); err != nil {
+			return fmt.Errorf("invalid date (YYYY-MM-DD)")
+		}
+	}
+	if filters.Time != "" {
+		if _, err := time.Parse("15:04", filters.Time); err != nil {
+			return fmt.Errorf("invalid time (HH:MM)")
+		}
+	}
+	if value := c.Query("window"); value != "" {
+		window, err := strconv.Atoi(value)
+		if err != nil || window < 0 || window > services.MaxAvailabilityWindow {
+			return fmt.Errorf("window must be 0 to %d minutes", services.MaxAvailabilityWindow)
+		}
+		filters.Window = window
+	}
+	return nil
+}
+
+// hoursFilters reads the opening hours filters of a search: open_now,
+// open_at (YYYY-MM-DDTHH:MM in each restaurant's timezone, or RFC 3339),
+// open_late and open_on, a weekday as 0 (Sunday) to 6 or its name
+func hoursFilters(c *gin.Context, filters *services.SearchFilters) error {
+	flags := []struct {
+		param string
+		into  *bool
+	}{
+		{"open_now", &filters.OpenNow},
+		{"open_late", &filters.OpenLate},
+	}
+	for _, flag := range flags {
+		if value := c.Query(flag.param); value != "" {
+			on, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s must be true or false", flag.param)
+			}
+			*flag.into = on
+		}
+	}
+
+	if value := c.Query("open_at"); value != "" {
+		if _, err := services.ParseOpenAt(value, time.UTC); err != nil {
+			return err
+		}
+		filters.OpenAt = value
+	}
+
+	if value := c.Query("open_on"); value != "" {
+		weekday, ok := parseWeekday(value)
+		if !ok {
+			return fmt.Errorf("open_on must be a weekday, 0 (Sunday) to 6 or its name")
+		}
+		filters.OpenOn = &weekday
+	}
+	return nil
+}
+
+// parseWeekday reads a weekday written as 0 (Sunday) to 6, or as its
+// English name, in full or in three letters
+func parseWeekday(value string) (int, bool) {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, n >= 0 && n <= 6
+	}
+	value = strings.ToLower(strings.TrimSpace(value))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if value == name || value == name[:3] {
+			return int(day), true
+		}
+	}
+	return 0, false
+}
 
-	suggestions = append(suggestions, cuisines...)
+// maxSearchRadiusKm is the widest "near me" search
+const maxSearchRadiusKm = 100
 
-	// Search areas
-	var areas []string
-	h.DB.Model(&db.Restaurant{}).Distinct("area").Where("area LIKE ?", "%"+query+"%").Limit(5).Pluck("area", &areas)
+// locationFilters reads the location filters of a search: lat and lng, a
+// point to measure distances from, radius, in km around it, and bounds, a
+// box written as south,west,north,east
+func locationFilters(c *gin.Context, filters *services.SearchFilters) error {
+	lat, lng := c.Query("lat"), c.Query("lng")
+	if lat != "" || lng != "" {
+		var p geo.Point
+		var err error
+		if p.Lat, err = strconv.ParseFloat(lat, 64); err != nil {
+			return fmt.Errorf("lat must be a latitude in degrees")
+		}
+		if p.Lng, err = strconv.ParseFloat(lng, 64); err != nil {
+			return fmt.Errorf("lng must be a longitude in degrees")
+		}
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		filters.Near = &p
+	}
 
-	// Filter out empty areas and append to suggestions
-	for _, area := range areas {
-		if area != "" {
-			suggestions = append(suggestions, area)
+	if value := c.Query("radius"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil || radius <= 0 || radius > maxSearchRadiusKm {
+			return fmt.Errorf("radius must be a distance in km up to %d", maxSearchRadiusKm)
+		}
+		if filters.Near == nil {
+			return fmt.Errorf("radius requires lat and lng")
 		}
+		filters.RadiusKm = radius
 	}
 
-	// Remove duplicates and limit results
-	seen := make(map[string]bool)
-	var uniqueSuggestions []string
-	for _, suggestion := range suggestions {
-		if !seen[suggestion] && len(uniqueSuggestions) < 10 {
-			seen[suggestion] = true
-			uniqueSuggestions = append(uniqueSuggestions, suggestion)
+	if value := c.Query("bounds"); value != "" {
+		bounds, err := geo.ParseBoundingBox(value)
+		if err != nil {
+			return err
 		}
+		filters.Bounds = &bounds
 	}
+	return nil
+}
+
+// upcomingSpecialDays is how many days ahead a restaurant's page shows
+// special hours for
+const upcomingSpecialDays = 30
 
-	c.JSON(200, gin.H{"suggestions": uniqueSuggestions})
+// Search suggestions endpoint. Suggests restaurants, cuisines, areas and
+// dishes like the query as it is typed, tolerating typos. Restaurants carry
+// their ID and slug; the others are searched for by their text.
+func (h *PublicHandler) SearchSuggestions(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < 2 {
+		c.JSON(200, gin.H{"suggestions": []services.Suggestion{}})
+		return
+	}
+
+	limit := 10
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 20 {
+		limit = l
+	}
+
+	suggestions, err := h.SearchService.Suggest(query, limit)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch suggestions"})
+		return
+	}
+
+	c.JSON(200, gin.H{"suggestions": suggestions})
 }
 
 // Cache management endpoints
@@ -443,30 +677,58 @@ func (h *PublicHandler) GetRestaurant(c *gin.Context) {
 	result := h.DB.Where("restaurant_id = ?", r.ID).Find(&openHours)
 	fmt.Printf("Query result: %v, Error: %v, Count: %d\n", result.RowsAffected, result.Error, len(openHours))
 
+	// Special hours of the coming weeks (and of yesterday, for hours past
+	// midnight), today's hours and whether it is open now, where the
+	// restaurant is
+	now := time.Now()
+	today := now.In(services.RestaurantLocation(r))
+	var specialHours []db.SpecialHour
+	h.DB.Where("restaurant_id = ? AND date BETWEEN ? AND ?", r.ID,
+		today.AddDate(0, 0, -1).Format("2006-01-02"), today.AddDate(0, 0, upcomingSpecialDays).Format("2006-01-02")).
+		Order("date ASC").Find(&specialHours)
+	hours := services.NewOpeningHours(services.RestaurantLocation(r), openHours, specialHours)
+	upcoming := []SpecialHourResponse{}
+	for _, sh := range specialHours {
+		if sh.Date >= today.Format("2006-01-02") {
+			upcoming = append(upcoming, toSpecialHourResponse(sh))
+		}
+	}
+
+	i18n.LocalizeRestaurant(&r, requestLocales(c))
+
 	// Create response with additional fields
 	response := gin.H{
-		"ID":          r.ID,
-		"Slug":        r.Slug,
-		"Name":        r.Name,
-		"Slogan":      r.Slogan,
-		"Place":       r.Place,
-		"Genre":       r.Genre,
-		"Budget":      r.Budget,
-		"Title":       r.Title,
-		"Description": r.Description,
-		"Address":     r.Address,
-		"Phone":       r.Phone,
-		"Timezone":    r.Timezone,
-		"Capacity":    r.Capacity,
-		"IsOpen":      r.IsOpen,
-		"OpenHours":   openHours,
-		"Menus":       []db.Menu{}, // Will be populated separately if needed
-		"Images":      r.Images,    // Now populated with actual images
-		"MainImageID": r.MainImageID,
-		"AvgRating":   avgRating,
-		"ReviewCount": reviewCount,
-		"CreatedAt":   r.CreatedAt,
-		"UpdatedAt":   r.UpdatedAt,
+		"ID":           r.ID,
+		"Slug":         r.Slug,
+		"Name":         r.Name,
+		"Slogan":       r.Slogan,
+		"Place":        r.Place,
+		"Genre":        r.Genre,
+		"Budget":       r.Budget,
+		"BudgetMin":    r.BudgetMin, // Per person; zero when unknown
+		"BudgetMax":    r.BudgetMax,
+		"PriceLevel":   r.PriceLevel, // 1 ($) to 4 ($$$$), 0 when unknown
+		"Title":        r.Title,
+		"Description":  r.Description,
+		"Address":      r.Address,
+		"Phone":        r.Phone,
+		"Timezone":     r.Timezone,
+		"Pricing":      toRestaurantPricing(r), // Currency of menu and course prices, tax and service charge
+		"Capacity":     r.Capacity,
+		"IsOpen":       r.IsOpen,
+		"Latitude":     r.Latitude, // Nil until the restaurant is on the map
+		"Longitude":    r.Longitude,
+		"OpenHours":    openHours,
+		"SpecialHours": upcoming,
+		"TodayHours":   hours.On(today),
+		"OpenNow":      hours.OpenAt(now),
+		"Menus":        []db.Menu{}, // Will be populated separately if needed
+		"Images":       r.Images,    // Now populated with actual images
+		"MainImageID":  r.MainImageID,
+		"AvgRating":    avgRating,
+		"ReviewCount":  reviewCount,
+		"CreatedAt":    r.CreatedAt,
+		"UpdatedAt":    r.UpdatedAt,
 	}
 
 	c.JSON(200, response)
@@ -1,18 +1,26 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/auth"
+	"github.com/example/restosaas/apps/api/internal/authz"
 	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/geo"
 	"github.com/example/restosaas/apps/api/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type RestaurantHandler struct{ DB *gorm.DB }
+type RestaurantHandler struct {
+	DB       *gorm.DB
+	Geocoder geo.Geocoder // Places restaurants from their address; none when nil
+}
 
 // CreateRestaurantRequest represents the request to create a restaurant
 type CreateRestaurantRequest struct {
@@ -27,6 +35,12 @@ type CreateRestaurantRequest struct {
 	Phone       string `json:"phone"`
 	Capacity    int64  `json:"capacity" binding:"min=1"`
 	IsOpen      bool   `json:"isOpen"`
+	// Position on the map; geocoded from address and place when left out
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	// Organization to create the restaurant in. Required for owner requests
+	// when the caller belongs to more than one organization.
+	OrgID string `json:"orgId"`
 }
 
 // UpdateRestaurantRequest represents the request to update a restaurant
@@ -45,6 +59,10 @@ type UpdateRestaurantRequest struct {
 	MainImageID *string              `json:"mainImageId,omitempty"`
 	OpenHours   []OpeningHourRequest `json:"openHours,omitempty"`
 	Images      []ImageRequest       `json:"images,omitempty"`
+	// Position on the map; geocoded again when the address or place
+	// changes and the position was geocoded before
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
 }
 
 // OpeningHourRequest represents opening hours for a specific day
@@ -66,6 +84,8 @@ type ImageRequest struct {
 // RestaurantResponse represents a restaurant in API responses
 type RestaurantResponse struct {
 	ID          string                `json:"id"`
+	OrgID       string                `json:"orgId,omitempty"`
+	Role        string                `json:"role,omitempty"` // Caller's role in the restaurant's organization
 	Slug        string                `json:"slug"`
 	Name        string                `json:"name"`
 	Slogan      string                `json:"slogan"`
@@ -78,6 +98,8 @@ type RestaurantResponse struct {
 	Phone       string                `json:"phone"`
 	Capacity    int64                 `json:"capacity"`
 	IsOpen      bool                  `json:"isOpen"`
+	Latitude    *float64              `json:"latitude,omitempty"`
+	Longitude   *float64              `json:"longitude,omitempty"`
 	MainImageID *string               `json:"mainImageId,omitempty"`
 	OpenHours   []OpeningHourResponse `json:"openHours,omitempty"`
 	Images      []ImageResponse       `json:"images,omitempty"`
@@ -104,7 +126,7 @@ type ImageResponse struct {
 // CreateMenuRequest represents the request to create a menu
 // These types are now defined in the separate menu.go and courses.go handlers
 
-// POST /api/owner/restaurants - Create restaurant (OWNER only)
+// POST /api/owner/restaurants - Create restaurant (restaurant:create)
 func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("uid")
@@ -119,10 +141,34 @@ func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 		return
 	}
 
-	// Get user's organization
-	var orgMember db.OrgMember
-	if err := h.DB.Where("user_id = ?", userID).First(&orgMember).Error; err != nil {
-		c.JSON(404, gin.H{"error": "organization not found"})
+	// Resolve the target organization
+	var orgID uuid.UUID
+	if req.OrgID != "" {
+		parsed, err := uuid.Parse(req.OrgID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid organization ID"})
+			return
+		}
+		orgID = parsed
+	} else if key, ok := auth.APIKeyFromContext(c); ok {
+		orgID = key.OrgID
+	} else {
+		var members []db.OrgMember
+		if err := h.DB.Where("user_id = ?", userID).Limit(2).Find(&members).Error; err != nil {
+			c.JSON(500, gin.H{"error": "failed to fetch organizations"})
+			return
+		}
+		if len(members) == 0 {
+			c.JSON(404, gin.H{"error": "organization not found"})
+			return
+		}
+		if len(members) > 1 {
+			c.JSON(400, gin.H{"error": "orgId is required when you belong to several organizations"})
+			return
+		}
+		orgID = members[0].OrgID
+	}
+	if !authz.Authorize(c, h.DB, orgID, authz.PermRestaurantCreate) {
 		return
 	}
 
@@ -139,7 +185,7 @@ func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 	// Create restaurant
 	restaurant := db.Restaurant{
 		ID:          uuid.New(),
-		OrgID:       orgMember.OrgID,
+		OrgID:       orgID,
 		Slug:        slug,
 		Name:        req.Name,
 		Slogan:      req.Slogan,
@@ -155,6 +201,16 @@ func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if err := setBudget(&restaurant, req.Budget); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	location, err := requestLocation(req.Latitude, req.Longitude)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	h.locateRestaurant(c.Request.Context(), &restaurant, location)
 
 	if err := h.DB.Create(&restaurant).Error; err != nil {
 		c.JSON(500, gin.H{"error": "failed to create restaurant"})
@@ -175,6 +231,8 @@ func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 		Phone:       restaurant.Phone,
 		Capacity:    restaurant.Capacity,
 		IsOpen:      restaurant.IsOpen,
+		Latitude:    restaurant.Latitude,
+		Longitude:   restaurant.Longitude,
 		CreatedAt:   restaurant.CreatedAt,
 		UpdatedAt:   restaurant.UpdatedAt,
 	}
@@ -182,7 +240,39 @@ func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 	c.JSON(201, response)
 }
 
-// GET /api/owner/restaurants/me - Get owner's restaurant (OWNER only)
+// GET /api/owner/restaurants - List restaurants in all of the caller's organizations
+func (h *RestaurantHandler) ListMyRestaurants(c *gin.Context) {
+	userID, exists := c.Get("uid")
+	if !exists {
+		c.JSON(401, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	restaurants, roles, err := h.memberRestaurants(c, userID, false)
+	if err == errInvalidOrgID {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err == errMissingRestaurantRead {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch restaurants"})
+		return
+	}
+
+	response := make([]RestaurantResponse, 0, len(restaurants))
+	for _, restaurant := range restaurants {
+		item := toRestaurantResponse(restaurant)
+		item.Role = string(roles[restaurant.OrgID])
+		response = append(response, item)
+	}
+
+	c.JSON(200, gin.H{"restaurants": response})
+}
+
+// GET /api/owner/restaurants/me - Get the caller's restaurants with details (any organization member)
 func (h *RestaurantHandler) GetMyRestaurant(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get("uid")
@@ -191,86 +281,146 @@ func (h *RestaurantHandler) GetMyRestaurant(c *gin.Context) {
 		return
 	}
 
-	// Get user's organization
-	var orgMember db.OrgMember
-	if err := h.DB.Where("user_id = ?", userID).First(&orgMember).Error; err != nil {
-		c.JSON(404, gin.H{"error": "organization not found"})
+	restaurants, roles, err := h.memberRestaurants(c, userID, true)
+	if err == errInvalidOrgID {
+		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Get all restaurants for the organization
-	var restaurants []db.Restaurant
-	if err := h.DB.Where("org_id = ?", orgMember.OrgID).Preload("Images").Preload("OpenHours").Preload("Menus").Preload("Courses").Find(&restaurants).Error; err != nil {
+	if err == errMissingRestaurantRead {
+		c.JSON(403, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to fetch restaurants"})
 		return
 	}
+	if len(roles) == 0 && c.GetString("role") != string(db.RoleSuper) {
+		c.JSON(404, gin.H{"error": "organization not found"})
+		return
+	}
 
 	// Convert to response format
 	var response []RestaurantResponse
 	for _, restaurant := range restaurants {
-		mainImageID := ""
-		if restaurant.MainImageID != nil {
-			mainImageID = restaurant.MainImageID.String()
-		}
+		restaurantResponse := toRestaurantResponse(restaurant)
+		restaurantResponse.Role = string(roles[restaurant.OrgID])
+		response = append(response, restaurantResponse)
+	}
 
-		restaurantResponse := RestaurantResponse{
-			ID:          restaurant.ID.String(),
-			Slug:        restaurant.Slug,
-			Name:        restaurant.Name,
-			Slogan:      restaurant.Slogan,
-			Place:       restaurant.Place,
-			Genre:       restaurant.Genre,
-			Budget:      restaurant.Budget,
-			Title:       restaurant.Title,
-			Description: restaurant.Description,
-			Address:     restaurant.Address,
-			Phone:       restaurant.Phone,
-			Capacity:    restaurant.Capacity,
-			IsOpen:      restaurant.IsOpen,
-			MainImageID: &mainImageID,
-			CreatedAt:   restaurant.CreatedAt,
-			UpdatedAt:   restaurant.UpdatedAt,
-		}
+	c.JSON(200, gin.H{"restaurants": response})
+}
 
-		// Convert opening hours
-		for _, hour := range restaurant.OpenHours {
-			restaurantResponse.OpenHours = append(restaurantResponse.OpenHours, OpeningHourResponse{
-				ID:        hour.ID.String(),
-				Weekday:   hour.Weekday,
-				OpenTime:  hour.OpenTime,
-				CloseTime: hour.CloseTime,
-				IsClosed:  hour.IsClosed,
-			})
-		}
+var (
+	errInvalidOrgID          = errors.New("invalid organization ID")
+	errMissingRestaurantRead = errors.New("access denied: missing permission " + string(authz.PermRestaurantRead))
+)
 
-		// Convert images
-		for _, img := range restaurant.Images {
-			restaurantResponse.Images = append(restaurantResponse.Images, ImageResponse{
-				ID:           img.ID.String(),
-				URL:          img.URL,
-				Alt:          img.Alt,
-				IsMain:       img.IsMain,
-				DisplayOrder: img.DisplayOrder,
-			})
+// memberRestaurants loads the restaurants of every organization the user
+// belongs to, optionally narrowed by the orgId query parameter, along with
+// the user's role in each organization. API keys with the restaurant:read
+// scope see their organization's restaurants, with no role.
+func (h *RestaurantHandler) memberRestaurants(c *gin.Context, userID interface{}, details bool) ([]db.Restaurant, map[uuid.UUID]db.OrgRole, error) {
+	var members []db.OrgMember
+	if key, ok := auth.APIKeyFromContext(c); ok {
+		if !key.HasScope(string(authz.PermRestaurantRead)) {
+			return nil, nil, errMissingRestaurantRead
 		}
+		members = []db.OrgMember{{OrgID: key.OrgID}}
+	} else if err := h.DB.Where("user_id = ?", userID).Find(&members).Error; err != nil {
+		return nil, nil, err
+	}
 
-		response = append(response, restaurantResponse)
+	roles := make(map[uuid.UUID]db.OrgRole, len(members))
+	orgIDs := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		roles[m.OrgID] = m.Role
+		orgIDs = append(orgIDs, m.OrgID)
 	}
 
-	c.JSON(200, gin.H{"restaurants": response})
+	if orgID := c.Query("orgId"); orgID != "" {
+		id, err := uuid.Parse(orgID)
+		if err != nil {
+			return nil, nil, errInvalidOrgID
+		}
+		if _, ok := roles[id]; !ok {
+			return []db.Restaurant{}, roles, nil
+		}
+		orgIDs = []uuid.UUID{id}
+	}
+	if len(orgIDs) == 0 {
+		return []db.Restaurant{}, roles, nil
+	}
+
+	q := h.DB.Where("org_id IN ?", orgIDs).Order("name ASC")
+	if details {
+		q = q.Preload("Images").Preload("OpenHours").Preload("Menus").Preload("Courses")
+	}
+	var restaurants []db.Restaurant
+	if err := q.Find(&restaurants).Error; err != nil {
+		return nil, nil, err
+	}
+	return restaurants, roles, nil
 }
 
-// PUT /api/owner/restaurants/:id - Update restaurant (OWNER or SUPER_ADMIN)
-func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
-	// Get user ID and role from context
-	userID, exists := c.Get("uid")
-	if !exists {
-		c.JSON(401, gin.H{"error": "unauthorized"})
-		return
+// toRestaurantResponse converts a restaurant, including any preloaded
+// opening hours and images, to its API representation
+func toRestaurantResponse(restaurant db.Restaurant) RestaurantResponse {
+	mainImageID := ""
+	if restaurant.MainImageID != nil {
+		mainImageID = restaurant.MainImageID.String()
+	}
+
+	restaurantResponse := RestaurantResponse{
+		ID:          restaurant.ID.String(),
+		OrgID:       restaurant.OrgID.String(),
+		Slug:        restaurant.Slug,
+		Name:        restaurant.Name,
+		Slogan:      restaurant.Slogan,
+		Place:       restaurant.Place,
+		Genre:       restaurant.Genre,
+		Budget:      restaurant.Budget,
+		Title:       restaurant.Title,
+		Description: restaurant.Description,
+		Address:     restaurant.Address,
+		Phone:       restaurant.Phone,
+		Capacity:    restaurant.Capacity,
+		IsOpen:      restaurant.IsOpen,
+		Latitude:    restaurant.Latitude,
+		Longitude:   restaurant.Longitude,
+		MainImageID: &mainImageID,
+		CreatedAt:   restaurant.CreatedAt,
+		UpdatedAt:   restaurant.UpdatedAt,
+	}
+
+	// Convert opening hours
+	for _, hour := range restaurant.OpenHours {
+		restaurantResponse.OpenHours = append(restaurantResponse.OpenHours, OpeningHourResponse{
+			ID:        hour.ID.String(),
+			Weekday:   hour.Weekday,
+			OpenTime:  hour.OpenTime,
+			CloseTime: hour.CloseTime,
+			IsClosed:  hour.IsClosed,
+		})
+	}
+
+	// Convert images
+	for _, img := range restaurant.Images {
+		restaurantResponse.Images = append(restaurantResponse.Images, ImageResponse{
+			ID:           img.ID.String(),
+			URL:          img.URL,
+			Alt:          img.Alt,
+			IsMain:       img.IsMain,
+			DisplayOrder: img.DisplayOrder,
+		})
 	}
 
-	userRole, roleExists := c.Get("role")
-	if !roleExists {
+	return restaurantResponse
+}
+
+// PUT /api/owner/restaurants/:id - Update restaurant (restaurant:write or SUPER_ADMIN)
+func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
+	// Get user ID from context
+	if _, exists := c.Get("uid"); !exists {
 		c.JSON(401, gin.H{"error": "unauthorized"})
 		return
 	}
@@ -300,25 +450,11 @@ func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
 		return
 	}
 
-	// Check access control
-	if userRole == string(db.RoleSuper) {
-		// SUPER_ADMIN can edit any restaurant
-	} else if userRole == string(db.RoleOwner) {
-		// OWNER can only edit their own restaurant
-		var orgMember db.OrgMember
-		if err := h.DB.Where("user_id = ?", userID).First(&orgMember).Error; err != nil {
-			c.JSON(404, gin.H{"error": "organization not found"})
-			return
-		}
-
-		if restaurant.OrgID != orgMember.OrgID {
-			c.JSON(403, gin.H{"error": "access denied: you can only edit your own restaurant"})
-			return
-		}
-	} else {
-		c.JSON(403, gin.H{"error": "access denied: insufficient permissions"})
+	// Shared by the owner and super admin routes, so check here as well
+	if !authz.Authorize(c, h.DB, restaurant.OrgID, authz.PermRestaurantWrite) {
 		return
 	}
+	before := restaurant
 
 	// Update fields
 	if req.Name != nil {
@@ -336,7 +472,10 @@ func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
 		restaurant.Genre = *req.Genre
 	}
 	if req.Budget != nil {
-		restaurant.Budget = *req.Budget
+		if err := setBudget(&restaurant, *req.Budget); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
 	}
 	if req.Title != nil {
 		restaurant.Title = *req.Title
@@ -356,10 +495,31 @@ func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
 	if req.IsOpen != nil {
 		restaurant.IsOpen = *req.IsOpen
 	}
+	location, err := requestLocation(req.Latitude, req.Longitude)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if location != nil || req.Address != nil || req.Place != nil {
+		h.locateRestaurant(c.Request.Context(), &restaurant, location)
+	}
 
 	restaurant.UpdatedAt = time.Now()
 
-	if err := h.DB.Save(&restaurant).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&restaurant).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantUpdate,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      restaurant,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to update restaurant"})
 		return
 	}
@@ -378,6 +538,8 @@ func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
 		Phone:       restaurant.Phone,
 		Capacity:    restaurant.Capacity,
 		IsOpen:      restaurant.IsOpen,
+		Latitude:    restaurant.Latitude,
+		Longitude:   restaurant.Longitude,
 		CreatedAt:   restaurant.CreatedAt,
 		UpdatedAt:   restaurant.UpdatedAt,
 	}
@@ -385,43 +547,20 @@ func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
 	c.JSON(200, response)
 }
 
-// DELETE /api/owner/restaurants/:id - Delete restaurant (OWNER only)
+// DELETE /api/owner/restaurants/:id - Delete restaurant (restaurant:delete)
 func (h *RestaurantHandler) DeleteRestaurant(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("uid")
+	_, exists := c.Get("uid")
 	if !exists {
 		c.JSON(401, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	// Get restaurant ID from URL
-	restaurantID := c.Param("id")
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
-	// Get user's organization
-	var orgMember db.OrgMember
-	if err := h.DB.Where("user_id = ?", userID).First(&orgMember).Error; err != nil {
-		c.JSON(404, gin.H{"error": "organization not found"})
-		return
-	}
-
-	// Get restaurant and verify ownership
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ? AND org_id = ?", restaurantUUID, orgMember.OrgID).First(&restaurant).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "restaurant not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": "failed to fetch restaurant"})
-		return
-	}
+	// Loaded and authorized by authz.RestaurantScope
+	restaurant := authz.ScopedRestaurant(c)
 
 	// Delete restaurant (cascade will handle related records)
-	if err := h.DB.Delete(&restaurant).Error; err != nil {
+	if err := h.deleteRestaurant(c, restaurant); err != nil {
 		c.JSON(500, gin.H{"error": "failed to delete restaurant"})
 		return
 	}
@@ -459,7 +598,7 @@ func (h *RestaurantHandler) ListAllRestaurants(c *gin.Context) {
 		Joins("JOIN organizations ON restaurants.org_id = organizations.id").
 		Joins("JOIN org_members ON organizations.id = org_members.org_id").
 		Joins("JOIN users ON org_members.user_id = users.id").
-		Where("org_members.role = ?", db.RoleOwner).
+		Where("org_members.role = ?", db.OrgRoleOwner).
 		Find(&restaurants).Error; err != nil {
 		c.JSON(500, gin.H{"error": "failed to fetch restaurants"})
 		return
@@ -506,7 +645,7 @@ func (h *RestaurantHandler) GetRestaurantByID(c *gin.Context) {
 		Joins("JOIN organizations ON restaurants.org_id = organizations.id").
 		Joins("JOIN org_members ON organizations.id = org_members.org_id").
 		Joins("JOIN users ON org_members.user_id = users.id").
-		Where("restaurants.id = ? AND org_members.role = ?", restaurantUUID, db.RoleOwner).
+		Where("restaurants.id = ? AND org_members.role = ?", restaurantUUID, db.OrgRoleOwner).
 		First(&result).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(404, gin.H{"error": "restaurant not found"})
@@ -519,22 +658,18 @@ func (h *RestaurantHandler) GetRestaurantByID(c *gin.Context) {
 	c.JSON(200, result)
 }
 
-// POST /api/owner/restaurants/:id/hours - Set opening hours (OWNER only)
+// POST /api/owner/restaurants/:id/hours - Set opening hours (restaurant:write)
 func (h *RestaurantHandler) SetOpeningHours(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("uid")
+	_, exists := c.Get("uid")
 	if !exists {
 		c.JSON(401, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	// Get restaurant ID from URL
-	restaurantID := c.Param("id")
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
+	// Loaded and authorized by authz.RestaurantScope
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
 
 	var req struct {
 		OpenHours []OpeningHourRequest `json:"openHours" binding:"required"`
@@ -545,23 +680,6 @@ func (h *RestaurantHandler) SetOpeningHours(c *gin.Context) {
 		return
 	}
 
-	// Verify restaurant ownership
-	var orgMember db.OrgMember
-	if err := h.DB.Where("user_id = ?", userID).First(&orgMember).Error; err != nil {
-		c.JSON(404, gin.H{"error": "organization not found"})
-		return
-	}
-
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ? AND org_id = ?", restaurantUUID, orgMember.OrgID).First(&restaurant).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "restaurant not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": "failed to fetch restaurant"})
-		return
-	}
-
 	// Delete existing opening hours
 	if err := h.DB.Where("restaurant_id = ?", restaurantUUID).Delete(&db.OpeningHour{}).Error; err != nil {
 		c.JSON(500, gin.H{"error": "failed to delete existing hours"})
@@ -587,22 +705,18 @@ func (h *RestaurantHandler) SetOpeningHours(c *gin.Context) {
 	c.JSON(200, gin.H{"message": "opening hours updated successfully"})
 }
 
-// POST /api/owner/restaurants/:id/images - Upload images (OWNER only)
+// POST /api/owner/restaurants/:id/images - Upload images (restaurant:write)
 func (h *RestaurantHandler) UploadImages(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("uid")
+	_, exists := c.Get("uid")
 	if !exists {
 		c.JSON(401, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	// Get restaurant ID from URL
-	restaurantID := c.Param("id")
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
+	// Loaded and authorized by authz.RestaurantScope
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
 
 	var req struct {
 		Images []ImageRequest `json:"images" binding:"required"`
@@ -613,23 +727,6 @@ func (h *RestaurantHandler) UploadImages(c *gin.Context) {
 		return
 	}
 
-	// Verify restaurant ownership
-	var orgMember db.OrgMember
-	if err := h.DB.Where("user_id = ?", userID).First(&orgMember).Error; err != nil {
-		c.JSON(404, gin.H{"error": "organization not found"})
-		return
-	}
-
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ? AND org_id = ?", restaurantUUID, orgMember.OrgID).First(&restaurant).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "restaurant not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": "failed to fetch restaurant"})
-		return
-	}
-
 	// If any image is marked as main, unset current main image
 	hasMainImage := false
 	for _, img := range req.Images {
@@ -641,7 +738,7 @@ func (h *RestaurantHandler) UploadImages(c *gin.Context) {
 
 	if hasMainImage {
 		// Unset current main image
-		if err := h.DB.Model(&restaurant).Update("main_image_id", nil).Error; err != nil {
+		if err := h.DB.Model(restaurant).Update("main_image_id", nil).Error; err != nil {
 			c.JSON(500, gin.H{"error": "failed to update main image"})
 			return
 		}
@@ -665,7 +762,7 @@ func (h *RestaurantHandler) UploadImages(c *gin.Context) {
 
 		// If this is the main image, update restaurant
 		if img.IsMain {
-			if err := h.DB.Model(&restaurant).Update("main_image_id", image.ID).Error; err != nil {
+			if err := h.DB.Model(restaurant).Update("main_image_id", image.ID).Error; err != nil {
 				c.JSON(500, gin.H{"error": "failed to set main image"})
 				return
 			}
@@ -726,19 +823,15 @@ func (h *RestaurantHandler) GetRestaurantReviews(c *gin.Context) {
 // POST /api/owner/restaurants/:id/images/single - Upload single image
 func (h *RestaurantHandler) UploadSingleImage(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("uid")
+	_, exists := c.Get("uid")
 	if !exists {
 		c.JSON(401, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	// Get restaurant ID from URL
-	restaurantID := c.Param("id")
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
+	// Loaded and authorized by authz.RestaurantScope
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
 
 	var req ImageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -746,19 +839,6 @@ func (h *RestaurantHandler) UploadSingleImage(c *gin.Context) {
 		return
 	}
 
-	// Verify restaurant ownership
-	var orgMember db.OrgMember
-	if err := h.DB.Where("user_id = ?", userID).First(&orgMember).Error; err != nil {
-		c.JSON(404, gin.H{"error": "organization not found"})
-		return
-	}
-
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ? AND org_id = ?", restaurantUUID, orgMember.OrgID).First(&restaurant).Error; err != nil {
-		c.JSON(404, gin.H{"error": "restaurant not found"})
-		return
-	}
-
 	// Initialize Cloudinary service
 	cloudinaryService, err := services.NewCloudinaryService()
 	if err != nil {
@@ -803,7 +883,7 @@ func (h *RestaurantHandler) UploadSingleImage(c *gin.Context) {
 
 	// If this is the main image, update restaurant
 	if req.IsMain {
-		if err := h.DB.Model(&restaurant).Update("main_image_id", image.ID).Error; err != nil {
+		if err := h.DB.Model(restaurant).Update("main_image_id", image.ID).Error; err != nil {
 			c.JSON(500, gin.H{"error": "failed to set main image"})
 			return
 		}
@@ -823,33 +903,17 @@ func (h *RestaurantHandler) UploadSingleImage(c *gin.Context) {
 
 // POST /api/owner/restaurants/:id/images/:imageId/set-main - Set main image
 func (h *RestaurantHandler) SetMainImage(c *gin.Context) {
-	restaurantID := c.Param("id")
+	// Loaded and authorized by authz.RestaurantScope
+	restaurant := authz.ScopedRestaurant(c)
+	restaurantUUID := restaurant.ID
 	imageID := c.Param("imageId")
 
-	// Parse UUIDs
-	restaurantUUID, err := uuid.Parse(restaurantID)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "invalid restaurant ID"})
-		return
-	}
-
 	imageUUID, err := uuid.Parse(imageID)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "invalid image ID"})
 		return
 	}
 
-	// Check if restaurant exists
-	var restaurant db.Restaurant
-	if err := h.DB.Where("id = ?", restaurantUUID).First(&restaurant).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(404, gin.H{"error": "restaurant not found"})
-			return
-		}
-		c.JSON(500, gin.H{"error": "failed to fetch restaurant"})
-		return
-	}
-
 	// Check if image exists and belongs to this restaurant
 	var image db.Image
 	if err := h.DB.Where("id = ? AND restaurant_id = ?", imageUUID, restaurantUUID).First(&image).Error; err != nil {
@@ -874,7 +938,7 @@ func (h *RestaurantHandler) SetMainImage(c *gin.Context) {
 		}
 
 		// Update restaurant's main_image_id
-		if err := tx.Model(&restaurant).Update("main_image_id", imageUUID).Error; err != nil {
+		if err := tx.Model(restaurant).Update("main_image_id", imageUUID).Error; err != nil {
 			return err
 		}
 
@@ -960,6 +1024,16 @@ func (h *RestaurantHandler) CreateRestaurantForOrganization(c *gin.Context) {
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if err := setBudget(&restaurant, req.Budget); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	location, err := requestLocation(req.Latitude, req.Longitude)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	h.locateRestaurant(c.Request.Context(), &restaurant, location)
 
 	if err := h.DB.Create(&restaurant).Error; err != nil {
 		c.JSON(500, gin.H{"error": "failed to create restaurant"})
@@ -971,7 +1045,7 @@ func (h *RestaurantHandler) CreateRestaurantForOrganization(c *gin.Context) {
 	h.DB.Table("org_members").
 		Select("users.*").
 		Joins("JOIN users ON org_members.user_id = users.id").
-		Where("org_members.org_id = ? AND org_members.role = ?", orgUUID, db.RoleOwner).
+		Where("org_members.org_id = ? AND org_members.role = ?", orgUUID, db.OrgRoleOwner).
 		First(&owner)
 
 	response := struct {
@@ -1051,10 +1125,26 @@ func (h *RestaurantHandler) DeleteRestaurantByID(c *gin.Context) {
 	}
 
 	// Delete restaurant (cascade will handle related records)
-	if err := h.DB.Delete(&restaurant).Error; err != nil {
+	if err := h.deleteRestaurant(c, &restaurant); err != nil {
 		c.JSON(500, gin.H{"error": "failed to delete restaurant"})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// deleteRestaurant deletes a restaurant and records it in the audit log
+func (h *RestaurantHandler) deleteRestaurant(c *gin.Context, restaurant *db.Restaurant) error {
+	return h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(restaurant).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantDelete,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     *restaurant,
+		})
+	})
+}
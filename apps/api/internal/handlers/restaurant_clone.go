@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"sort"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Parts of a restaurant that can be cloned
+const (
+	ClonePartMenus   = "menus" // Categories, items, variants, options and availability rules
+	ClonePartCourses = "courses"
+	ClonePartHours   = "hours"
+	ClonePartImages  = "images"
+)
+
+var cloneParts = []string{ClonePartMenus, ClonePartCourses, ClonePartHours, ClonePartImages}
+
+// CloneRestaurantRequest copies parts of a restaurant into another one of
+// the same organization, e.g. when a chain opens a new branch
+type CloneRestaurantRequest struct {
+	TargetRestaurantID string `json:"targetRestaurantId" binding:"required"`
+	// Parts to copy (menus, courses, hours, images); all when empty
+	Include []string `json:"include" binding:"omitempty,dive,oneof=menus courses hours images"`
+	// keep (default) copies prices, in the target's currency; reset sets
+	// them to 0 so the branch can price items itself
+	Prices string `json:"prices" binding:"omitempty,oneof=keep reset"`
+	// Replace the target's menus, courses and images instead of adding to
+	// them. Opening hours are always replaced.
+	Replace bool `json:"replace"`
+}
+
+type CloneRestaurantResponse struct {
+	SourceRestaurantID string   `json:"sourceRestaurantId"`
+	TargetRestaurantID string   `json:"targetRestaurantId"`
+	Included           []string `json:"included"`
+	PricesReset        bool     `json:"pricesReset"`
+	Replaced           bool     `json:"replaced"`
+	Categories         int      `json:"categories"`
+	Menus              int      `json:"menus"`
+	Courses            int      `json:"courses"`
+	OpeningHours       int      `json:"openingHours"`
+	Images             int      `json:"images"`
+	// SKUs already used in the target; the copies were left without one
+	ClearedSKUs []string `json:"clearedSkus"`
+}
+
+// POST /api/owner/restaurants/:id/clone - Copy menus, courses, hours and images into another restaurant (menus:write on both; restaurant:write on the target for hours and images)
+func (h *RestaurantHandler) CloneRestaurant(c *gin.Context) {
+	// Loaded and authorized by authz.RestaurantScope
+	source := authz.ScopedRestaurant(c)
+
+	var req CloneRestaurantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	targetUUID, err := uuid.Parse(req.TargetRestaurantID)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid target restaurant ID"})
+		return
+	}
+	if targetUUID == source.ID {
+		c.JSON(400, gin.H{"error": "target must be a different restaurant"})
+		return
+	}
+
+	var target db.Restaurant
+	if err := h.DB.Where("id = ? AND org_id = ?", targetUUID, source.OrgID).First(&target).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(404, gin.H{"error": "target restaurant not found"})
+			return
+		}
+		c.JSON(500, gin.H{"error": "failed to fetch target restaurant"})
+		return
+	}
+
+	include := make(map[string]bool)
+	for _, part := range req.Include {
+		include[part] = true
+	}
+	if len(include) == 0 {
+		for _, part := range cloneParts {
+			include[part] = true
+		}
+	}
+	// Both restaurants are in the same organization, so the target is
+	// checked for the permissions the copied parts need there
+	if (include[ClonePartHours] || include[ClonePartImages]) && !authz.Authorize(c, h.DB, target.OrgID, authz.PermRestaurantWrite) {
+		return
+	}
+
+	response := CloneRestaurantResponse{
+		SourceRestaurantID: source.ID.String(),
+		TargetRestaurantID: target.ID.String(),
+		Included:           []string{},
+		PricesReset:        req.Prices == "reset",
+		Replaced:           req.Replace,
+		ClearedSKUs:        []string{},
+	}
+	for _, part := range cloneParts {
+		if include[part] {
+			response.Included = append(response.Included, part)
+		}
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if include[ClonePartMenus] {
+			if err := cloneMenusInto(tx, source.ID, &target, req.Replace, response.PricesReset, &response); err != nil {
+				return err
+			}
+		}
+		if include[ClonePartCourses] {
+			if err := cloneCoursesInto(tx, source.ID, &target, req.Replace, response.PricesReset, &response); err != nil {
+				return err
+			}
+		}
+		if include[ClonePartHours] {
+			if err := cloneHoursInto(tx, source.ID, target.ID, &response); err != nil {
+				return err
+			}
+		}
+		if include[ClonePartImages] {
+			if err := cloneImagesInto(tx, source, &target, req.Replace, &response); err != nil {
+				return err
+			}
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantClone,
+			EntityType: "restaurant",
+			EntityID:   target.ID.String(),
+			OrgID:      &target.OrgID,
+			After:      response,
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to clone restaurant"})
+		return
+	}
+
+	c.JSON(200, response)
+}
+
+// menuCopy is a restaurant's menu with fresh IDs, ready to insert into
+// another restaurant
+type menuCopy struct {
+	categories  []db.MenuCategory // Parents before their children
+	menus       []db.Menu         // With variants and option groups
+	rules       []db.MenuAvailability
+	clearedSKUs []string
+}
+
+// copyMenus copies categories, items with their options, and availability
+// rules into the target restaurant, remapping every reference to the new
+// IDs. SKUs in takenSKUs are dropped from the copies.
+func copyMenus(targetID uuid.UUID, currency string, categories []db.MenuCategory, menus []db.Menu, rules []db.MenuAvailability, resetPrices bool, takenSKUs map[string]bool) menuCopy {
+	var out menuCopy
+	now := time.Now()
+
+	categoryIDs := make(map[uuid.UUID]uuid.UUID, len(categories))
+	for _, category := range categories {
+		categoryIDs[category.ID] = uuid.New()
+	}
+	remapCategory := func(id *uuid.UUID) *uuid.UUID {
+		if id == nil {
+			return nil
+		}
+		if newID, ok := categoryIDs[*id]; ok {
+			return &newID
+		}
+		return nil
+	}
+
+	for _, category := range categories {
+		copied := category
+		copied.ID = categoryIDs[category.ID]
+		copied.RestaurantID = targetID
+		copied.ParentID = remapCategory(category.ParentID)
+		copied.CreatedAt, copied.UpdatedAt = now, now
+		out.categories = append(out.categories, copied)
+	}
+	sortParentsFirst(out.categories)
+
+	menuIDs := make(map[uuid.UUID]uuid.UUID, len(menus))
+	for _, menu := range menus {
+		copied := menu
+		copied.ID = uuid.New()
+		copied.RestaurantID = targetID
+		copied.CategoryID = remapCategory(menu.CategoryID)
+		copied.SoldOutUntil = nil
+		copied.CreatedAt, copied.UpdatedAt = now, now
+		if copied.SKU != "" && takenSKUs[copied.SKU] {
+			out.clearedSKUs = append(out.clearedSKUs, copied.SKU)
+			copied.SKU = ""
+		}
+		copied.Price = clonedPrice(menu.Price, currency, resetPrices)
+		menuIDs[menu.ID] = copied.ID
+
+		copied.Variants = make([]db.MenuVariant, 0, len(menu.Variants))
+		for _, variant := range menu.Variants {
+			variant.ID = uuid.New()
+			variant.MenuID = copied.ID
+			variant.Price = clonedPrice(variant.Price, currency, resetPrices)
+			copied.Variants = append(copied.Variants, variant)
+		}
+		copied.OptionGroups = make([]db.MenuOptionGroup, 0, len(menu.OptionGroups))
+		for _, group := range menu.OptionGroups {
+			options := make([]db.MenuOption, 0, len(group.Options))
+			group.ID = uuid.New()
+			group.MenuID = copied.ID
+			for _, option := range group.Options {
+				option.ID = uuid.New()
+				option.GroupID = group.ID
+				option.PriceDelta = clonedPrice(option.PriceDelta, currency, resetPrices)
+				options = append(options, option)
+			}
+			group.Options = options
+			copied.OptionGroups = append(copied.OptionGroups, group)
+		}
+		out.menus = append(out.menus, copied)
+	}
+
+	for _, rule := range rules {
+		copied := rule
+		copied.ID = uuid.New()
+		copied.RestaurantID = targetID
+		copied.CategoryID = remapCategory(rule.CategoryID)
+		copied.MenuID = nil
+		if rule.MenuID != nil {
+			if newID, ok := menuIDs[*rule.MenuID]; ok {
+				copied.MenuID = &newID
+			}
+		}
+		if copied.MenuID == nil && copied.CategoryID == nil {
+			continue
+		}
+		out.rules = append(out.rules, copied)
+	}
+	return out
+}
+
+// sortParentsFirst orders categories so that each comes after its parent,
+// keeping the order otherwise
+func sortParentsFirst(categories []db.MenuCategory) {
+	parents := make(map[uuid.UUID]*uuid.UUID, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+	depth := func(category db.MenuCategory) int {
+		d := 0
+		for id := category.ParentID; id != nil && d <= len(categories); id = parents[*id] {
+			d++
+		}
+		return d
+	}
+	sort.SliceStable(categories, func(i, j int) bool { return depth(categories[i]) < depth(categories[j]) })
+}
+
+func cloneMenusInto(tx *gorm.DB, sourceID uuid.UUID, target *db.Restaurant, replace, resetPrices bool, response *CloneRestaurantResponse) error {
+	targetID := target.ID
+	var categories []db.MenuCategory
+	if err := tx.Where("restaurant_id = ?", sourceID).Order("sort_order ASC").Find(&categories).Error; err != nil {
+		return err
+	}
+	var menus []db.Menu
+	if err := withMenuOptions(tx).Where("restaurant_id = ?", sourceID).Order("sort_order ASC, created_at ASC").Find(&menus).Error; err != nil {
+		return err
+	}
+	var rules []db.MenuAvailability
+	if err := tx.Where("restaurant_id = ?", sourceID).Find(&rules).Error; err != nil {
+		return err
+	}
+
+	takenSKUs := make(map[string]bool)
+	if replace {
+		// Variants, option groups and the rules of deleted items and
+		// categories go with them
+		if err := tx.Where("restaurant_id = ?", targetID).Delete(&db.MenuAvailability{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("restaurant_id = ?", targetID).Delete(&db.Menu{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("restaurant_id = ?", targetID).Delete(&db.MenuCategory{}).Error; err != nil {
+			return err
+		}
+	} else {
+		var skus []string
+		if err := tx.Model(&db.Menu{}).Where("restaurant_id = ? AND sku <> ''", targetID).Pluck("sku", &skus).Error; err != nil {
+			return err
+		}
+		for _, sku := range skus {
+			takenSKUs[sku] = true
+		}
+	}
+
+	copied := copyMenus(targetID, restaurantCurrency(*target), categories, menus, rules, resetPrices, takenSKUs)
+	if !replace {
+		// Appended top-level categories and uncategorized items go after
+		// the target's own
+		var existing []db.MenuCategory
+		if err := tx.Where("restaurant_id = ?", targetID).Find(&existing).Error; err != nil {
+			return err
+		}
+		offset := nextCategorySortOrder(existing, nil)
+		for i := range copied.categories {
+			if copied.categories[i].ParentID == nil {
+				copied.categories[i].SortOrder += offset
+			}
+		}
+		var maxOrder *int
+		if err := tx.Model(&db.Menu{}).Select("MAX(sort_order)").Where("restaurant_id = ? AND category_id IS NULL", targetID).Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+		if maxOrder != nil {
+			for i := range copied.menus {
+				if copied.menus[i].CategoryID == nil {
+					copied.menus[i].SortOrder += *maxOrder + 1
+				}
+			}
+		}
+	}
+
+	for i := range copied.categories {
+		if err := tx.Create(&copied.categories[i]).Error; err != nil {
+			return err
+		}
+	}
+	for i := range copied.menus {
+		menu := &copied.menus[i]
+		if err := tx.Omit(clause.Associations).Create(menu).Error; err != nil {
+			return err
+		}
+		for j := range menu.Variants {
+			if err := tx.Create(&menu.Variants[j]).Error; err != nil {
+				return err
+			}
+		}
+		for j := range menu.OptionGroups {
+			if err := tx.Omit(clause.Associations).Create(&menu.OptionGroups[j]).Error; err != nil {
+				return err
+			}
+			for k := range menu.OptionGroups[j].Options {
+				if err := tx.Create(&menu.OptionGroups[j].Options[k]).Error; err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for i := range copied.rules {
+		if err := tx.Create(&copied.rules[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	response.Categories = len(copied.categories)
+	response.Menus = len(copied.menus)
+	response.ClearedSKUs = append(response.ClearedSKUs, copied.clearedSKUs...)
+	return nil
+}
+
+func cloneCoursesInto(tx *gorm.DB, sourceID uuid.UUID, target *db.Restaurant, replace, resetPrices bool, response *CloneRestaurantResponse) error {
+	targetID, currency := target.ID, restaurantCurrency(*target)
+	var courses []db.Course
+	if err := tx.Where("restaurant_id = ?", sourceID).Order("created_at ASC").Find(&courses).Error; err != nil {
+		return err
+	}
+	if replace {
+		if err := tx.Where("restaurant_id = ?", targetID).Delete(&db.Course{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, course := range courses {
+		course.ID = uuid.New()
+		course.RestaurantID = targetID
+		course.CreatedAt, course.UpdatedAt = time.Now(), time.Now()
+		course.CoursePrice = clonedPrice(course.CoursePrice, currency, resetPrices)
+		course.OriginalPrice = clonedPrice(course.OriginalPrice, currency, resetPrices)
+		if err := tx.Create(&course).Error; err != nil {
+			return err
+		}
+	}
+	response.Courses = len(courses)
+	return nil
+}
+
+// clonedPrice returns a copied price in the target's currency, keeping its
+// face value (see money.Money.Rescale), or zero when prices are reset
+func clonedPrice(price money.Money, currency string, reset bool) money.Money {
+	if reset {
+		return money.New(0, currency)
+	}
+	return price.Rescale(currency)
+}
+
+func cloneHoursInto(tx *gorm.DB, sourceID, targetID uuid.UUID, response *CloneRestaurantResponse) error {
+	var hours []db.OpeningHour
+	if err := tx.Where("restaurant_id = ?", sourceID).Order("weekday ASC").Find(&hours).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("restaurant_id = ?", targetID).Delete(&db.OpeningHour{}).Error; err != nil {
+		return err
+	}
+	for _, hour := range hours {
+		hour.ID = uuid.New()
+		hour.RestaurantID = targetID
+		if err := tx.Create(&hour).Error; err != nil {
+			return err
+		}
+	}
+	response.OpeningHours = len(hours)
+	return nil
+}
+
+// cloneImagesInto copies the image records; the files themselves are
+// shared. The source's main image becomes the target's when the target has
+// none.
+func cloneImagesInto(tx *gorm.DB, source, target *db.Restaurant, replace bool, response *CloneRestaurantResponse) error {
+	var images []db.Image
+	if err := tx.Where("restaurant_id = ?", source.ID).Order("display_order ASC").Find(&images).Error; err != nil {
+		return err
+	}
+
+	mainImageID := target.MainImageID
+	offset := int64(0)
+	if replace {
+		if err := tx.Where("restaurant_id = ?", target.ID).Delete(&db.Image{}).Error; err != nil {
+			return err
+		}
+		mainImageID = nil
+	} else {
+		var maxOrder *int64
+		if err := tx.Model(&db.Image{}).Select("MAX(display_order)").Where("restaurant_id = ?", target.ID).Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+		if maxOrder != nil {
+			offset = *maxOrder + 1
+		}
+	}
+
+	setMain := mainImageID == nil
+	for _, image := range images {
+		isSourceMain := source.MainImageID != nil && *source.MainImageID == image.ID
+		image.ID = uuid.New()
+		image.RestaurantID = target.ID
+		image.DisplayOrder += offset
+		image.IsMain = setMain && isSourceMain
+		if image.IsMain {
+			id := image.ID
+			mainImageID = &id
+		}
+		if err := tx.Create(&image).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(target).Update("main_image_id", mainImageID).Error; err != nil {
+		return err
+	}
+	response.Images = len(images)
+	return nil
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyMenus(t *testing.T) {
+	target := uuid.New()
+	food := category("Food", nil, 0)
+	momo := category("Momo", &food, 0)
+	drinks := category("Drinks", nil, 1)
+
+	steamed := menuIn("Steamed", &momo, 0)
+	steamed.SKU = "M-1"
+	steamed.Price = npr(250)
+	steamed.Variants = []db.MenuVariant{{ID: uuid.New(), MenuID: steamed.ID, Name: "10 pcs", Price: npr(250)}}
+	group := db.MenuOptionGroup{ID: uuid.New(), MenuID: steamed.ID, Name: "Sauce"}
+	group.Options = []db.MenuOption{{ID: uuid.New(), GroupID: group.ID, Name: "Extra", PriceDelta: npr(30)}}
+	steamed.OptionGroups = []db.MenuOptionGroup{group}
+	lassi := menuIn("Lassi", &drinks, 0)
+	lassi.SKU = "D-1"
+
+	rules := []db.MenuAvailability{
+		{ID: uuid.New(), CategoryID: &food.ID, Weekdays: 127, StartTime: "11:00", EndTime: "22:00"},
+		{ID: uuid.New(), MenuID: &lassi.ID, Weekdays: 127},
+	}
+
+	// Children listed before their parents are still inserted after them
+	out := copyMenus(target, "NPR", []db.MenuCategory{momo, drinks, food}, []db.Menu{steamed, lassi}, rules, true, map[string]bool{"D-1": true})
+
+	require.Len(t, out.categories, 3)
+	newIDs := map[uuid.UUID]bool{}
+	for _, c := range out.categories {
+		assert.Equal(t, target, c.RestaurantID)
+		assert.NotContains(t, []uuid.UUID{food.ID, momo.ID, drinks.ID}, c.ID)
+		if c.ParentID != nil {
+			assert.True(t, newIDs[*c.ParentID], "parent of %s inserted first", c.Name)
+		}
+		newIDs[c.ID] = true
+	}
+
+	require.Len(t, out.menus, 2)
+	copiedSteamed, copiedLassi := out.menus[0], out.menus[1]
+	assert.True(t, newIDs[*copiedSteamed.CategoryID])
+	assert.Equal(t, "M-1", copiedSteamed.SKU)
+	assert.Equal(t, npr(0), copiedSteamed.Price)
+	assert.Equal(t, npr(0), copiedSteamed.Variants[0].Price)
+	assert.Equal(t, copiedSteamed.ID, copiedSteamed.Variants[0].MenuID)
+	assert.Equal(t, copiedSteamed.ID, copiedSteamed.OptionGroups[0].MenuID)
+	assert.Equal(t, copiedSteamed.OptionGroups[0].ID, copiedSteamed.OptionGroups[0].Options[0].GroupID)
+	assert.Equal(t, npr(0), copiedSteamed.OptionGroups[0].Options[0].PriceDelta)
+	assert.Equal(t, "", copiedLassi.SKU)
+	assert.Equal(t, []string{"D-1"}, out.clearedSKUs)
+
+	// The source is left untouched
+	assert.Equal(t, npr(250), steamed.Variants[0].Price)
+	assert.Equal(t, npr(30), steamed.OptionGroups[0].Options[0].PriceDelta)
+
+	require.Len(t, out.rules, 2)
+	assert.True(t, newIDs[*out.rules[0].CategoryID])
+	assert.Equal(t, copiedLassi.ID, *out.rules[1].MenuID)
+}
+
+func TestClonedPrice(t *testing.T) {
+	assert.Equal(t, npr(250), clonedPrice(npr(250), "NPR", false))
+	assert.Equal(t, money.New(250, "JPY"), clonedPrice(npr(250), "JPY", false), "face value kept")
+	assert.Equal(t, money.New(0, "JPY"), clonedPrice(npr(250), "JPY", true))
+}
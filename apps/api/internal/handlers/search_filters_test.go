@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func searchContext(target string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c
+}
+
+func TestFacetFilters(t *testing.T) {
+	var filters services.SearchFilters
+	c := searchContext("/search?cuisine=Newari&cuisine=Thakali&cuisine=+&area=Thamel&place=Kathmandu&rating=4,3&rating=0")
+	require.NoError(t, facetFilters(c, &filters))
+	assert.Equal(t, []string{"Newari", "Thakali"}, filters.Cuisines)
+	assert.Equal(t, []string{"Thamel"}, filters.Areas)
+	assert.Equal(t, []string{"Kathmandu"}, filters.Places)
+	assert.Equal(t, []int{4, 3, 0}, filters.RatingBands)
+
+	var none services.SearchFilters
+	require.NoError(t, facetFilters(searchContext("/search"), &none))
+	assert.Nil(t, none.Cuisines)
+	assert.Nil(t, none.RatingBands)
+
+	assert.Error(t, facetFilters(searchContext("/search?rating=5"), &services.SearchFilters{}))
+	assert.Error(t, facetFilters(searchContext("/search?rating=good"), &services.SearchFilters{}))
+}
+
+func TestSearchSuggestions_ShortQuery(t *testing.T) {
+	// Too short to suggest anything, so the database is not asked
+	h := &PublicHandler{}
+	for _, q := range []string{"", "m", "%20m%20", "ね"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/search/suggestions?q="+q, nil)
+		h.SearchSuggestions(c)
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"suggestions": []}`, w.Body.String())
+	}
+}
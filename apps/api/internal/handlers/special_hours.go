@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SpecialHourRequest sets a restaurant's hours on one date, replacing its
+// weekly hours. Times are required unless it is closed.
+type SpecialHourRequest struct {
+	OpenTime  string `json:"openTime"`  // Format: "09:00"
+	CloseTime string `json:"closeTime"` // Format: "23:00"; before openTime for hours past midnight
+	IsClosed  bool   `json:"isClosed"`
+	Note      string `json:"note"` // Shown to customers, e.g. "Closed for Dashain"
+}
+
+type SpecialHourResponse struct {
+	Date      string `json:"date"`
+	OpenTime  string `json:"openTime,omitempty"`
+	CloseTime string `json:"closeTime,omitempty"`
+	IsClosed  bool   `json:"isClosed"`
+	Note      string `json:"note,omitempty"`
+}
+
+func toSpecialHourResponse(sh db.SpecialHour) SpecialHourResponse {
+	return SpecialHourResponse{
+		Date:      sh.Date,
+		OpenTime:  sh.OpenTime,
+		CloseTime: sh.CloseTime,
+		IsClosed:  sh.IsClosed,
+		Note:      sh.Note,
+	}
+}
+
+// GET /api/owner/restaurants/:id/special-hours - List special hours from a date, today by default (restaurant:read)
+func (h *RestaurantHandler) ListSpecialHours(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	from := c.Query("from")
+	if from == "" {
+		from = time.Now().In(services.RestaurantLocation(*restaurant)).Format("2006-01-02")
+	} else if err := services.ParseDate(from); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var hours []db.SpecialHour
+	if err := h.DB.Where("restaurant_id = ? AND date >= ?", restaurant.ID, from).Order("date ASC").Find(&hours).Error; err != nil {
+		c.JSON(500, gin.H{"error": "failed to fetch special hours"})
+		return
+	}
+
+	response := make([]SpecialHourResponse, 0, len(hours))
+	for _, sh := range hours {
+		response = append(response, toSpecialHourResponse(sh))
+	}
+	c.JSON(200, response)
+}
+
+// PUT /api/owner/restaurants/:id/special-hours/:date - Set special hours of a date (restaurant:write)
+func (h *RestaurantHandler) SetSpecialHours(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	date := c.Param("date")
+	if err := services.ParseDate(date); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req SpecialHourRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateSpecialHours(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	sh := db.SpecialHour{
+		ID:           uuid.New(),
+		RestaurantID: restaurant.ID,
+		Date:         date,
+		OpenTime:     req.OpenTime,
+		CloseTime:    req.CloseTime,
+		IsClosed:     req.IsClosed,
+		Note:         req.Note,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var before interface{}
+		var existing db.SpecialHour
+		err := tx.Where("restaurant_id = ? AND date = ?", restaurant.ID, date).First(&existing).Error
+		switch {
+		case err == nil:
+			before = toSpecialHourResponse(existing)
+		case err != gorm.ErrRecordNotFound:
+			return err
+		}
+
+		// Another request may set the same date at the same time
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "restaurant_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"open_time", "close_time", "is_closed", "note", "updated_at"}),
+		}).Create(&sh).Error
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantHours,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     before,
+			After:      toSpecialHourResponse(sh),
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to save special hours"})
+		return
+	}
+
+	c.JSON(200, toSpecialHourResponse(sh))
+}
+
+// DELETE /api/owner/restaurants/:id/special-hours/:date - Remove special hours of a date (restaurant:write)
+func (h *RestaurantHandler) DeleteSpecialHours(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var sh db.SpecialHour
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("restaurant_id = ? AND date = ?", restaurant.ID, c.Param("date")).
+			First(&sh).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&sh).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantHours,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     toSpecialHourResponse(sh),
+		})
+	})
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(404, gin.H{"error": "special hours not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to delete special hours"})
+		return
+	}
+
+	c.Status(204)
+}
+
+// validateSpecialHours checks the times of special hours, which are left
+// out when the restaurant is closed
+func validateSpecialHours(req *SpecialHourRequest) error {
+	if req.IsClosed {
+		req.OpenTime, req.CloseTime = "", ""
+		return nil
+	}
+	if _, err := services.ParseClock(req.OpenTime); err != nil {
+		return fmt.Errorf("openTime: %w", err)
+	}
+	if _, err := services.ParseClock(req.CloseTime); err != nil {
+		return fmt.Errorf("closeTime: %w", err)
+	}
+	if req.OpenTime == req.CloseTime {
+		return fmt.Errorf("closeTime must differ from openTime")
+	}
+	return nil
+}
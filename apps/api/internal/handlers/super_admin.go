@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/example/restosaas/apps/api/internal/audit"
 	"github.com/example/restosaas/apps/api/internal/auth"
 	"github.com/example/restosaas/apps/api/internal/db"
 	"github.com/gin-gonic/gin"
@@ -105,7 +106,7 @@ func (h *SuperAdminHandler) CreateOwner(c *gin.Context) {
 		ID:     uuid.New(),
 		UserID: user.ID,
 		OrgID:  org.ID,
-		Role:   db.RoleOwner,
+		Role:   db.OrgRoleOwner,
 	}
 
 	if err := tx.Create(&orgMember).Error; err != nil {
@@ -350,7 +351,18 @@ func (h *SuperAdminHandler) DeleteUser(c *gin.Context) {
 	}
 
 	// Delete user (GORM will handle cascade deletes based on foreign key constraints)
-	if err := h.DB.Delete(&user).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionUserDelete,
+			EntityType: "user",
+			EntityID:   user.ID.String(),
+			Before:     user,
+		})
+	})
+	if err != nil {
 		c.JSON(500, gin.H{"error": "failed to delete user"})
 		return
 	}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"github.com/example/restosaas/apps/api/internal/audit"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TranslationsResponse lists an entity's translations and what can be
+// translated
+type TranslationsResponse struct {
+	Locales      []string        `json:"locales"` // Supported locales
+	Fields       []string        `json:"fields"`  // Translatable fields
+	Translations db.Translations `json:"translations"`
+}
+
+func toTranslationsResponse(translations db.Translations, fields []string) TranslationsResponse {
+	if translations == nil {
+		translations = db.Translations{}
+	}
+	return TranslationsResponse{Locales: i18n.Locales, Fields: fields, Translations: translations}
+}
+
+// requestLocales returns the locales to show public content in, from the
+// lang query parameter and the Accept-Language header
+func requestLocales(c *gin.Context) []string {
+	c.Header("Vary", "Accept-Language")
+	return i18n.Chain(c.Query("lang"), c.GetHeader("Accept-Language"))
+}
+
+// translationFields reads the fields of a translation to set, an object of
+// field names to text
+func translationFields(c *gin.Context) (map[string]string, bool) {
+	fields := map[string]string{}
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return fields, true
+}
+
+// GetRestaurantTranslations godoc
+// @Summary Get restaurant translations
+// @Description Get a restaurant's translated text by locale
+// @Tags restaurants
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} TranslationsResponse
+// @Failure 404 {object} map[string]string
+// @Router /owner/restaurants/{id}/translations [get]
+func (h *RestaurantHandler) GetRestaurantTranslations(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+	c.JSON(200, toTranslationsResponse(restaurant.Translations, i18n.RestaurantFields))
+}
+
+// SetRestaurantTranslation godoc
+// @Summary Set a restaurant translation
+// @Description Replace a restaurant's translated text for one locale; empty fields fall back to other locales
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param locale path string true "Locale: en, ne, ja or zh"
+// @Param fields body map[string]string true "Text by field, e.g. {\"name\": \"...\"}"
+// @Success 200 {object} TranslationsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{id}/translations/{locale} [put]
+func (h *RestaurantHandler) SetRestaurantTranslation(c *gin.Context) {
+	fields, ok := translationFields(c)
+	if !ok {
+		return
+	}
+	h.saveRestaurantTranslation(c, fields)
+}
+
+// DeleteRestaurantTranslation godoc
+// @Summary Remove a restaurant translation
+// @Description Remove a restaurant's translated text for one locale
+// @Tags restaurants
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param locale path string true "Locale: en, ne, ja or zh"
+// @Success 200 {object} TranslationsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{id}/translations/{locale} [delete]
+func (h *RestaurantHandler) DeleteRestaurantTranslation(c *gin.Context) {
+	h.saveRestaurantTranslation(c, map[string]string{})
+}
+
+// saveRestaurantTranslation sets the fields of the :locale translation,
+// removing it when they are all empty
+func (h *RestaurantHandler) saveRestaurantTranslation(c *gin.Context, fields map[string]string) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	translations, err := i18n.Set(restaurant.Translations, c.Param("locale"), fields, i18n.RestaurantFields)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(restaurant).Update("translations", translations).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionRestaurantUpdate,
+			EntityType: "restaurant",
+			EntityID:   restaurant.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     gin.H{"translations": restaurant.Translations},
+			After:      gin.H{"translations": translations},
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update translations"})
+		return
+	}
+
+	c.JSON(200, toTranslationsResponse(translations, i18n.RestaurantFields))
+}
+
+// GetMenuTranslations godoc
+// @Summary Get menu item translations
+// @Description Get a menu item's translated text by locale
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param menuId path string true "Menu ID"
+// @Success 200 {object} TranslationsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/{menuId}/translations [get]
+func (h *MenuHandler) GetMenuTranslations(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	menu, ok := h.menuParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	c.JSON(200, toTranslationsResponse(menu.Translations, i18n.MenuFields))
+}
+
+// SetMenuTranslation godoc
+// @Summary Set a menu item translation
+// @Description Replace a menu item's translated text for one locale; empty fields fall back to other locales
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param menuId path string true "Menu ID"
+// @Param locale path string true "Locale: en, ne, ja or zh"
+// @Param fields body map[string]string true "Text by field, e.g. {\"name\": \"...\"}"
+// @Success 200 {object} TranslationsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/{menuId}/translations/{locale} [put]
+func (h *MenuHandler) SetMenuTranslation(c *gin.Context) {
+	fields, ok := translationFields(c)
+	if !ok {
+		return
+	}
+	h.saveMenuTranslation(c, fields)
+}
+
+// DeleteMenuTranslation godoc
+// @Summary Remove a menu item translation
+// @Description Remove a menu item's translated text for one locale
+// @Tags menus
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param menuId path string true "Menu ID"
+// @Param locale path string true "Locale: en, ne, ja or zh"
+// @Success 200 {object} TranslationsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/menus/{menuId}/translations/{locale} [delete]
+func (h *MenuHandler) DeleteMenuTranslation(c *gin.Context) {
+	h.saveMenuTranslation(c, map[string]string{})
+}
+
+// saveMenuTranslation sets the fields of the :locale translation of the
+// menu item, removing it when they are all empty
+func (h *MenuHandler) saveMenuTranslation(c *gin.Context, fields map[string]string) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	menu, ok := h.menuParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	translations, err := i18n.Set(menu.Translations, c.Param("locale"), fields, i18n.MenuFields)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(menu).Update("translations", translations).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionMenuUpdate,
+			EntityType: "menu",
+			EntityID:   menu.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     gin.H{"translations": menu.Translations},
+			After:      gin.H{"translations": translations},
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update translations"})
+		return
+	}
+
+	c.JSON(200, toTranslationsResponse(translations, i18n.MenuFields))
+}
+
+// GetCourseTranslations godoc
+// @Summary Get course translations
+// @Description Get a course's translated text by locale
+// @Tags courses
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param courseId path string true "Course ID"
+// @Success 200 {object} TranslationsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/courses/{courseId}/translations [get]
+func (h *CourseHandler) GetCourseTranslations(c *gin.Context) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	course, ok := h.courseParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	c.JSON(200, toTranslationsResponse(course.Translations, i18n.CourseFields))
+}
+
+// SetCourseTranslation godoc
+// @Summary Set a course translation
+// @Description Replace a course's translated text for one locale; empty fields fall back to other locales
+// @Tags courses
+// @Accept json
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param courseId path string true "Course ID"
+// @Param locale path string true "Locale: en, ne, ja or zh"
+// @Param fields body map[string]string true "Text by field, e.g. {\"title\": \"...\"}"
+// @Success 200 {object} TranslationsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/courses/{courseId}/translations/{locale} [put]
+func (h *CourseHandler) SetCourseTranslation(c *gin.Context) {
+	fields, ok := translationFields(c)
+	if !ok {
+		return
+	}
+	h.saveCourseTranslation(c, fields)
+}
+
+// DeleteCourseTranslation godoc
+// @Summary Remove a course translation
+// @Description Remove a course's translated text for one locale
+// @Tags courses
+// @Produce json
+// @Param restaurantId path string true "Restaurant ID"
+// @Param courseId path string true "Course ID"
+// @Param locale path string true "Locale: en, ne, ja or zh"
+// @Success 200 {object} TranslationsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /owner/restaurants/{restaurantId}/courses/{courseId}/translations/{locale} [delete]
+func (h *CourseHandler) DeleteCourseTranslation(c *gin.Context) {
+	h.saveCourseTranslation(c, map[string]string{})
+}
+
+// saveCourseTranslation sets the fields of the :locale translation of the
+// course, removing it when they are all empty
+func (h *CourseHandler) saveCourseTranslation(c *gin.Context, fields map[string]string) {
+	restaurant := authz.ScopedRestaurant(c)
+
+	course, ok := h.courseParam(c, restaurant.ID)
+	if !ok {
+		return
+	}
+	translations, err := i18n.Set(course.Translations, c.Param("locale"), fields, i18n.CourseFields)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(course).Update("translations", translations).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.Entry{
+			Action:     audit.ActionCourseUpdate,
+			EntityType: "course",
+			EntityID:   course.ID.String(),
+			OrgID:      &restaurant.OrgID,
+			Before:     gin.H{"translations": course.Translations},
+			After:      gin.H{"translations": translations},
+		})
+	})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "failed to update translations"})
+		return
+	}
+
+	c.JSON(200, toTranslationsResponse(translations, i18n.CourseFields))
+}
+
+func (h *CourseHandler) courseParam(c *gin.Context, restaurantID uuid.UUID) (*db.Course, bool) {
+	courseID, err := uuid.Parse(c.Param("courseId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid course ID"})
+		return nil, false
+	}
+
+	var course db.Course
+	if err := h.DB.Where("id = ? AND restaurant_id = ?", courseID, restaurantID).First(&course).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(404, gin.H{"error": "course not found"})
+			return nil, false
+		}
+		c.JSON(500, gin.H{"error": "failed to fetch course"})
+		return nil, false
+	}
+	return &course, true
+}
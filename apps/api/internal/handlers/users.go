@@ -10,12 +10,18 @@ import (
 
 	"github.com/example/restosaas/apps/api/internal/auth"
 	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/notify"
+	"github.com/example/restosaas/apps/api/internal/ratelimit"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type UserHandler struct{ DB *gorm.DB }
+type UserHandler struct {
+	DB     *gorm.DB
+	Guard  *ratelimit.LoginGuard // Optional; login is unthrottled when nil
+	Mailer notify.Mailer         // Used for lockout notifications
+}
 
 // Request/Response DTOs
 type CreateUserRequest struct {
@@ -317,6 +323,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // @Success 200 {object} UserWithTokenResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
@@ -326,6 +333,16 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Throttle per account before touching the database
+	if h.Guard != nil {
+		res, err := h.Guard.AllowAccount(req.Email)
+		if err == nil && !res.Allowed {
+			ratelimit.SetRetryAfter(c, res.RetryAfter)
+			c.JSON(429, gin.H{"error": "too many login attempts, try again later"})
+			return
+		}
+	}
+
 	// Hash provided password
 	hasher := sha256.New()
 	hasher.Write([]byte(req.Password))
@@ -334,10 +351,17 @@ func (h *UserHandler) Login(c *gin.Context) {
 	// Find user
 	var user db.User
 	if err := h.DB.Where("email = ? AND password = ?", req.Email, hashedPassword).First(&user).Error; err != nil {
+		h.recordLoginFailure(req.Email)
 		c.JSON(401, gin.H{"error": "invalid credentials"})
 		return
 	}
 
+	if h.Guard != nil {
+		if err := h.Guard.RecordSuccess(req.Email); err != nil {
+			fmt.Printf("failed to reset login failures for %s: %v\n", req.Email, err)
+		}
+	}
+
 	// Generate JWT token
 	token, err := auth.IssueToken(user.ID.String(), string(user.Role))
 	if err != nil {
@@ -400,3 +424,36 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 
 	c.JSON(200, response)
 }
+
+// recordLoginFailure counts a failed login and, when it triggers a lockout,
+// notifies the account holder. Unknown emails are counted but never mailed.
+func (h *UserHandler) recordLoginFailure(email string) {
+	if h.Guard == nil {
+		return
+	}
+	locked, until, err := h.Guard.RecordFailure(email)
+	if err != nil {
+		fmt.Printf("failed to record login failure for %s: %v\n", email, err)
+		return
+	}
+	if !locked || h.Mailer == nil {
+		return
+	}
+
+	var user db.User
+	if err := h.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		return
+	}
+
+	msg := notify.Message{
+		To:      user.Email,
+		Subject: "Your account has been temporarily locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe noticed several failed sign-in attempts on your account. "+
+			"For your security, sign-in is locked until %s.\n\n"+
+			"If this wasn't you, consider changing your password once the lock expires.\n",
+			user.DisplayName, until.UTC().Format(time.RFC1123)),
+	}
+	if err := h.Mailer.Send(msg); err != nil {
+		fmt.Printf("failed to send lockout notification to %s: %v\n", user.Email, err)
+	}
+}
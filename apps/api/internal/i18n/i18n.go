@@ -0,0 +1,186 @@
+// Package i18n resolves which language to show translatable content in.
+// Restaurants, menu items and courses keep their text as written by the
+// owner, plus optional translations per locale.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+)
+
+// Supported locales
+const (
+	English  = "en"
+	Nepali   = "ne"
+	Japanese = "ja"
+	Chinese  = "zh"
+)
+
+// Locales lists the supported locales
+var Locales = []string{English, Nepali, Japanese, Chinese}
+
+// Default is tried after the requested locales, before the untranslated
+// text
+const Default = English
+
+// Normalize maps a language tag such as "ja-JP" or "ZH_hant" to a supported
+// locale
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, locale := range Locales {
+		if tag == locale {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// ParseLocale validates a locale given by an owner
+func ParseLocale(tag string) (string, error) {
+	locale, ok := Normalize(tag)
+	if !ok {
+		return "", fmt.Errorf("unsupported locale %q, expected one of %s", tag, strings.Join(Locales, ", "))
+	}
+	return locale, nil
+}
+
+// ParseAcceptLanguage returns the supported locales of an Accept-Language
+// header, most preferred first
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var candidates []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if locale, ok := Normalize(tag); ok && q > 0 {
+			candidates = append(candidates, weighted{locale, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	var locales []string
+	for _, c := range candidates {
+		locales = appendUnique(locales, c.locale)
+	}
+	return locales
+}
+
+// Chain returns the locales to try, in order: lang (a ?lang= parameter)
+// if supported, then the Accept-Language preferences, then Default
+func Chain(lang, acceptLanguage string) []string {
+	var chain []string
+	if locale, ok := Normalize(lang); ok {
+		chain = append(chain, locale)
+	}
+	for _, locale := range ParseAcceptLanguage(acceptLanguage) {
+		chain = appendUnique(chain, locale)
+	}
+	return appendUnique(chain, Default)
+}
+
+// Text returns the field's translation for the first locale of chain that
+// has one, or else the untranslated text
+func Text(translations db.Translations, chain []string, field, untranslated string) string {
+	for _, locale := range chain {
+		if value := translations[locale][field]; value != "" {
+			return value
+		}
+	}
+	return untranslated
+}
+
+// Set replaces the fields of one locale, allowing only the given field
+// names. Empty values are dropped, and a locale left without fields is
+// removed. The translations passed in are not modified.
+func Set(translations db.Translations, locale string, fields map[string]string, allowed []string) (db.Translations, error) {
+	locale, err := ParseLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(fields))
+	for field, value := range fields {
+		if !contains(allowed, field) {
+			return nil, fmt.Errorf("field %q cannot be translated, expected one of %s", field, strings.Join(allowed, ", "))
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			values[field] = value
+		}
+	}
+
+	out := make(db.Translations, len(translations)+1)
+	for l, f := range translations {
+		if l != locale {
+			out[l] = f
+		}
+	}
+	if len(values) > 0 {
+		out[locale] = values
+	}
+	return out, nil
+}
+
+func appendUnique(list []string, value string) []string {
+	if contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Translatable fields, by their JSON names
+var (
+	RestaurantFields = []string{"name", "slogan", "title", "description"}
+	MenuFields       = []string{"name", "shortDesc"}
+	CourseFields     = []string{"title", "description", "courseContent", "precautions"}
+)
+
+// LocalizeRestaurant replaces the restaurant's text with its translations
+// for chain
+func LocalizeRestaurant(r *db.Restaurant, chain []string) {
+	r.Name = Text(r.Translations, chain, "name", r.Name)
+	r.Slogan = Text(r.Translations, chain, "slogan", r.Slogan)
+	r.Title = Text(r.Translations, chain, "title", r.Title)
+	r.Description = Text(r.Translations, chain, "description", r.Description)
+}
+
+// LocalizeMenu replaces the menu item's text with its translations for
+// chain
+func LocalizeMenu(m *db.Menu, chain []string) {
+	m.Name = Text(m.Translations, chain, "name", m.Name)
+	m.ShortDesc = Text(m.Translations, chain, "shortDesc", m.ShortDesc)
+}
+
+// LocalizeCourse replaces the course's text with its translations for
+// chain
+func LocalizeCourse(c *db.Course, chain []string) {
+	c.Title = Text(c.Translations, chain, "title", c.Title)
+	c.Description = Text(c.Translations, chain, "description", c.Description)
+	c.CourseContent = Text(c.Translations, chain, "courseContent", c.CourseContent)
+	c.Precautions = Text(c.Translations, chain, "precautions", c.Precautions)
+}
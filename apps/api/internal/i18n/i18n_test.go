@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	for tag, want := range map[string]string{"ja": Japanese, "ja-JP": Japanese, " ZH_hant ": Chinese, "ne-NP": Nepali} {
+		got, ok := Normalize(tag)
+		assert.True(t, ok, tag)
+		assert.Equal(t, want, got, tag)
+	}
+
+	_, ok := Normalize("fr")
+	assert.False(t, ok)
+	_, err := ParseLocale("")
+	assert.Error(t, err)
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{Japanese, English, Chinese}, ParseAcceptLanguage("fr-FR, zh;q=0.5, ja-JP;q=0.9, en;q=0.8, ja;q=0.7"))
+	assert.Equal(t, []string{Nepali}, ParseAcceptLanguage("ne, en;q=0, ja;q=bad"))
+	assert.Empty(t, ParseAcceptLanguage(""))
+}
+
+func TestChain(t *testing.T) {
+	assert.Equal(t, []string{Chinese, Japanese, English}, Chain("zh", "ja, zh;q=0.5"))
+	assert.Equal(t, []string{Japanese, English}, Chain("xx", "ja"))
+	assert.Equal(t, []string{English}, Chain("", ""))
+}
+
+func TestText(t *testing.T) {
+	translations := db.Translations{
+		Japanese: {"name": "モモ"},
+		English:  {"name": "Momo", "shortDesc": "Dumplings"},
+	}
+	assert.Equal(t, "モモ", Text(translations, []string{Japanese, English}, "name", "मम"))
+	assert.Equal(t, "Dumplings", Text(translations, []string{Japanese, English}, "shortDesc", ""))
+	assert.Equal(t, "मम", Text(translations, []string{Chinese}, "name", "मम"))
+	assert.Equal(t, "मम", Text(nil, []string{Chinese}, "name", "मम"))
+}
+
+func TestSet(t *testing.T) {
+	translations := db.Translations{English: {"name": "Momo"}}
+
+	got, err := Set(translations, "ja-JP", map[string]string{"name": " モモ ", "shortDesc": ""}, MenuFields)
+	require.NoError(t, err)
+	assert.Equal(t, db.Translations{English: {"name": "Momo"}, Japanese: {"name": "モモ"}}, got)
+	assert.Len(t, translations, 1, "input is not modified")
+
+	got, err = Set(got, "en", nil, MenuFields)
+	require.NoError(t, err)
+	assert.Equal(t, db.Translations{Japanese: {"name": "モモ"}}, got)
+
+	_, err = Set(got, "ja", map[string]string{"price": "100"}, MenuFields)
+	assert.Error(t, err)
+	_, err = Set(got, "fr", map[string]string{"name": "Momo"}, MenuFields)
+	assert.Error(t, err)
+}
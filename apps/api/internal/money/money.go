@@ -0,0 +1,242 @@
+// Package money represents prices as an integer amount of a currency's
+// minor unit (paisa, cents) together with the ISO 4217 currency code, so
+// prices never go through floating point.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Money is an amount in minor units of Currency. Embed it in a model with
+// gorm:"embedded;embeddedPrefix:<column>_" to store it as <column>_amount
+// and <column>_currency.
+type Money struct {
+	Amount   int64  `json:"amount" gorm:"not null;default:0"`
+	Currency string `json:"currency" gorm:"type:varchar(3);not null;default:'NPR'"`
+}
+
+// Currency describes how amounts of a currency are written
+type Currency struct {
+	Code     string
+	Exponent int    // Digits of the minor unit, 2 for paisa and cents, 0 for yen
+	Symbol   string // Written before the amount
+}
+
+// Default is the currency of restaurants that haven't chosen one, and of
+// prices stored before currencies were recorded
+const Default = "NPR"
+
+// Currencies lists the supported currencies by code
+var Currencies = map[string]Currency{
+	"NPR": {Code: "NPR", Exponent: 2, Symbol: "Rs"},
+	"INR": {Code: "INR", Exponent: 2, Symbol: "₹"},
+	"JPY": {Code: "JPY", Exponent: 0, Symbol: "¥"},
+	"CNY": {Code: "CNY", Exponent: 2, Symbol: "CN¥"},
+	"USD": {Code: "USD", Exponent: 2, Symbol: "$"},
+	"EUR": {Code: "EUR", Exponent: 2, Symbol: "€"},
+	"GBP": {Code: "GBP", Exponent: 2, Symbol: "£"},
+	"AUD": {Code: "AUD", Exponent: 2, Symbol: "A$"},
+}
+
+// localSymbols overrides a currency's symbol in a locale, e.g. रू for
+// rupees in Nepali
+var localSymbols = map[string]map[string]string{
+	"ne": {"NPR": "रू"},
+	"ja": {"JPY": "￥"},
+	"zh": {"CNY": "¥", "JPY": "JP¥"},
+}
+
+// ParseCurrency validates a currency code such as "npr"
+func ParseCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := Currencies[code]; !ok {
+		return "", fmt.Errorf("unsupported currency %q", code)
+	}
+	return code, nil
+}
+
+// Exponent returns the digits of the currency's minor unit, 2 for
+// currencies that aren't supported
+func Exponent(currency string) int {
+	if c, ok := Currencies[currency]; ok {
+		return c.Exponent
+	}
+	return 2
+}
+
+// New returns amount minor units of currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// FromMajor returns whole units of currency, e.g. FromMajor(250, "NPR") is
+// Rs 250.00
+func FromMajor(units int64, currency string) Money {
+	return Money{Amount: units * pow10(Exponent(currency)), Currency: currency}
+}
+
+// Parse reads a decimal amount in major units, such as "250" or "-12.5"
+func Parse(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+
+	exp := Exponent(currency)
+	if whole == "" || len(frac) > exp || strings.ContainsAny(whole+frac, "+-") {
+		return Money{}, fmt.Errorf("invalid %s amount %q", currency, s)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid %s amount %q", currency, s)
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// UnmarshalJSON reads an object with amount and currency or, as clients
+// sent prices before currencies were recorded, a bare integer of minor
+// units. A bare amount has no currency; handlers fill in the restaurant's.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' && !bytes.Equal(data, []byte("null")) {
+		var amount int64
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return fmt.Errorf("invalid price %s: expected an integer of minor units or an object with amount and currency", data)
+		}
+		*m = Money{Amount: amount}
+		return nil
+	}
+	type object Money // Without this method
+	var o object
+	if err := json.Unmarshal(data, &o); err != nil {
+		return err
+	}
+	*m = Money(o)
+	return nil
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns m + o; o is taken to be in m's currency
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}
+}
+
+// Rescale returns the same face value in another currency, so Rs 250.00
+// becomes ¥250. Digits the new currency can't hold are rounded.
+func (m Money) Rescale(currency string) Money {
+	from, to := Exponent(m.Currency), Exponent(currency)
+	amount := m.Amount
+	if to > from {
+		amount *= pow10(to - from)
+	} else if to < from {
+		amount = mulDiv(amount, 1, pow10(from-to))
+	}
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Decimal returns the amount in major units without grouping, such as
+// "1250.50", as used in CSV files
+func (m Money) Decimal() string {
+	whole, frac := m.split()
+	if m.Amount < 0 {
+		whole = "-" + whole
+	}
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// String returns the amount with its currency code, such as "NPR 1250.50"
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+// Format writes m for display in locale (see package i18n), such as
+// "Rs 1,250.00" in English or "रू 1,250.00" in Nepali. Nepali groups
+// digits in lakhs and crores: रू 12,34,567.00.
+func Format(m Money, locale string) string {
+	symbol := m.Currency
+	if c, ok := Currencies[m.Currency]; ok {
+		symbol = c.Symbol
+	}
+	if s, ok := localSymbols[locale][m.Currency]; ok {
+		symbol = s
+	}
+
+	whole, frac := m.split()
+	digits := group(whole, locale == "ne")
+	if frac != "" {
+		digits += "." + frac
+	}
+	if last, _ := utf8.DecodeLastRuneInString(symbol); unicode.IsLetter(last) || unicode.IsMark(last) {
+		symbol += " "
+	}
+	if m.Amount < 0 {
+		return "-" + symbol + digits
+	}
+	return symbol + digits
+}
+
+// split returns the digits of the absolute amount before and after the
+// decimal point
+func (m Money) split() (string, string) {
+	exp := Exponent(m.Currency)
+	amount := m.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	s := strconv.FormatInt(amount, 10)
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return s[:len(s)-exp], s[len(s)-exp:]
+}
+
+// group inserts thousands separators, or for indian the lakh and crore
+// separators of South Asia: the last three digits, then pairs
+func group(digits string, indian bool) string {
+	head, tail := digits, ""
+	if len(head) > 3 {
+		head, tail = digits[:len(digits)-3], ","+digits[len(digits)-3:]
+	}
+	size := 3
+	if indian {
+		size = 2
+	}
+	for len(head) > size && tail != "" {
+		tail = "," + head[len(head)-size:] + tail
+		head = head[:len(head)-size]
+	}
+	return head + tail
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// mulDiv returns a*b/c rounded half away from zero
+func mulDiv(a, b, c int64) int64 {
+	n := a * b
+	if (n < 0) != (c < 0) {
+		return (n - c/2) / c
+	}
+	return (n + c/2) / c
+}
@@ -0,0 +1,110 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+	}{
+		{"250", "NPR", 25000},
+		{"12.5", "NPR", 1250},
+		{"-30.25", "NPR", -3025},
+		{" 1250 ", "JPY", 1250},
+		{"0.05", "USD", 5},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in, tt.currency)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, New(tt.want, tt.currency), got, tt.in)
+	}
+
+	for _, bad := range []string{"", "abc", "1.234", ".5", "+5", "1.-5", "1,250"} {
+		_, err := Parse(bad, "NPR")
+		assert.Error(t, err, bad)
+	}
+	_, err := Parse("12.5", "JPY")
+	assert.Error(t, err)
+}
+
+func TestDecimal(t *testing.T) {
+	assert.Equal(t, "1250.50", New(125050, "NPR").Decimal())
+	assert.Equal(t, "0.05", New(5, "USD").Decimal())
+	assert.Equal(t, "-0.30", New(-30, "NPR").Decimal())
+	assert.Equal(t, "1250", New(1250, "JPY").Decimal())
+	assert.Equal(t, "NPR 250.00", FromMajor(250, "NPR").String())
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "Rs 1,234,567.00", Format(FromMajor(1234567, "NPR"), "en"))
+	assert.Equal(t, "रू 12,34,567.00", Format(FromMajor(1234567, "NPR"), "ne"))
+	assert.Equal(t, "रू 999.50", Format(New(99950, "NPR"), "ne"))
+	assert.Equal(t, "¥1,250", Format(New(1250, "JPY"), "en"))
+	assert.Equal(t, "￥1,250", Format(New(1250, "JPY"), "ja"))
+	assert.Equal(t, "-$0.30", Format(New(-30, "USD"), "en"))
+	assert.Equal(t, "XYZ 1.00", Format(New(100, "XYZ"), "en"))
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var prices struct {
+		Price    Money  `json:"price"`
+		Original *Money `json:"original"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"price": {"amount": 25000, "currency": "JPY"}}`), &prices))
+	assert.Equal(t, New(25000, "JPY"), prices.Price)
+	assert.Nil(t, prices.Original)
+
+	// Bare minor units, as sent before currencies were recorded
+	require.NoError(t, json.Unmarshal([]byte(`{"price": 25000, "original": 30000}`), &prices))
+	assert.Equal(t, Money{Amount: 25000}, prices.Price)
+	assert.Equal(t, &Money{Amount: 30000}, prices.Original)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"price": 250.5}`), &prices))
+	assert.Error(t, json.Unmarshal([]byte(`{"price": "250"}`), &prices))
+}
+
+func TestRescale(t *testing.T) {
+	assert.Equal(t, New(250, "JPY"), FromMajor(250, "NPR").Rescale("JPY"))
+	assert.Equal(t, New(251, "JPY"), New(25050, "NPR").Rescale("JPY"))
+	assert.Equal(t, New(25000, "USD"), New(250, "JPY").Rescale("USD"))
+}
+
+func TestParseCurrency(t *testing.T) {
+	code, err := ParseCurrency(" jpy ")
+	require.NoError(t, err)
+	assert.Equal(t, "JPY", code)
+
+	_, err = ParseCurrency("XYZ")
+	assert.Error(t, err)
+}
+
+func TestBreakdown(t *testing.T) {
+	nepal := Pricing{TaxRate: 1300, ServiceCharge: 1000}
+	assert.Equal(t, Breakdown{
+		Net:           FromMajor(1000, "NPR"),
+		ServiceCharge: FromMajor(100, "NPR"),
+		Tax:           FromMajor(143, "NPR"),
+		Total:         FromMajor(1243, "NPR"),
+	}, nepal.Breakdown(FromMajor(1000, "NPR")))
+
+	// Included tax and service charge are split back out of the price
+	nepal.TaxIncluded = true
+	got := nepal.Breakdown(FromMajor(1243, "NPR"))
+	assert.Equal(t, FromMajor(1000, "NPR"), got.Net)
+	assert.Equal(t, FromMajor(100, "NPR"), got.ServiceCharge)
+	assert.Equal(t, FromMajor(143, "NPR"), got.Tax)
+	assert.Equal(t, FromMajor(1243, "NPR"), got.Total)
+
+	// Rounding never loses the total
+	got = Pricing{TaxRate: 1000, TaxIncluded: true}.Breakdown(New(999, "JPY"))
+	assert.Equal(t, int64(999), got.Net.Amount+got.ServiceCharge.Amount+got.Tax.Amount)
+
+	assert.Equal(t, FromMajor(250, "NPR"), Pricing{}.Total(FromMajor(250, "NPR")))
+}
@@ -0,0 +1,48 @@
+package money
+
+// Pricing is how a restaurant charges on top of its prices. Rates are in
+// basis points, 1300 = 13%. The service charge is taxed along with the
+// price.
+type Pricing struct {
+	TaxRate       int
+	ServiceCharge int
+	TaxIncluded   bool // Prices already include the service charge and tax
+}
+
+// Breakdown is what a customer pays for a price
+type Breakdown struct {
+	Net           Money `json:"net"`
+	ServiceCharge Money `json:"serviceCharge"`
+	Tax           Money `json:"tax"`
+	Total         Money `json:"total"`
+}
+
+// Breakdown splits price into its net amount, service charge and tax. For
+// tax-included pricing the total is the price itself.
+func (p Pricing) Breakdown(price Money) Breakdown {
+	if p.TaxIncluded {
+		net := mulDiv(price.Amount, 10000*10000, int64(10000+p.ServiceCharge)*int64(10000+p.TaxRate))
+		service := mulDiv(net, int64(p.ServiceCharge), 10000)
+		return Breakdown{
+			Net:           New(net, price.Currency),
+			ServiceCharge: New(service, price.Currency),
+			Tax:           New(price.Amount-net-service, price.Currency),
+			Total:         price,
+		}
+	}
+
+	service := mulDiv(price.Amount, int64(p.ServiceCharge), 10000)
+	tax := mulDiv(price.Amount+service, int64(p.TaxRate), 10000)
+	return Breakdown{
+		Net:           price,
+		ServiceCharge: New(service, price.Currency),
+		Tax:           New(tax, price.Currency),
+		Total:         New(price.Amount+service+tax, price.Currency),
+	}
+}
+
+// Total returns what a customer pays for price, service charge and tax
+// included
+func (p Pricing) Total(price Money) Money {
+	return p.Breakdown(price).Total
+}
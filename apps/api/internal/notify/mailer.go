@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers notification emails
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// LogMailer writes messages to the application log instead of sending them.
+// It is the default when no SMTP server is configured.
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	log.Printf("mail to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// SMTPMailer sends messages through an SMTP relay
+type SMTPMailer struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	From     string
+}
+
+func (m SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host := m.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		m.From, msg.To, msg.Subject, msg.Body)
+	return smtp.SendMail(m.Addr, auth, m.From, []string{msg.To}, []byte(body))
+}
+
+// FromEnv returns an SMTPMailer when SMTP_ADDR is set, otherwise a LogMailer
+func FromEnv() Mailer {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		return LogMailer{}
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@restosaas.local"
+	}
+	return SMTPMailer{
+		Addr:     addr,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
@@ -0,0 +1,80 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// ProviderConfig configures one OpenID Connect identity provider
+type ProviderConfig struct {
+	Name         string   `json:"name"`        // URL-safe identifier, e.g. "microsoft"
+	DisplayName  string   `json:"displayName"` // Label for login buttons
+	Issuer       string   `json:"issuer"`      // Discovery base, e.g. https://login.example.com
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	RedirectURL  string   `json:"redirectUrl"`      // Default redirect URI
+	Scopes       []string `json:"scopes,omitempty"` // Defaults to openid email profile
+}
+
+var providerNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Validate checks that the required fields are present
+func (c ProviderConfig) Validate() error {
+	if !providerNameRe.MatchString(c.Name) {
+		return fmt.Errorf("oidc: invalid provider name %q", c.Name)
+	}
+	if c.Issuer == "" || c.ClientID == "" {
+		return fmt.Errorf("oidc: provider %q needs issuer and clientId", c.Name)
+	}
+	return nil
+}
+
+func (c ProviderConfig) scopes() []string {
+	if len(c.Scopes) == 0 {
+		return []string{"openid", "email", "profile"}
+	}
+	for _, s := range c.Scopes {
+		if s == "openid" {
+			return c.Scopes
+		}
+	}
+	return append([]string{"openid"}, c.Scopes...)
+}
+
+// ParseConfigs decodes a JSON array of provider configurations
+func ParseConfigs(data []byte) ([]ProviderConfig, error) {
+	var configs []ProviderConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("oidc: parse providers: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, cfg := range configs {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("oidc: duplicate provider %q", cfg.Name)
+		}
+		seen[cfg.Name] = true
+	}
+	return configs, nil
+}
+
+// ConfigsFromEnv reads provider configurations from OIDC_PROVIDERS (inline
+// JSON) or OIDC_PROVIDERS_FILE (path to a JSON file). No providers are
+// configured when both are unset.
+func ConfigsFromEnv() ([]ProviderConfig, error) {
+	if inline := os.Getenv("OIDC_PROVIDERS"); inline != "" {
+		return ParseConfigs([]byte(inline))
+	}
+	if path := os.Getenv("OIDC_PROVIDERS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("oidc: read providers file: %w", err)
+		}
+		return ParseConfigs(data)
+	}
+	return nil, nil
+}
@@ -0,0 +1,334 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdP is a minimal OpenID provider: discovery, JWKS, token and userinfo
+type fakeIdP struct {
+	t        *testing.T
+	server   *httptest.Server
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	issuer   string // Overrides the advertised issuer when set
+	claims   jwt.MapClaims
+	method   jwt.SigningMethod
+	kid      string
+	verifier string // Expected PKCE verifier
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	f := &fakeIdP{t: t, rsaKey: rsaKey, ecKey: ecKey, method: jwt.SigningMethodRS256, kid: "rsa-1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", f.discovery)
+	mux.HandleFunc("/jwks", f.jwks)
+	mux.HandleFunc("/token", f.token)
+	mux.HandleFunc("/userinfo", f.userinfo)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeIdP) config() ProviderConfig {
+	return ProviderConfig{
+		Name:         "acme",
+		Issuer:       f.server.URL,
+		ClientID:     "client-123",
+		ClientSecret: "secret",
+		RedirectURL:  "https://app.example.com/auth/callback/acme",
+	}
+}
+
+func (f *fakeIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := f.server.URL
+	if f.issuer != "" {
+		issuer = f.issuer
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                f.server.URL + "/authorize",
+		"token_endpoint":                        f.server.URL + "/token",
+		"userinfo_endpoint":                     f.server.URL + "/userinfo",
+		"jwks_uri":                              f.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256", "ES256"},
+	})
+}
+
+func (f *fakeIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(f.rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(f.rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "use": "sig", "crv": "P-256", "x": b64(f.ecKey.X.Bytes()), "y": b64(f.ecKey.Y.Bytes())},
+		},
+	})
+}
+
+func (f *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	require.NoError(f.t, r.ParseForm())
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != "client-123" || secret != "secret" || r.Form.Get("code") != "good-code" ||
+		(f.verifier != "" && r.Form.Get("code_verifier") != f.verifier) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "at-1",
+		"token_type":   "Bearer",
+		"id_token":     f.sign(f.claims),
+	})
+}
+
+func (f *fakeIdP) userinfo(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer at-1" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"sub": "user-1", "email": "diner@example.com", "email_verified": true})
+}
+
+func (f *fakeIdP) sign(claims jwt.MapClaims) string {
+	tok := jwt.NewWithClaims(f.method, claims)
+	tok.Header["kid"] = f.kid
+	var key interface{} = f.rsaKey
+	if f.method == jwt.SigningMethodES256 {
+		key = f.ecKey
+	}
+	s, err := tok.SignedString(key)
+	require.NoError(f.t, err)
+	return s
+}
+
+func (f *fakeIdP) validClaims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            f.server.URL,
+		"sub":            "user-1",
+		"aud":            "client-123",
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "diner@example.com",
+		"email_verified": true,
+		"name":           "Dana Diner",
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	idp := newFakeIdP(t)
+
+	p, err := Discover(context.Background(), idp.config(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, idp.server.URL+"/token", p.Discovery.TokenEndpoint)
+	assert.Equal(t, []string{"RS256", "ES256"}, p.algs)
+
+	idp.issuer = "https://evil.example.com"
+	_, err = Discover(context.Background(), idp.config(), nil)
+	assert.Error(t, err)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := newFakeIdP(t)
+	p, err := Discover(context.Background(), idp.config(), nil)
+	require.NoError(t, err)
+
+	u, err := url.Parse(p.AuthCodeURL("st", "nn", "ch", ""))
+	require.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, idp.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "client-123", q.Get("client_id"))
+	assert.Equal(t, "https://app.example.com/auth/callback/acme", q.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", q.Get("scope"))
+	assert.Equal(t, "st", q.Get("state"))
+	assert.Equal(t, "nn", q.Get("nonce"))
+	assert.Equal(t, "ch", q.Get("code_challenge"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+}
+
+func TestExchangeAndVerify(t *testing.T) {
+	ctx := context.Background()
+	idp := newFakeIdP(t)
+	idp.verifier = "verifier-1"
+	idp.claims = idp.validClaims("nonce-1")
+
+	p, err := Discover(ctx, idp.config(), nil)
+	require.NoError(t, err)
+
+	token, err := p.Exchange(ctx, "good-code", "verifier-1", "")
+	require.NoError(t, err)
+
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "diner@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "Dana Diner", claims.Name)
+
+	info, err := p.UserInfo(ctx, token.AccessToken, claims.Subject)
+	require.NoError(t, err)
+	assert.Equal(t, "diner@example.com", info.Email)
+
+	_, err = p.Exchange(ctx, "good-code", "wrong-verifier", "")
+	assert.Error(t, err)
+	_, err = p.Exchange(ctx, "bad-code", "verifier-1", "")
+	assert.Error(t, err)
+}
+
+func TestVerifyIDToken_ECKey(t *testing.T) {
+	ctx := context.Background()
+	idp := newFakeIdP(t)
+	idp.method = jwt.SigningMethodES256
+	idp.kid = "ec-1"
+
+	p, err := Discover(ctx, idp.config(), nil)
+	require.NoError(t, err)
+
+	_, err = p.VerifyIDToken(ctx, idp.sign(idp.validClaims("n")), "n")
+	assert.NoError(t, err)
+}
+
+func TestVerifyIDToken_Rejects(t *testing.T) {
+	ctx := context.Background()
+	idp := newFakeIdP(t)
+	p, err := Discover(ctx, idp.config(), nil)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token func() string
+		nonce string
+		want  error
+	}{
+		{
+			name:  "wrong nonce",
+			token: func() string { return idp.sign(idp.validClaims("n")) },
+			nonce: "other",
+			want:  ErrNonceMismatch,
+		},
+		{
+			name:  "missing nonce",
+			token: func() string { return idp.sign(idp.validClaims("")) },
+			nonce: "",
+			want:  ErrNonceMismatch,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				c := idp.validClaims("n")
+				c["aud"] = "someone-else"
+				return idp.sign(c)
+			},
+			nonce: "n",
+			want:  ErrInvalidIDToken,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				c := idp.validClaims("n")
+				c["iss"] = "https://evil.example.com"
+				return idp.sign(c)
+			},
+			nonce: "n",
+			want:  ErrInvalidIDToken,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				c := idp.validClaims("n")
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+				return idp.sign(c)
+			},
+			nonce: "n",
+			want:  ErrInvalidIDToken,
+		},
+		{
+			name: "multiple audiences without azp",
+			token: func() string {
+				c := idp.validClaims("n")
+				c["aud"] = []string{"client-123", "other"}
+				return idp.sign(c)
+			},
+			nonce: "n",
+			want:  ErrInvalidIDToken,
+		},
+		{
+			name: "signed by unknown key",
+			token: func() string {
+				tok := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.validClaims("n"))
+				tok.Header["kid"] = "rsa-1"
+				s, _ := tok.SignedString(otherKey)
+				return s
+			},
+			nonce: "n",
+			want:  ErrInvalidIDToken,
+		},
+		{
+			name: "symmetric algorithm",
+			token: func() string {
+				tok := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.validClaims("n"))
+				s, _ := tok.SignedString([]byte("secret"))
+				return s
+			},
+			nonce: "n",
+			want:  ErrInvalidIDToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.VerifyIDToken(ctx, tt.token(), tt.nonce)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestParseConfigs(t *testing.T) {
+	configs, err := ParseConfigs([]byte(`[{"name":"microsoft","issuer":"https://login.example.com","clientId":"abc","scopes":["email"]}]`))
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, []string{"openid", "email"}, configs[0].scopes())
+
+	_, err = ParseConfigs([]byte(`[{"name":"Bad Name","issuer":"https://x","clientId":"abc"}]`))
+	assert.Error(t, err)
+	_, err = ParseConfigs([]byte(`[{"name":"a","issuer":"https://x","clientId":"1"},{"name":"a","issuer":"https://y","clientId":"2"}]`))
+	assert.Error(t, err)
+}
+
+func TestRegistry(t *testing.T) {
+	idp := newFakeIdP(t)
+	r := NewRegistry([]ProviderConfig{idp.config()}, nil)
+
+	assert.True(t, r.Has("acme"))
+	p1, err := r.Provider(context.Background(), "acme")
+	require.NoError(t, err)
+	p2, err := r.Provider(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Same(t, p1, p2)
+
+	_, err = r.Provider(context.Background(), "nope")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
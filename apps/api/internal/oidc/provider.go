@@ -0,0 +1,252 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Discovery is the subset of the provider metadata document
+// (/.well-known/openid-configuration) that the client uses
+type Discovery struct {
+	Issuer                  string   `json:"issuer"`
+	AuthorizationEndpoint   string   `json:"authorization_endpoint"`
+	TokenEndpoint           string   `json:"token_endpoint"`
+	UserinfoEndpoint        string   `json:"userinfo_endpoint"`
+	JWKSURI                 string   `json:"jwks_uri"`
+	IDTokenSigningAlgValues []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// Token is the token endpoint response
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+}
+
+// Claims are the verified identity claims of an ID token
+type Claims struct {
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	Name            string `json:"name"`
+	Picture         string `json:"picture"`
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// Errors returned while verifying an ID token
+var (
+	ErrInvalidIDToken = errors.New("oidc: invalid id token")
+	ErrNonceMismatch  = errors.New("oidc: nonce mismatch")
+)
+
+// clockSkew tolerates small differences between our clock and the provider's
+const clockSkew = time.Minute
+
+// defaultSigningAlgs applies when discovery does not list algorithms
+var defaultSigningAlgs = []string{"RS256"}
+
+// supportedSigningAlgs are the asymmetric algorithms we verify
+var supportedSigningAlgs = map[string]bool{
+	"RS256": true, "RS384": true, "RS512": true,
+	"PS256": true, "PS384": true, "PS512": true,
+	"ES256": true, "ES384": true, "ES512": true,
+}
+
+// Provider is a discovered OpenID Connect provider
+type Provider struct {
+	Config    ProviderConfig
+	Discovery Discovery
+
+	client *http.Client
+	keys   *keySet
+	algs   []string
+}
+
+// Discover fetches the provider's metadata and returns a ready Provider
+func Discover(ctx context.Context, cfg ProviderConfig, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	wellKnown := strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, "GET", wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: discovery for %s: %w", cfg.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: discovery for %s: status %d", cfg.Name, resp.StatusCode)
+	}
+
+	var doc Discovery
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("oidc: decode discovery for %s: %w", cfg.Name, err)
+	}
+
+	// The document must describe the issuer we were configured with,
+	// otherwise tokens from another issuer could be accepted
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(cfg.Issuer, "/") {
+		return nil, fmt.Errorf("oidc: discovery for %s: issuer %q does not match %q", cfg.Name, doc.Issuer, cfg.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: discovery for %s: missing endpoints", cfg.Name)
+	}
+
+	algs := make([]string, 0, len(doc.IDTokenSigningAlgValues))
+	for _, alg := range doc.IDTokenSigningAlgValues {
+		if supportedSigningAlgs[alg] {
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		algs = defaultSigningAlgs
+	}
+
+	return &Provider{
+		Config:    cfg,
+		Discovery: doc,
+		client:    client,
+		keys:      newKeySet(doc.JWKSURI, client),
+		algs:      algs,
+	}, nil
+}
+
+// AuthCodeURL builds the authorization request URL using PKCE (S256)
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge, redirectURI string) string {
+	params := url.Values{}
+	params.Set("client_id", p.Config.ClientID)
+	params.Set("redirect_uri", p.redirectURI(redirectURI))
+	params.Set("response_type", "code")
+	params.Set("scope", strings.Join(p.Config.scopes(), " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.Discovery.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.Discovery.AuthorizationEndpoint + sep + params.Encode()
+}
+
+// Exchange redeems an authorization code at the token endpoint
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, redirectURI string) (*Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURI(redirectURI))
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.Discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.Config.ClientID), url.QueryEscape(p.Config.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: token request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: token request: status %d", resp.StatusCode)
+	}
+
+	var token Token
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("oidc: decode token response: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+	return &token, nil
+}
+
+// VerifyIDToken checks the ID token's signature against the provider's
+// keys, its issuer, audience and expiry, and that it carries nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods(p.algs),
+		jwt.WithIssuer(p.Discovery.Issuer),
+		jwt.WithAudience(p.Config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+	// With several audiences the token must have been issued to us
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.Config.ClientID {
+		return nil, fmt.Errorf("%w: azp %q is not the client", ErrInvalidIDToken, claims.AuthorizedParty)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, ErrNonceMismatch
+	}
+	return claims, nil
+}
+
+// UserInfo fetches claims from the userinfo endpoint. Callers use it to
+// fill in profile fields the ID token omits; the subject must match.
+func (p *Provider) UserInfo(ctx context.Context, accessToken, subject string) (*Claims, error) {
+	if p.Discovery.UserinfoEndpoint == "" {
+		return nil, errors.New("oidc: provider has no userinfo endpoint")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", p.Discovery.UserinfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: userinfo request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: userinfo request: status %d", resp.StatusCode)
+	}
+
+	var info Claims
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("oidc: decode userinfo: %w", err)
+	}
+	if info.Subject != subject {
+		return nil, errors.New("oidc: userinfo subject does not match id token")
+	}
+	return &info, nil
+}
+
+func (p *Provider) redirectURI(override string) string {
+	if override != "" {
+		return override
+	}
+	return p.Config.RedirectURL
+}
@@ -0,0 +1,88 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownProvider is returned for names that are not configured
+var ErrUnknownProvider = errors.New("oidc: unknown provider")
+
+// discoveryTTL is how long a discovered provider is reused before its
+// metadata is fetched again
+const discoveryTTL = 24 * time.Hour
+
+// Registry holds the configured providers and discovers each lazily on
+// first use, so an unreachable provider does not block startup
+type Registry struct {
+	client  *http.Client
+	configs map[string]ProviderConfig
+
+	mu        sync.Mutex
+	providers map[string]*Provider
+	loadedAt  map[string]time.Time
+}
+
+// NewRegistry returns a registry for configs. A nil client uses a client
+// with a 10 second timeout.
+func NewRegistry(configs []ProviderConfig, client *http.Client) *Registry {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	r := &Registry{
+		client:    client,
+		configs:   make(map[string]ProviderConfig, len(configs)),
+		providers: map[string]*Provider{},
+		loadedAt:  map[string]time.Time{},
+	}
+	for _, cfg := range configs {
+		r.configs[cfg.Name] = cfg
+	}
+	return r
+}
+
+// Configs returns the configured providers sorted by name
+func (r *Registry) Configs() []ProviderConfig {
+	configs := make([]ProviderConfig, 0, len(r.configs))
+	for _, cfg := range r.configs {
+		configs = append(configs, cfg)
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Name < configs[j].Name })
+	return configs
+}
+
+// Has reports whether name is configured
+func (r *Registry) Has(name string) bool {
+	_, ok := r.configs[name]
+	return ok
+}
+
+// Provider returns the discovered provider called name
+func (r *Registry) Provider(ctx context.Context, name string) (*Provider, error) {
+	cfg, ok := r.configs[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.providers[name]; ok && time.Since(r.loadedAt[name]) < discoveryTTL {
+		return p, nil
+	}
+	p, err := Discover(ctx, cfg, r.client)
+	if err != nil {
+		// Keep serving the previous metadata if a refresh fails
+		if old, ok := r.providers[name]; ok {
+			return old, nil
+		}
+		return nil, err
+	}
+	r.providers[name] = p
+	r.loadedAt[name] = time.Now()
+	return p, nil
+}
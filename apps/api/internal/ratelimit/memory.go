@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+// MemoryStore is an in-process Store. It is safe for concurrent use but its
+// state is not shared between API instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	locks   map[string]LockState
+	ops     int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		locks:   make(map[string]LockState),
+	}
+}
+
+func (s *MemoryStore) Take(key string, rate Rate, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ops++
+	if s.ops%1024 == 0 {
+		s.prune(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Burst), last: now, period: rate.Period}
+		s.buckets[key] = b
+	}
+	b.tokens = refill(b.tokens, b.last, now, rate)
+	b.last = now
+	b.period = rate.Period
+
+	if b.tokens < 1 {
+		return Result{Allowed: false, Remaining: 0, RetryAfter: retryAfter(b.tokens, rate)}, nil
+	}
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+func (s *MemoryStore) Lock(key string) (LockState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locks[key], nil
+}
+
+func (s *MemoryStore) RecordFailure(key string, policy LockoutPolicy, now time.Time) (LockState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := applyFailure(s.locks[key], policy, now)
+	s.locks[key] = state
+	return state, nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.buckets, key)
+	delete(s.locks, key)
+	return nil
+}
+
+// prune drops buckets that have fully refilled and lock states that have
+// expired, so the maps do not grow with every address ever seen.
+func (s *MemoryStore) prune(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+	for key, state := range s.locks {
+		if !state.Locked(now) && now.Sub(state.LastFailure) > 24*time.Hour {
+			delete(s.locks, key)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Rate describes a token bucket: Burst tokens that refill evenly over Period.
+type Rate struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// LockoutPolicy controls progressive lockout after repeated failures.
+// Every Threshold failures inside Window lock the account; each further
+// lockout doubles the duration, starting at BaseLock and capped at MaxLock.
+type LockoutPolicy struct {
+	Threshold int
+	Window    time.Duration
+	BaseLock  time.Duration
+	MaxLock   time.Duration
+}
+
+// LockState is the failure history stored per account
+type LockState struct {
+	Failures    int
+	Lockouts    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Locked reports whether the account is locked at the given time
+func (s LockState) Locked(now time.Time) bool {
+	return now.Before(s.LockedUntil)
+}
+
+// Store persists bucket and lockout state. MemoryStore keeps everything in
+// process; a shared implementation (Redis, Postgres, ...) can satisfy the same
+// interface so limits hold across API replicas. Implementations must apply
+// each call atomically.
+type Store interface {
+	// Take consumes one token from the bucket identified by key.
+	Take(key string, rate Rate, now time.Time) (Result, error)
+	// Lock returns the current lockout state for key.
+	Lock(key string) (LockState, error)
+	// RecordFailure registers a failed attempt and returns the updated state.
+	RecordFailure(key string, policy LockoutPolicy, now time.Time) (LockState, error)
+	// Reset clears the bucket and lockout state for key.
+	Reset(key string) error
+}
+
+// refill computes the bucket level after elapsed time. Shared by stores so
+// every backend agrees on the token bucket math.
+func refill(tokens float64, last, now time.Time, rate Rate) float64 {
+	if rate.Period <= 0 || rate.Burst <= 0 {
+		return float64(rate.Burst)
+	}
+	elapsed := now.Sub(last)
+	if elapsed <= 0 {
+		return tokens
+	}
+	perToken := rate.Period / time.Duration(rate.Burst)
+	tokens += float64(elapsed) / float64(perToken)
+	return math.Min(tokens, float64(rate.Burst))
+}
+
+// retryAfter returns how long until one full token is available
+func retryAfter(tokens float64, rate Rate) time.Duration {
+	if rate.Burst <= 0 {
+		return rate.Period
+	}
+	perToken := rate.Period / time.Duration(rate.Burst)
+	missing := 1 - tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(missing * float64(perToken)))
+}
+
+// applyFailure advances a lockout state by one failure under policy
+func applyFailure(state LockState, policy LockoutPolicy, now time.Time) LockState {
+	// Failures outside the window no longer count, and a long quiet period
+	// also forgives previous lockouts.
+	if !state.LastFailure.IsZero() && now.Sub(state.LastFailure) > policy.Window {
+		state.Failures = 0
+		if now.Sub(state.LastFailure) > policy.MaxLock*2 {
+			state.Lockouts = 0
+		}
+	}
+
+	state.Failures++
+	state.LastFailure = now
+
+	if policy.Threshold > 0 && state.Failures >= policy.Threshold {
+		lock := policy.BaseLock << state.Lockouts
+		if lock <= 0 || lock > policy.MaxLock {
+			lock = policy.MaxLock
+		}
+		state.LockedUntil = now.Add(lock)
+		state.Lockouts++
+		state.Failures = 0
+	}
+
+	return state
+}
+
+// NormalizeAccount returns the canonical key for an account identifier
+func NormalizeAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	store := NewMemoryStore()
+	rate := Rate{Burst: 3, Period: 3 * time.Second}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		res, err := store.Take("k", rate, now)
+		assert.NoError(t, err)
+		assert.True(t, res.Allowed)
+	}
+
+	res, _ := store.Take("k", rate, now)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	// One token refills per second
+	res, _ = store.Take("k", rate, now.Add(time.Second))
+	assert.True(t, res.Allowed)
+	res, _ = store.Take("k", rate, now.Add(time.Second))
+	assert.False(t, res.Allowed)
+
+	// Other keys are independent
+	res, _ = store.Take("other", rate, now)
+	assert.True(t, res.Allowed)
+}
+
+func TestLoginGuard_ProgressiveLockout(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	guard := NewLoginGuard(NewMemoryStore())
+	guard.AccountRate = Rate{Burst: 100, Period: time.Minute}
+	guard.Now = func() time.Time { return now }
+
+	fail := func(n int) (bool, time.Time) {
+		var locked bool
+		var until time.Time
+		for i := 0; i < n; i++ {
+			locked, until, _ = guard.RecordFailure("Owner@Example.com")
+		}
+		return locked, until
+	}
+
+	// Four failures do not lock
+	locked, _ := fail(4)
+	assert.False(t, locked)
+	res, _ := guard.AllowAccount("owner@example.com")
+	assert.True(t, res.Allowed)
+
+	// The fifth locks for the base duration
+	locked, until := fail(1)
+	assert.True(t, locked)
+	assert.Equal(t, now.Add(time.Minute), until)
+	res, _ = guard.AllowAccount("owner@example.com")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Minute, res.RetryAfter)
+
+	// The next lockout doubles
+	now = until
+	locked, until = fail(5)
+	assert.True(t, locked)
+	assert.Equal(t, now.Add(2*time.Minute), until)
+
+	// Success clears history
+	assert.NoError(t, guard.RecordSuccess("owner@example.com"))
+	res, _ = guard.AllowAccount("owner@example.com")
+	assert.True(t, res.Allowed)
+}
+
+func TestLoginGuard_LockoutCapped(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	guard := NewLoginGuard(NewMemoryStore())
+	guard.Now = func() time.Time { return now }
+
+	var until time.Time
+	for lockout := 0; lockout < 10; lockout++ {
+		for i := 0; i < guard.Lockout.Threshold; i++ {
+			_, until, _ = guard.RecordFailure("a@example.com")
+		}
+		assert.LessOrEqual(t, until.Sub(now), guard.Lockout.MaxLock)
+		now = until
+	}
+}
+
+func TestLoginGuard_LimitByIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	guard := NewLoginGuard(NewMemoryStore())
+	guard.IPRate = Rate{Burst: 2, Period: time.Minute}
+
+	r := gin.New()
+	r.POST("/login", guard.LimitByIP(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	codes := make([]int, 0, 3)
+	var last *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = "203.0.113.7:5555"
+		r.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+		last = w
+	}
+
+	assert.Equal(t, []int{200, 200, 429}, codes)
+	assert.Equal(t, "30", last.Header().Get("Retry-After"))
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/handlers"
+	"gorm.io/gorm"
+)
+
+// menuPublishInterval is how often scheduled menu versions are checked
+const menuPublishInterval = time.Minute
+
+// StartJobs runs the background jobs until ctx is done. Jobs are safe to
+// run on several instances at once.
+func StartJobs(ctx context.Context, gdb *gorm.DB) {
+	go every(ctx, menuPublishInterval, func(now time.Time) {
+		n, err := handlers.PublishDueMenuVersions(gdb, now)
+		if err != nil {
+			log.Printf("menu versions: %v", err)
+			return
+		}
+		if n > 0 {
+			log.Printf("menu versions: published %d scheduled versions", n)
+		}
+	})
+}
+
+// every calls run at each interval until ctx is done
+func every(ctx context.Context, interval time.Duration, run func(now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			run(now)
+		}
+	}
+}
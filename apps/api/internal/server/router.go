@@ -1,9 +1,17 @@
 package server
 
 import (
+	"log"
+	"os"
+
 	"github.com/example/restosaas/apps/api/internal/auth"
+	"github.com/example/restosaas/apps/api/internal/authz"
 	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/geo"
 	"github.com/example/restosaas/apps/api/internal/handlers"
+	"github.com/example/restosaas/apps/api/internal/notify"
+	"github.com/example/restosaas/apps/api/internal/oidc"
+	"github.com/example/restosaas/apps/api/internal/ratelimit"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -11,17 +19,29 @@ import (
 )
 
 func Mount(r *gin.Engine, gdb *gorm.DB) {
+	mailer := notify.FromEnv()
+	loginGuard := ratelimit.NewLoginGuard(ratelimit.NewMemoryStore())
+
 	pub := handlers.NewPublicHandler(gdb)
 	own := handlers.OwnerHandler{DB: gdb}
 	adm := handlers.AdminHandler{DB: gdb}
 	pay := handlers.PaymentHandler{DB: gdb}
-	usr := handlers.UserHandler{DB: gdb}
+	usr := handlers.UserHandler{DB: gdb, Guard: loginGuard, Mailer: mailer}
 	superAdmin := handlers.SuperAdminHandler{DB: gdb}
-	restaurant := handlers.RestaurantHandler{DB: gdb}
+	restaurant := handlers.RestaurantHandler{DB: gdb, Geocoder: geo.NewStaticGeocoder()}
 	organization := handlers.OrganizationHandler{DB: gdb}
 	menu := handlers.MenuHandler{DB: gdb}
 	course := handlers.CourseHandler{DB: gdb}
-	oauth := handlers.OAuthHandler{DB: gdb}
+	auditLog := handlers.AuditHandler{DB: gdb}
+	apiKeys := handlers.APIKeyHandler{DB: gdb}
+	invitation := handlers.InvitationHandler{DB: gdb, Mailer: mailer, AppURL: os.Getenv("APP_URL")}
+
+	oidcConfigs, err := oidc.ConfigsFromEnv()
+	if err != nil {
+		log.Printf("OIDC providers disabled: %v", err)
+	}
+	oidcLogin := handlers.OIDCHandler{DB: gdb, Providers: oidc.NewRegistry(oidcConfigs, nil)}
+	oauth := handlers.OAuthHandler{DB: gdb, OIDC: &oidcLogin}
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
@@ -59,14 +79,25 @@ func Mount(r *gin.Engine, gdb *gorm.DB) {
 		api.GET("/search/cache/stats", pub.GetCacheStats)
 
 		// User authentication routes (PUBLIC - no auth required)
-		api.POST("/auth/register", usr.CreateUser) // Register new user
-		api.POST("/users", usr.CreateUser)         // Register new user (alternative endpoint)
-		api.POST("/auth/login", usr.Login)         // Login user
+		api.POST("/auth/register", usr.CreateUser)                 // Register new user
+		api.POST("/users", usr.CreateUser)                         // Register new user (alternative endpoint)
+		api.POST("/auth/login", loginGuard.LimitByIP(), usr.Login) // Login user
 
 		// OAuth routes (PUBLIC - no auth required)
-		api.POST("/auth/oauth/google", oauth.GoogleCallback)
-		api.POST("/auth/oauth/facebook", oauth.FacebookCallback)
-		api.POST("/auth/oauth/twitter", oauth.TwitterCallback)
+		api.GET("/auth/oauth/:provider/authorize", loginGuard.LimitByIP(), oauth.Authorize)
+		api.POST("/auth/oauth/google", loginGuard.LimitByIP(), oauth.GoogleCallback)
+		api.POST("/auth/oauth/facebook", loginGuard.LimitByIP(), oauth.FacebookCallback)
+		api.POST("/auth/oauth/twitter", loginGuard.LimitByIP(), oauth.TwitterCallback)
+
+		// OpenID Connect routes for configured providers (PUBLIC - no auth required)
+		api.GET("/auth/oidc/providers", oidcLogin.ListProviders)
+		api.GET("/auth/oidc/:provider/authorize", loginGuard.LimitByIP(), oidcLogin.Authorize)
+		api.POST("/auth/oidc/:provider/callback", loginGuard.LimitByIP(), oidcLogin.Callback)
+
+		// Invitation responses (PUBLIC - the emailed token authorizes them)
+		api.POST("/invitations/preview", loginGuard.LimitByIP(), invitation.PreviewInvitation)
+		api.POST("/invitations/accept", loginGuard.LimitByIP(), invitation.AcceptInvitation)
+		api.POST("/invitations/decline", loginGuard.LimitByIP(), invitation.DeclineInvitation)
 	}
 
 	// General user routes (require authentication for any role)
@@ -74,6 +105,12 @@ func Mount(r *gin.Engine, gdb *gorm.DB) {
 	userRoutes.Use(auth.RequireAuth(), auth.AddTokenToResponse())
 	{
 		userRoutes.GET("/me", usr.GetMe) // GET /api/users/me - Get current user
+
+		// Linked login providers
+		userRoutes.GET("/me/identities", oauth.ListIdentities)
+		userRoutes.POST("/me/identities/:provider/authorize", oauth.AuthorizeLink)
+		userRoutes.POST("/me/identities/:provider", oauth.LinkIdentity)
+		userRoutes.DELETE("/me/identities/:provider", oauth.UnlinkIdentity)
 	}
 
 	// User management routes (require SUPER_ADMIN or OWNER role)
@@ -86,11 +123,27 @@ func Mount(r *gin.Engine, gdb *gorm.DB) {
 		users.DELETE("/:id", usr.DeleteUser) // DELETE /api/users/:id
 	}
 
+	// Organization-scoped routes: any signed-in user may call them; what they
+	// can do is decided by their role in the restaurant's organization
 	owner := r.Group("/api/owner")
-	owner.Use(auth.RequireAuth(string(db.RoleOwner), string(db.RoleSuper)), auth.AddTokenToResponse())
+	owner.Use(auth.RequireAuth(), auth.AddTokenToResponse())
 	{
-		owner.GET("/reservations", own.ListReservations)
-		owner.POST("/reviews/:id/approve", own.ApproveReview)
+		owner.GET("/organizations", organization.ListMyOrganizations)                                                            // Organizations the caller belongs to
+		owner.GET("/organizations/:id/audit-logs", authz.OrgScope(gdb, authz.PermAuditRead), auditLog.ListOrganizationAuditLogs) // Organization audit log
+
+		// Invitations (members:manage)
+		canManageMembers := authz.OrgScope(gdb, authz.PermMembersManage)
+		owner.GET("/organizations/:id/invitations", canManageMembers, invitation.ListInvitations)
+		owner.POST("/organizations/:id/invitations", canManageMembers, invitation.CreateInvitation)
+		owner.POST("/organizations/:id/invitations/:invitationId/resend", canManageMembers, invitation.ResendInvitation)
+		owner.DELETE("/organizations/:id/invitations/:invitationId", canManageMembers, invitation.RevokeInvitation)
+
+		// API keys (apikeys:manage)
+		canManageKeys := authz.OrgScope(gdb, authz.PermAPIKeysManage)
+		owner.GET("/organizations/:id/api-keys", canManageKeys, apiKeys.ListAPIKeys)
+		owner.POST("/organizations/:id/api-keys", canManageKeys, apiKeys.CreateAPIKey)
+		owner.POST("/organizations/:id/api-keys/:keyId/rotate", canManageKeys, apiKeys.RotateAPIKey)
+		owner.DELETE("/organizations/:id/api-keys/:keyId", canManageKeys, apiKeys.RevokeAPIKey)
 	}
 
 	admin := r.Group("/api/admin")
@@ -114,6 +167,7 @@ func Mount(r *gin.Engine, gdb *gorm.DB) {
 		superAdminGroup.GET("/restaurants/:id", restaurant.GetRestaurantByID)       // Get restaurant by ID
 		superAdminGroup.PUT("/restaurants/:id", restaurant.UpdateRestaurant)        // Update restaurant
 		superAdminGroup.DELETE("/restaurants/:id", restaurant.DeleteRestaurantByID) // Delete restaurant
+		superAdminGroup.GET("/audit-logs", auditLog.ListAuditLogs)                  // Query audit logs
 	}
 
 	// Super Admin organization routes (SUPER_ADMIN only)
@@ -131,39 +185,106 @@ func Mount(r *gin.Engine, gdb *gorm.DB) {
 		superAdminOrgGroup.POST("/:id/restaurants", restaurant.CreateRestaurantForOrganization) // Create restaurant for organization
 	}
 
-	// Restaurant management routes (OWNER only)
+	// Restaurant, menu and course routes also accept organization API keys,
+	// limited to the key's organization and scopes
+	memberOrAPIKey := auth.RequireAuthOrAPIKey(apiKeys.Resolve)
+
+	// Restaurant management routes (organization members, by permission)
+	canReadRestaurant := authz.RestaurantScope(gdb, authz.PermRestaurantRead)
+	canWriteRestaurant := authz.RestaurantScope(gdb, authz.PermRestaurantWrite)
 	restaurantGroup := r.Group("/api/owner/restaurants")
-	restaurantGroup.Use(auth.RequireAuth(string(db.RoleOwner), string(db.RoleSuper)), auth.AddTokenToResponse())
+	restaurantGroup.Use(memberOrAPIKey, auth.AddTokenToResponse())
 	{
-		restaurantGroup.POST("", restaurant.CreateRestaurant)                          // Create restaurant
-		restaurantGroup.GET("/me", restaurant.GetMyRestaurant)                         // Get my restaurant
-		restaurantGroup.PUT("/:id", restaurant.UpdateRestaurant)                       // Update restaurant
-		restaurantGroup.DELETE("/:id", restaurant.DeleteRestaurant)                    // Delete restaurant
-		restaurantGroup.POST("/:id/hours", restaurant.SetOpeningHours)                 // Set opening hours
-		restaurantGroup.POST("/:id/images", restaurant.UploadImages)                   // Upload images
-		restaurantGroup.POST("/:id/images/single", restaurant.UploadSingleImage)       // Upload single image
-		restaurantGroup.POST("/:id/images/:imageId/set-main", restaurant.SetMainImage) // Set main image
+		restaurantGroup.GET("", restaurant.ListMyRestaurants)                                                                            // List my restaurants
+		restaurantGroup.POST("", restaurant.CreateRestaurant)                                                                            // Create restaurant
+		restaurantGroup.GET("/me", restaurant.GetMyRestaurant)                                                                           // Get my restaurant
+		restaurantGroup.PUT("/:id", canWriteRestaurant, restaurant.UpdateRestaurant)                                                     // Update restaurant
+		restaurantGroup.DELETE("/:id", authz.RestaurantScope(gdb, authz.PermRestaurantDelete), restaurant.DeleteRestaurant)              // Delete restaurant
+		restaurantGroup.POST("/:id/hours", canWriteRestaurant, restaurant.SetOpeningHours)                                               // Set opening hours
+		restaurantGroup.GET("/:id/special-hours", canReadRestaurant, restaurant.ListSpecialHours)                                        // List special hours from a date
+		restaurantGroup.PUT("/:id/special-hours/:date", canWriteRestaurant, restaurant.SetSpecialHours)                                  // Set special hours of a date
+		restaurantGroup.DELETE("/:id/special-hours/:date", canWriteRestaurant, restaurant.DeleteSpecialHours)                            // Remove special hours of a date
+		restaurantGroup.POST("/:id/images", canWriteRestaurant, restaurant.UploadImages)                                                 // Upload images
+		restaurantGroup.POST("/:id/images/single", canWriteRestaurant, restaurant.UploadSingleImage)                                     // Upload single image
+		restaurantGroup.POST("/:id/images/:imageId/set-main", canWriteRestaurant, restaurant.SetMainImage)                               // Set main image
+		restaurantGroup.POST("/:id/clone", authz.RestaurantScope(gdb, authz.PermMenusWrite), restaurant.CloneRestaurant)                 // Copy into another restaurant
+		restaurantGroup.GET("/:id/translations", canReadRestaurant, restaurant.GetRestaurantTranslations)                                // Get translations
+		restaurantGroup.PUT("/:id/translations/:locale", canWriteRestaurant, restaurant.SetRestaurantTranslation)                        // Set one locale
+		restaurantGroup.DELETE("/:id/translations/:locale", canWriteRestaurant, restaurant.DeleteRestaurantTranslation)                  // Remove one locale
+		restaurantGroup.GET("/:id/pricing", canReadRestaurant, restaurant.GetRestaurantPricing)                                          // Get currency, tax and service charge
+		restaurantGroup.PUT("/:id/pricing", canWriteRestaurant, restaurant.UpdateRestaurantPricing)                                      // Set currency, tax and service charge
+		restaurantGroup.GET("/:id/budget", canReadRestaurant, restaurant.GetRestaurantBudget)                                            // Get budget per person
+		restaurantGroup.PUT("/:id/budget", canWriteRestaurant, restaurant.SetRestaurantBudget)                                           // Set budget per person
+		restaurantGroup.GET("/:id/location", canReadRestaurant, restaurant.GetRestaurantLocation)                                        // Get position on the map
+		restaurantGroup.PUT("/:id/location", canWriteRestaurant, restaurant.SetRestaurantLocation)                                       // Set or geocode position on the map
+		restaurantGroup.GET("/:id/reservations", authz.RestaurantScope(gdb, authz.PermReservationsRead), own.ListReservations)           // List reservations
+		restaurantGroup.POST("/:id/reviews/:reviewId/approve", authz.RestaurantScope(gdb, authz.PermReviewsModerate), own.ApproveReview) // Approve review
 	}
 
-	// Menu management routes (OWNER only)
+	// Menu management routes (menus:read / menus:write, and menus:stock for
+	// sold-out toggles)
+	canReadMenus := authz.RestaurantScope(gdb, authz.PermMenusRead)
+	canWriteMenus := authz.RestaurantScope(gdb, authz.PermMenusWrite)
+	canStockMenus := authz.RestaurantScope(gdb, authz.PermMenusStock)
 	menuGroup := r.Group("/api/owner/restaurants/:id/menus")
-	menuGroup.Use(auth.RequireAuth(string(db.RoleOwner), string(db.RoleSuper)), auth.AddTokenToResponse())
+	menuGroup.Use(memberOrAPIKey, auth.AddTokenToResponse())
+	{
+		menuGroup.GET("", canReadMenus, menu.ListMenus)                                              // Get menus
+		menuGroup.POST("", canWriteMenus, menu.CreateMenu)                                           // Create menu
+		menuGroup.PUT("/reorder", canWriteMenus, menu.ReorderMenus)                                  // Reorder menus within a category
+		menuGroup.POST("/import", canWriteMenus, menu.ImportMenus)                                   // Import menus from CSV or JSON
+		menuGroup.GET("/export", canReadMenus, menu.ExportMenus)                                     // Export menus as CSV or JSON
+		menuGroup.GET("/:menuId", canReadMenus, menu.GetMenu)                                        // Get menu
+		menuGroup.PUT("/:menuId", canWriteMenus, menu.UpdateMenu)                                    // Update menu
+		menuGroup.DELETE("/:menuId", canWriteMenus, menu.DeleteMenu)                                 // Delete menu
+		menuGroup.GET("/:menuId/availability", canReadMenus, menu.GetMenuAvailability)               // Get availability rules
+		menuGroup.PUT("/:menuId/availability", canWriteMenus, menu.SetMenuAvailability)              // Set availability rules
+		menuGroup.GET("/:menuId/translations", canReadMenus, menu.GetMenuTranslations)               // Get translations
+		menuGroup.PUT("/:menuId/translations/:locale", canWriteMenus, menu.SetMenuTranslation)       // Set one locale
+		menuGroup.DELETE("/:menuId/translations/:locale", canWriteMenus, menu.DeleteMenuTranslation) // Remove one locale
+		menuGroup.POST("/:menuId/sold-out", canStockMenus, menu.MarkMenuSoldOut)                     // 86 an item
+		menuGroup.DELETE("/:menuId/sold-out", canStockMenus, menu.ClearMenuSoldOut)                  // Back on sale
+	}
+
+	// Menu category routes (menus:read / menus:write)
+	categoryGroup := r.Group("/api/owner/restaurants/:id/menu-categories")
+	categoryGroup.Use(memberOrAPIKey, auth.AddTokenToResponse())
+	{
+		categoryGroup.GET("", canReadMenus, menu.ListMenuCategories)                                    // Get category tree
+		categoryGroup.POST("", canWriteMenus, menu.CreateMenuCategory)                                  // Create category
+		categoryGroup.PUT("/reorder", canWriteMenus, menu.ReorderMenuCategories)                        // Reorder categories
+		categoryGroup.PUT("/:categoryId", canWriteMenus, menu.UpdateMenuCategory)                       // Update or move category
+		categoryGroup.DELETE("/:categoryId", canWriteMenus, menu.DeleteMenuCategory)                    // Delete category
+		categoryGroup.GET("/:categoryId/availability", canReadMenus, menu.GetMenuCategoryAvailability)  // Get availability rules
+		categoryGroup.PUT("/:categoryId/availability", canWriteMenus, menu.SetMenuCategoryAvailability) // Set availability rules
+	}
+
+	// Menu version routes (menus:read / menus:write)
+	versionGroup := r.Group("/api/owner/restaurants/:id/menu-versions")
+	versionGroup.Use(memberOrAPIKey, auth.AddTokenToResponse())
 	{
-		menuGroup.GET("", menu.ListMenus)             // Get menus
-		menuGroup.POST("", menu.CreateMenu)           // Create menu
-		menuGroup.GET("/:menuId", menu.GetMenu)       // Get menu
-		menuGroup.PUT("/:menuId", menu.UpdateMenu)    // Update menu
-		menuGroup.DELETE("/:menuId", menu.DeleteMenu) // Delete menu
+		versionGroup.GET("", canReadMenus, menu.ListMenuVersions)                              // List versions
+		versionGroup.POST("", canWriteMenus, menu.CreateMenuVersion)                           // Create draft
+		versionGroup.GET("/:versionId", canReadMenus, menu.GetMenuVersion)                     // Get version with snapshot
+		versionGroup.PUT("/:versionId", canWriteMenus, menu.UpdateMenuVersion)                 // Edit draft
+		versionGroup.DELETE("/:versionId", canWriteMenus, menu.DeleteMenuVersion)              // Delete draft
+		versionGroup.GET("/:versionId/diff", canReadMenus, menu.DiffMenuVersion)               // Compare with live or another version
+		versionGroup.POST("/:versionId/publish", canWriteMenus, menu.PublishMenuVersion)       // Publish now or schedule
+		versionGroup.DELETE("/:versionId/schedule", canWriteMenus, menu.UnscheduleMenuVersion) // Back to draft
+		versionGroup.POST("/:versionId/rollback", canWriteMenus, menu.RollbackMenuVersion)     // Publish an archived version again
 	}
 
-	// Course management routes (OWNER only)
+	// Course management routes (menus:read / menus:write)
 	courseGroup := r.Group("/api/owner/restaurants/:id/courses")
-	courseGroup.Use(auth.RequireAuth(string(db.RoleOwner), string(db.RoleSuper)), auth.AddTokenToResponse())
+	courseGroup.Use(memberOrAPIKey, auth.AddTokenToResponse())
 	{
-		courseGroup.GET("", course.ListCourses)               // Get courses
-		courseGroup.POST("", course.CreateCourse)             // Create course
-		courseGroup.GET("/:courseId", course.GetCourse)       // Get course
-		courseGroup.PUT("/:courseId", course.UpdateCourse)    // Update course
-		courseGroup.DELETE("/:courseId", course.DeleteCourse) // Delete course
+		courseGroup.GET("", canReadMenus, course.ListCourses)                                                // Get courses
+		courseGroup.POST("", canWriteMenus, course.CreateCourse)                                             // Create course
+		courseGroup.GET("/:courseId", canReadMenus, course.GetCourse)                                        // Get course
+		courseGroup.PUT("/:courseId", canWriteMenus, course.UpdateCourse)                                    // Update course
+		courseGroup.DELETE("/:courseId", canWriteMenus, course.DeleteCourse)                                 // Delete course
+		courseGroup.GET("/:courseId/translations", canReadMenus, course.GetCourseTranslations)               // Get translations
+		courseGroup.PUT("/:courseId/translations/:locale", canWriteMenus, course.SetCourseTranslation)       // Set one locale
+		courseGroup.DELETE("/:courseId/translations/:locale", canWriteMenus, course.DeleteCourseTranslation) // Remove one locale
 	}
 }
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/auth"
+	"github.com/example/restosaas/apps/api/internal/authz"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// tenant is an organization with one restaurant and content in it
+type tenant struct {
+	org        db.Organization
+	owner      db.User
+	kitchen    db.User
+	restaurant db.Restaurant
+	params     map[string]string // Route parameter values for this tenant
+}
+
+func setupIsolationDB(t *testing.T) *gorm.DB {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("Skipping integration test - no TEST_DATABASE_URL configured")
+	}
+
+	gdb, err := gorm.Open(postgres.Open(dbURL), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(gdb); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return gdb
+}
+
+func createTenant(t *testing.T, gdb *gorm.DB, name string) tenant {
+	now := time.Now()
+	tn := tenant{
+		org:     db.Organization{ID: uuid.New(), Name: name, SubscriptionStatus: "ACTIVE", CreatedAt: now},
+		owner:   db.User{ID: uuid.New(), Email: name + "-owner@example.com", DisplayName: name + " owner", Role: db.RoleOwner, CreatedAt: now},
+		kitchen: db.User{ID: uuid.New(), Email: name + "-kitchen@example.com", DisplayName: name + " kitchen", Role: db.RoleCustomer, CreatedAt: now},
+	}
+	tn.restaurant = db.Restaurant{
+		ID: uuid.New(), OrgID: tn.org.ID, Slug: name + "-" + uuid.NewString()[:8], Name: name,
+		Slogan: "s", Place: "p", Genre: "g", Budget: "500-1500", Title: "t", CreatedAt: now, UpdatedAt: now,
+	}
+	category := db.MenuCategory{ID: uuid.New(), RestaurantID: tn.restaurant.ID, Name: "Mains", CreatedAt: now, UpdatedAt: now}
+	menu := db.Menu{ID: uuid.New(), RestaurantID: tn.restaurant.ID, Name: "Momo", Price: money.FromMajor(300, money.Default), Type: db.MenuTypeFood, MealType: db.MealTypeBoth, CreatedAt: now, UpdatedAt: now}
+	course := db.Course{ID: uuid.New(), RestaurantID: tn.restaurant.ID, Title: "Set", CoursePrice: money.FromMajor(1000, money.Default), StayTime: 90, CreatedAt: now, UpdatedAt: now}
+	image := db.Image{ID: uuid.New(), RestaurantID: tn.restaurant.ID, URL: "https://example.com/a.jpg"}
+	review := db.Review{ID: uuid.New(), RestaurantID: tn.restaurant.ID, Rating: 5, CreatedAt: now, UpdatedAt: now}
+	version := db.MenuVersion{ID: uuid.New(), RestaurantID: tn.restaurant.ID, Number: 1, Name: "Draft", Status: db.MenuVersionDraft, Snapshot: db.JSON(`{"categories":[],"items":[]}`), CreatedAt: now, UpdatedAt: now}
+
+	for _, v := range []interface{}{&tn.org, &tn.owner, &tn.kitchen, &tn.restaurant, &category, &menu, &course, &image, &review, &version,
+		&db.OrgMember{ID: uuid.New(), UserID: tn.owner.ID, OrgID: tn.org.ID, Role: db.OrgRoleOwner},
+		&db.OrgMember{ID: uuid.New(), UserID: tn.kitchen.ID, OrgID: tn.org.ID, Role: db.OrgRoleKitchen},
+	} {
+		require.NoError(t, gdb.Create(v).Error)
+	}
+
+	tn.params = map[string]string{
+		"id":         tn.restaurant.ID.String(),
+		"menuId":     menu.ID.String(),
+		"categoryId": category.ID.String(),
+		"courseId":   course.ID.String(),
+		"imageId":    image.ID.String(),
+		"reviewId":   review.ID.String(),
+		"versionId":  version.ID.String(),
+		"locale":     "ja",
+	}
+	return tn
+}
+
+// fillPath substitutes route parameters such as :id with values from params
+func fillPath(route string, params map[string]string) string {
+	parts := strings.Split(route, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			parts[i] = params[p[1:]]
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func call(t *testing.T, r *gin.Engine, method, path string, user db.User) *httptest.ResponseRecorder {
+	token, err := auth.IssueToken(user.ID.String(), string(user.Role))
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func callWithKey(r *gin.Engine, method, path, key string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// scopedRoutes returns every owner route addressing a single restaurant
+func scopedRoutes(r *gin.Engine) []gin.RouteInfo {
+	var routes []gin.RouteInfo
+	for _, route := range r.Routes() {
+		if strings.HasPrefix(route.Path, "/api/owner/restaurants/:id") {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+func TestTenantIsolation_OwnerRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "tenant-isolation-test")
+	t.Setenv("APP_ENV", "test")
+	gdb := setupIsolationDB(t)
+
+	a := createTenant(t, gdb, "tenant-a")
+	b := createTenant(t, gdb, "tenant-b")
+
+	// An API key of tenant A with every grantable scope
+	secret, prefix, err := auth.GenerateAPIKey()
+	require.NoError(t, err)
+	scopes := db.StringList{}
+	for _, p := range authz.APIKeyScopes {
+		scopes = append(scopes, string(p))
+	}
+	key := db.APIKey{ID: uuid.New(), OrgID: a.org.ID, Name: "pos", Prefix: prefix, Hash: auth.HashAPIKey(secret), Scopes: scopes, CreatedAt: time.Now()}
+	require.NoError(t, gdb.Create(&key).Error)
+
+	defer func() {
+		gdb.Where("id = ?", key.ID).Delete(&db.APIKey{})
+		for _, tn := range []tenant{a, b} {
+			gdb.Where("restaurant_id = ?", tn.restaurant.ID).Delete(&db.Review{})
+			gdb.Where("restaurant_id = ?", tn.restaurant.ID).Delete(&db.MenuVersion{})
+			gdb.Where("restaurant_id = ?", tn.restaurant.ID).Delete(&db.Image{})
+			gdb.Where("restaurant_id = ?", tn.restaurant.ID).Delete(&db.Course{})
+			gdb.Where("restaurant_id = ?", tn.restaurant.ID).Delete(&db.Menu{})
+			gdb.Where("id = ?", tn.restaurant.ID).Delete(&db.Restaurant{})
+			gdb.Where("org_id = ?", tn.org.ID).Delete(&db.OrgMember{})
+			gdb.Where("id = ?", tn.org.ID).Delete(&db.Organization{})
+			gdb.Where("id IN ?", []uuid.UUID{tn.owner.ID, tn.kitchen.ID}).Delete(&db.User{})
+		}
+	}()
+
+	r := gin.New()
+	Mount(r, gdb)
+	routes := scopedRoutes(r)
+	require.NotEmpty(t, routes)
+
+	for _, route := range routes {
+		route := route
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			// Another tenant's owner cannot see the restaurant at all
+			w := call(t, r, route.Method, fillPath(route.Path, b.params), a.owner)
+			assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+			// Neither can tenant A's API key
+			w = callWithKey(r, route.Method, fillPath(route.Path, b.params), secret)
+			assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+			// Child resources of another tenant are not reachable through
+			// the caller's own restaurant either
+			if strings.Count(route.Path, ":") > 1 {
+				mixed := map[string]string{}
+				for k, v := range b.params {
+					mixed[k] = v
+				}
+				mixed["id"] = a.params["id"]
+				w := call(t, r, route.Method, fillPath(route.Path, mixed), a.owner)
+				assert.Contains(t, []int{http.StatusBadRequest, http.StatusNotFound}, w.Code, w.Body.String())
+			}
+
+			// Kitchen staff may read, and mark items sold out, but not
+			// change anything else
+			if route.Method != http.MethodGet && !strings.HasSuffix(route.Path, "/sold-out") {
+				w := call(t, r, route.Method, fillPath(route.Path, b.params), b.kitchen)
+				assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Nothing in tenant B was changed
+	var count int64
+	gdb.Model(&db.Restaurant{}).Where("id = ?", b.restaurant.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+	gdb.Model(&db.Menu{}).Where("restaurant_id = ?", b.restaurant.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+	gdb.Model(&db.Review{}).Where("restaurant_id = ? AND is_approved = ?", b.restaurant.ID, true).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AvailableSlot is a time a search's party can be seated at a restaurant
+type AvailableSlot struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Available int       `json:"available"` // Seats free
+}
+
+const (
+	// DefaultAvailabilityWindow is how many minutes from the requested time
+	// a slot may start, either way
+	DefaultAvailabilityWindow = 30
+	// MaxAvailabilityWindow is the widest window
+	MaxAvailabilityWindow = 180
+)
+
+// availabilityBatch is how many restaurants have their opening hours and
+// reservations read at once
+const availabilityBatch = 500
+
+// openAtLayout is an open_at time without a zone, taken in each
+// restaurant's timezone
+const openAtLayout = "2006-01-02T15:04"
+
+// wantsTable reports whether a search is for a table at a date or time,
+// so that only restaurants with a free slot are found
+func (f SearchFilters) wantsTable() bool {
+	return f.Date != "" || f.Time != ""
+}
+
+// wantsHours reports whether a search is for restaurants open at a time,
+// which depends on each one's timezone and special hours
+func (f SearchFilters) wantsHours() bool {
+	return f.OpenNow || f.OpenAt != "" || f.OpenLate
+}
+
+// partySize is how many people a search is for, at least one
+func (f SearchFilters) partySize() int {
+	n, err := strconv.Atoi(f.People)
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// ParseOpenAt reads an open_at time: RFC 3339 for an instant, or
+// YYYY-MM-DDTHH:MM for that time of day wherever each restaurant is
+func ParseOpenAt(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(openAtLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid open_at %q, expected YYYY-MM-DDTHH:MM or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// applyPartyFilter keeps restaurants large enough for the party and, for a
+// date, open on it by their weekly or special hours. Whether a table is
+// free is checked afterwards by narrowRestaurants.
+func (s *SearchService) applyPartyFilter(query *gorm.DB, filters SearchFilters) *gorm.DB {
+	if filters.People != "" {
+		query = query.Where("capacity >= ?", filters.partySize())
+	}
+	if date, err := time.Parse(dateLayout, filters.Date); err == nil {
+		query = query.Where(`(
+			EXISTS (SELECT 1 FROM opening_hours
+				WHERE opening_hours.restaurant_id = restaurants.id AND opening_hours.weekday = ? AND opening_hours.is_closed = false)
+			OR EXISTS (SELECT 1 FROM special_hours
+				WHERE special_hours.restaurant_id = restaurants.id AND special_hours.date = ? AND special_hours.is_closed = false)
+		)`, int(date.Weekday()), filters.Date)
+	}
+	return query
+}
+
+// applyHoursFilter keeps restaurants open on the weekday of the filters by
+// their weekly hours. Open now, open at and open late are checked
+// afterwards by narrowRestaurants.
+func (s *SearchService) applyHoursFilter(query *gorm.DB, filters SearchFilters) *gorm.DB {
+	if filters.OpenOn == nil {
+		return query
+	}
+	return query.Where(`EXISTS (SELECT 1 FROM opening_hours
+		WHERE opening_hours.restaurant_id = restaurants.id AND opening_hours.weekday = ? AND opening_hours.is_closed = false)`,
+		*filters.OpenOn)
+}
+
+// narrowSearch finds the restaurants open at the times of a search and,
+// for a table, with a slot for the party, with their free slots by
+// restaurant ID. Restaurants are narrowed down before the facet filters are
+// applied, so that each facet is counted among them against the other
+// filters; findRestaurants applies the facet filters to the results. The
+// IDs are nil for searches that are not narrowed.
+func (s *SearchService) narrowSearch(filters SearchFilters) ([]uuid.UUID, map[string][]AvailableSlot, error) {
+	if !filters.wantsTable() && !filters.wantsHours() {
+		return nil, nil, nil
+	}
+	query := s.applyFilters(s.DB.Model(&db.Restaurant{}), filters, facetNames...)
+	ids, slots, err := s.narrowRestaurants(query, filters, time.Now())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check opening hours and availability: %w", err)
+	}
+	if ids == nil {
+		ids = []uuid.UUID{}
+	}
+	return ids, slots, nil
+}
+
+// findRestaurants fetches a page of the restaurants of a sorted query, kept
+// to the narrowed IDs unless nil, and how many there are in all
+func (s *SearchService) findRestaurants(query *gorm.DB, narrowed []uuid.UUID, filters SearchFilters) ([]db.Restaurant, int64, error) {
+	if narrowed != nil {
+		query = inNarrowed(query, narrowed)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count restaurants: %w", err)
+	}
+	var restaurants []db.Restaurant
+	offset := (filters.Page - 1) * filters.Limit
+	if err := query.Offset(offset).Limit(filters.Limit).Find(&restaurants).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch restaurants: %w", err)
+	}
+	return restaurants, total, nil
+}
+
+// inNarrowed keeps the narrowed restaurants of a query. The IDs are one
+// array parameter, however many restaurants there are.
+func inNarrowed(query *gorm.DB, narrowed []uuid.UUID) *gorm.DB {
+	ids := make([]string, len(narrowed))
+	for i, id := range narrowed {
+		ids[i] = id.String()
+	}
+	return query.Where("restaurants.id = ANY(?::uuid[])", "{"+strings.Join(ids, ",")+"}")
+}
+
+// narrowRestaurants narrows the restaurants of a query to those open at the
+// times of the filters and, for a table, with a slot for the party within
+// the window of the requested time (any time of the day without one).
+// Opening hours and reservations are read for a batch of restaurants at a
+// time rather than for each restaurant or slot.
+func (s *SearchService) narrowRestaurants(query *gorm.DB, filters SearchFilters, now time.Time) ([]uuid.UUID, map[string][]AvailableSlot, error) {
+	var candidates []db.Restaurant
+	if err := query.Select("restaurants.id", "restaurants.capacity", "restaurants.timezone").Scan(&candidates).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// The days whose hours and reservations are needed
+	days := []time.Time{now}
+	resvFrom, resvTo := dayBounds(now)
+	if date, err := time.Parse(dateLayout, filters.Date); err == nil {
+		days = append(days, date)
+		resvFrom, resvTo = dayBounds(date)
+	}
+	if at, err := ParseOpenAt(filters.OpenAt, time.UTC); err == nil {
+		days = append(days, at)
+	}
+	from, to := hoursSpan(days...)
+
+	var ids []uuid.UUID
+	slots := make(map[string][]AvailableSlot)
+	for start := 0; start < len(candidates); start += availabilityBatch {
+		batch := candidates[start:]
+		if len(batch) > availabilityBatch {
+			batch = batch[:availabilityBatch]
+		}
+		hours, err := LoadOpeningHours(s.DB, batch, from, to)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		byRestaurant := make(map[uuid.UUID][]db.Reservation)
+		if filters.wantsTable() {
+			batchIDs := make([]uuid.UUID, len(batch))
+			for i, r := range batch {
+				batchIDs[i] = r.ID
+			}
+			reservations, err := activeReservations(s.DB, batchIDs, resvFrom, resvTo)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, resv := range reservations {
+				byRestaurant[resv.RestaurantID] = append(byRestaurant[resv.RestaurantID], resv)
+			}
+		}
+
+		for _, r := range batch {
+			if !openMatches(hours[r.ID], filters, now) {
+				continue
+			}
+			if filters.wantsTable() {
+				found := matchingSlots(r, hours[r.ID], byRestaurant[r.ID], filters, now)
+				if len(found) == 0 {
+					continue
+				}
+				slots[r.ID.String()] = found
+			}
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids, slots, nil
+}
+
+// openMatches checks a restaurant's hours against the open now, open at
+// and open late filters. Open late is checked on the day of open_at, else
+// of the table's date, else today where the restaurant is.
+func openMatches(hours *OpeningHours, filters SearchFilters, now time.Time) bool {
+	if filters.OpenNow && !hours.OpenAt(now) {
+		return false
+	}
+	day := now
+	if date, err := time.ParseInLocation(dateLayout, filters.Date, hours.loc); err == nil {
+		day = date
+	}
+	if filters.OpenAt != "" {
+		at, err := ParseOpenAt(filters.OpenAt, hours.loc)
+		if err != nil || !hours.OpenAt(at) {
+			return false
+		}
+		day = at
+	}
+	if filters.OpenLate && !hours.OpenLate(day) {
+		return false
+	}
+	return true
+}
+
+// matchingSlots returns the slots of a restaurant on the search's date
+// (today where the restaurant is, without one) that are yet to start, seat
+// the party and start within the window of the search's time
+func matchingSlots(r db.Restaurant, hours *OpeningHours, reservations []db.Reservation, filters SearchFilters, now time.Time) []AvailableSlot {
+	loc := RestaurantLocation(r)
+	date := now.In(loc)
+	if d, err := time.Parse(dateLayout, filters.Date); err == nil {
+		date = d
+	}
+
+	var target time.Time
+	window := time.Duration(filters.Window) * time.Minute
+	if t, err := time.Parse("15:04", filters.Time); err == nil {
+		target = time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+		if filters.Window == 0 {
+			window = DefaultAvailabilityWindow * time.Minute
+		}
+	}
+
+	party := filters.partySize()
+	var found []AvailableSlot
+	for _, slot := range daySlots(r, hours.On(date), reservations, date) {
+		if slot.Start.Before(now) || slot.Available < party {
+			continue
+		}
+		if !target.IsZero() && (slot.Start.Before(target.Add(-window)) || slot.Start.After(target.Add(window))) {
+			continue
+		}
+		found = append(found, AvailableSlot{Start: slot.Start, End: slot.End, Available: slot.Available})
+	}
+	return found
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func slotStarts(slots []AvailableSlot) []string {
+	starts := make([]string, len(slots))
+	for i, slot := range slots {
+		starts[i] = slot.Start.In(kathmandu).Format("15:04")
+	}
+	return starts
+}
+
+func TestDaySlots(t *testing.T) {
+	r := db.Restaurant{ID: uuid.New(), Timezone: "Asia/Kathmandu", Capacity: 10}
+	day := DayHours{Date: "2025-06-02", OpenTime: "17:00", CloseTime: "20:00"}
+	reservations := []db.Reservation{
+		{RestaurantID: r.ID, StartsAt: at("2025-06-02", "19:00"), DurationMin: 90, PartySize: 6},
+		{RestaurantID: uuid.New(), StartsAt: at("2025-06-02", "19:00"), DurationMin: 90, PartySize: 6},
+	}
+
+	slots := daySlots(r, day, reservations, at("2025-06-02", "00:00"))
+	var available []int
+	for _, slot := range slots {
+		available = append(available, slot.Available)
+	}
+	// Parties arriving from 18:00 would still be seated at 19:00
+	assert.Equal(t, []int{10, 10, 4, 4, 4, 4}, available)
+	assert.Equal(t, at("2025-06-02", "17:00"), slots[0].Start)
+
+	// Past midnight
+	late := DayHours{Date: "2025-06-02", OpenTime: "23:00", CloseTime: "01:00"}
+	assert.Len(t, daySlots(r, late, nil, at("2025-06-02", "00:00")), 4)
+
+	day.IsClosed = true
+	assert.Empty(t, daySlots(r, day, nil, at("2025-06-02", "00:00")))
+}
+
+func TestMatchingSlots(t *testing.T) {
+	r := db.Restaurant{ID: uuid.New(), Timezone: "Asia/Kathmandu", Capacity: 10}
+	hours := NewOpeningHours(kathmandu, []db.OpeningHour{{Weekday: int(time.Monday), OpenTime: "17:00", CloseTime: "22:00"}}, nil)
+	reservations := []db.Reservation{{RestaurantID: r.ID, StartsAt: at("2025-06-02", "19:00"), DurationMin: 60, PartySize: 8}}
+	now := at("2025-06-01", "12:00")
+
+	filters := SearchFilters{People: "4", Date: "2025-06-02", Time: "19:00"}
+	assert.Empty(t, matchingSlots(r, hours, reservations, filters, now))
+	assert.Len(t, matchingSlots(r, hours, reservations, SearchFilters{People: "2", Date: "2025-06-02", Time: "19:00"}, now), 3)
+
+	filters.Window = 60
+	assert.Equal(t, []string{"20:00"}, slotStarts(matchingSlots(r, hours, reservations, filters, now)))
+	filters.Window = 120
+	assert.Equal(t, []string{"17:00", "17:30", "20:00", "20:30", "21:00"}, slotStarts(matchingSlots(r, hours, reservations, filters, now)))
+
+	// Any time of the day, but not in the past
+	day := SearchFilters{People: "4", Date: "2025-06-02"}
+	assert.Equal(t, []string{"20:00", "20:30", "21:00", "21:30"}, slotStarts(matchingSlots(r, hours, reservations, day, at("2025-06-02", "19:45"))))
+
+	// Closed on the day, by special hours, or too large a party
+	holiday := NewOpeningHours(kathmandu, hours.Weekly(), []db.SpecialHour{{Date: "2025-06-02", IsClosed: true}})
+	assert.Empty(t, matchingSlots(r, holiday, nil, SearchFilters{Date: "2025-06-02"}, now))
+	assert.Empty(t, matchingSlots(r, hours, nil, SearchFilters{Date: "2025-06-03"}, now))
+	assert.Empty(t, matchingSlots(r, hours, nil, SearchFilters{People: "11", Date: "2025-06-02"}, now))
+
+	// Today where the restaurant is without a date
+	today := SearchFilters{Time: "21:30"}
+	assert.Equal(t, []string{"21:00", "21:30"}, slotStarts(matchingSlots(r, hours, nil, today, at("2025-06-02", "18:00"))))
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/example/restosaas/apps/api/internal/budget"
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/dietary"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Facets of a search, each left out of the filters when its own values are
+// counted
+const (
+	FacetCuisine    = "cuisine"
+	FacetArea       = "area"
+	FacetPlace      = "place"
+	FacetPriceLevel = "price_level"
+	FacetRating     = "rating"
+	FacetDiet       = "diet"
+)
+
+var facetNames = []string{FacetCuisine, FacetArea, FacetPlace, FacetPriceLevel, FacetRating, FacetDiet}
+
+// facetLimit is how many values of a cuisine, area or place facet are
+// counted, the most common first
+const facetLimit = 20
+
+// Facets count the restaurants of a search by cuisine, area, place, price
+// level, rating band and diet tag. Each facet is counted with the other
+// filters of the search but not its own, so that selecting another of its
+// values adds the restaurants it shows.
+type Facets struct {
+	Cuisine    []FacetValue `json:"cuisine"`
+	Area       []FacetValue `json:"area"`
+	Place      []FacetValue `json:"place"`
+	PriceLevel []FacetValue `json:"price_level"` // 1 ($) to 4 ($$$$), known levels only
+	Rating     []FacetValue `json:"rating"`      // Whole stars, 4 for 4.0 and up, 0 when not rated
+	Diet       []FacetValue `json:"diet"`        // dietary.Tags of at least one menu item or course
+}
+
+// FacetValue is a value of a facet and how many restaurants have it
+type FacetValue struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"` // Price levels only, e.g. "$$"
+	Count int64  `json:"count"`
+}
+
+// countFacets counts the facets of a search, among the narrowed restaurants
+// unless nil (see narrowSearch)
+func (s *SearchService) countFacets(filters SearchFilters, narrowed []uuid.UUID) (*Facets, error) {
+	restaurants := func(except string) *gorm.DB {
+		query := s.applyFilters(s.DB.Model(&db.Restaurant{}), filters, except)
+		if narrowed != nil {
+			query = inNarrowed(query, narrowed)
+		}
+		return query
+	}
+
+	facets := &Facets{}
+	columns := []struct {
+		name  string
+		value string // SQL of the value
+		known string // Condition of restaurants with a value
+		order string
+		limit int
+		into  *[]FacetValue
+	}{
+		{FacetCuisine, "restaurants.genre", "restaurants.genre <> ''", "count DESC, value", facetLimit, &facets.Cuisine},
+		{FacetArea, "restaurants.area", "restaurants.area <> ''", "count DESC, value", facetLimit, &facets.Area},
+		{FacetPlace, "restaurants.place", "restaurants.place <> ''", "count DESC, value", facetLimit, &facets.Place},
+		{FacetPriceLevel, "restaurants.price_level::text", "restaurants.price_level > 0", "value", -1, &facets.PriceLevel},
+		{FacetRating, s.getRatingBand() + "::text", "true", "value DESC", -1, &facets.Rating},
+	}
+	for _, column := range columns {
+		values := []FacetValue{}
+		err := restaurants(column.name).
+			Select(column.value + " AS value, COUNT(*) AS count").
+			Where(column.known).
+			Group("value").
+			Order(column.order).
+			Limit(column.limit).
+			Scan(&values).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %w", column.name, err)
+		}
+		*column.into = values
+	}
+	for i, v := range facets.PriceLevel {
+		level, _ := strconv.Atoi(v.Value)
+		facets.PriceLevel[i].Label = budget.Symbol(level)
+	}
+
+	facets.Diet = []FacetValue{}
+	err := s.DB.Raw(`SELECT tag AS value, COUNT(DISTINCT restaurant_id) AS count FROM (
+			SELECT restaurant_id, jsonb_array_elements_text(dietary_tags) AS tag FROM menus
+			UNION ALL
+			SELECT restaurant_id, jsonb_array_elements_text(dietary_tags) FROM courses
+		) AS tags
+		WHERE restaurant_id IN (?) AND tag IN ?
+		GROUP BY tag
+		ORDER BY count DESC, tag`,
+		restaurants(FacetDiet).Select("restaurants.id"), dietary.Tags,
+	).Scan(&facets.Diet).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s facet: %w", FacetDiet, err)
+	}
+	return facets, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/dietary"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterValues(t *testing.T) {
+	assert.Equal(t, []string{"Newari", "Thakali"}, filterValues(" Newari ", []string{"", "Thakali"}))
+	assert.Equal(t, []string{"Thakali"}, filterValues("", []string{"Thakali"}))
+	assert.Nil(t, filterValues("", nil))
+}
+
+func TestCountFacets_Integration(t *testing.T) {
+	gdb := setupSearchDB(t)
+	f := newSearchFixture(t, gdb)
+	word := searchWord()
+	newari, thakali := word+"newari", word+"thakali"
+	thamel, patan := word+" Thamel", word+" Patan"
+
+	rated := f.restaurant(db.Restaurant{Genre: newari, Area: thamel, PriceLevel: 2})
+	f.review(rated, 5, true)
+	f.review(rated, 4, true)
+	f.review(rated, 1, false) // Not approved, not counted
+	f.dish(rated, "Bara", dietary.Vegan)
+	f.dish(rated, "Chatamari", dietary.Vegan, dietary.Vegetarian)
+
+	other := f.restaurant(db.Restaurant{Genre: thakali, Area: thamel, PriceLevel: 2})
+	f.review(other, 3, true)
+	f.dish(other, "Dal bhat", dietary.Vegetarian)
+
+	elsewhere := f.restaurant(db.Restaurant{Genre: thakali, Area: patan, PriceLevel: 2})
+	f.dish(elsewhere, "Dhido", dietary.Vegan)
+	pricier := f.restaurant(db.Restaurant{Genre: newari, Area: thamel, PriceLevel: 4})
+	f.dish(pricier, "Yomari", dietary.Halal)
+
+	service := NewSearchService(gdb)
+	filters := SearchFilters{Cuisine: newari, Area: thamel, PriceLevels: []int{2}}
+	facets, err := service.countFacets(filters, nil)
+	require.NoError(t, err)
+
+	// The cuisine facet leaves out cuisine= but not area= or price levels
+	assert.Equal(t, []FacetValue{{Value: newari, Count: 1}, {Value: thakali, Count: 1}}, facets.Cuisine)
+	assert.Equal(t, []FacetValue{{Value: thamel, Count: 1}}, facets.Area)
+	assert.Equal(t, []FacetValue{{Value: "2", Label: "$$", Count: 1}, {Value: "4", Label: "$$$$", Count: 1}}, facets.PriceLevel)
+	assert.Equal(t, []FacetValue{{Value: "4", Count: 1}}, facets.Rating)
+	// Restaurants with a tag, not items
+	assert.Equal(t, []FacetValue{{Value: dietary.Vegan, Count: 1}, {Value: dietary.Vegetarian, Count: 1}}, facets.Diet)
+
+	filters = SearchFilters{Area: thamel}
+	facets, err = service.countFacets(filters, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []FacetValue{{Value: "4", Count: 1}, {Value: "3", Count: 1}, {Value: "0", Count: 1}}, facets.Rating)
+	assert.Equal(t, []FacetValue{
+		{Value: dietary.Vegetarian, Count: 2},
+		{Value: dietary.Halal, Count: 1},
+		{Value: dietary.Vegan, Count: 1},
+	}, facets.Diet)
+
+	// Narrowed searches, e.g. open now, count among the narrowed
+	// restaurants, each facet still leaving out its own filter
+	filters = SearchFilters{Cuisine: newari, Area: thamel}
+	facets, err = service.countFacets(filters, []uuid.UUID{rated.ID, other.ID, elsewhere.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []FacetValue{{Value: newari, Count: 1}, {Value: thakali, Count: 1}}, facets.Cuisine)
+	assert.Equal(t, []FacetValue{{Value: thamel, Count: 1}}, facets.Area)
+	assert.Equal(t, []FacetValue{{Value: dietary.Vegan, Count: 1}, {Value: dietary.Vegetarian, Count: 1}}, facets.Diet)
+
+	// Searches for restaurants open at a time find those matching every
+	// filter, with facets counted as above
+	for _, r := range []db.Restaurant{rated, other, elsewhere} {
+		f.hours(r, 1, "09:00", "22:00")
+	}
+	result, err := service.SearchRestaurants(SearchFilters{Cuisine: newari, Area: thamel, OpenAt: "2025-06-02T12:00"}) // A Monday
+	require.NoError(t, err)
+	assert.Equal(t, []string{rated.ID.String()}, resultIDs(result))
+	assert.Equal(t, []FacetValue{{Value: newari, Count: 1}, {Value: thakali, Count: 1}}, result.Facets.Cuisine)
+
+	facets, err = service.countFacets(filters, []uuid.UUID{})
+	require.NoError(t, err)
+	assert.Empty(t, facets.Cuisine)
+	assert.Empty(t, facets.Diet)
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/google/uuid"
+)
+
+// AllWeekdays is the MenuAvailability.Weekdays mask for every day
+const AllWeekdays = 1<<7 - 1
+
+const dateLayout = "2006-01-02"
+
+// MenuSchedule answers whether a restaurant's menu items can be ordered at
+// a given time, from its availability rules and sold-out flags
+type MenuSchedule struct {
+	loc           *time.Location
+	menuRules     map[uuid.UUID][]db.MenuAvailability
+	categoryRules map[uuid.UUID][]db.MenuAvailability
+	parents       map[uuid.UUID]*uuid.UUID
+}
+
+// NewMenuSchedule indexes a restaurant's rules and categories. Times are
+// evaluated in loc, the restaurant's timezone.
+func NewMenuSchedule(loc *time.Location, rules []db.MenuAvailability, categories []db.MenuCategory) *MenuSchedule {
+	s := &MenuSchedule{
+		loc:           loc,
+		menuRules:     make(map[uuid.UUID][]db.MenuAvailability),
+		categoryRules: make(map[uuid.UUID][]db.MenuAvailability),
+		parents:       make(map[uuid.UUID]*uuid.UUID, len(categories)),
+	}
+	for _, rule := range rules {
+		switch {
+		case rule.MenuID != nil:
+			s.menuRules[*rule.MenuID] = append(s.menuRules[*rule.MenuID], rule)
+		case rule.CategoryID != nil:
+			s.categoryRules[*rule.CategoryID] = append(s.categoryRules[*rule.CategoryID], rule)
+		}
+	}
+	for _, category := range categories {
+		s.parents[category.ID] = category.ParentID
+	}
+	return s
+}
+
+// Available reports whether the item can be ordered at the given time: it
+// is not sold out and one of the rules that apply to it matches
+func (s *MenuSchedule) Available(menu db.Menu, at time.Time) bool {
+	if menu.SoldOutUntil != nil && at.Before(*menu.SoldOutUntil) {
+		return false
+	}
+	rules := s.rulesFor(menu)
+	if len(rules) == 0 {
+		return true
+	}
+	local := at.In(s.loc)
+	for _, rule := range rules {
+		if RuleMatches(rule, local) {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesFor returns the item's own rules, or else those of its nearest
+// category that has any
+func (s *MenuSchedule) rulesFor(menu db.Menu) []db.MenuAvailability {
+	if rules := s.menuRules[menu.ID]; len(rules) > 0 {
+		return rules
+	}
+	seen := make(map[uuid.UUID]bool)
+	for id := menu.CategoryID; id != nil && !seen[*id]; id = s.parents[*id] {
+		if rules := s.categoryRules[*id]; len(rules) > 0 {
+			return rules
+		}
+		seen[*id] = true
+	}
+	return nil
+}
+
+// RuleMatches reports whether local, a time in the restaurant's timezone,
+// falls in the rule. A window ending before it starts runs past midnight;
+// its early hours count as part of the previous day.
+func RuleMatches(rule db.MenuAvailability, local time.Time) bool {
+	if rule.StartTime == "" && rule.EndTime == "" {
+		return onDay(rule, local)
+	}
+	start, err := ParseClock(rule.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(rule.EndTime)
+	if err != nil {
+		return false
+	}
+	now := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return onDay(rule, local) && now >= start && now < end
+	}
+	// Past midnight, e.g. 22:00-02:00
+	if now >= start {
+		return onDay(rule, local)
+	}
+	return now < end && onDay(rule, local.AddDate(0, 0, -1))
+}
+
+// onDay reports whether the rule applies on local's weekday and date
+func onDay(rule db.MenuAvailability, local time.Time) bool {
+	if rule.Weekdays&(1<<uint(local.Weekday())) == 0 {
+		return false
+	}
+	date := local.Format(dateLayout)
+	if rule.StartDate != "" && date < rule.StartDate {
+		return false
+	}
+	if rule.EndDate != "" && date > rule.EndDate {
+		return false
+	}
+	return true
+}
+
+// ParseClock parses an "HH:MM" time of day into minutes after midnight
+func ParseClock(hm string) (int, error) {
+	t, err := time.Parse("15:04", hm)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", hm)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ParseDate checks a "YYYY-MM-DD" date
+func ParseDate(date string) error {
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+	return nil
+}
+
+// NextDayStart returns the next midnight after at in loc, when a sold-out
+// item is put back on sale by default
+func NextDayStart(at time.Time, loc *time.Location) time.Time {
+	local := at.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+}
+
+// RestaurantLocation returns the restaurant's timezone, or UTC if it is not
+// a known zone
+func RestaurantLocation(r db.Restaurant) *time.Location {
+	if loc, err := time.LoadLocation(r.Timezone); err == nil && r.Timezone != "" {
+		return loc
+	}
+	return time.UTC
+}
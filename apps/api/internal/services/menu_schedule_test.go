@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var kathmandu, _ = time.LoadLocation("Asia/Kathmandu")
+
+// at returns a local time in Kathmandu; 2025-06-02 is a Monday
+func at(date, clock string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, kathmandu)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func weekdays(days ...time.Weekday) int {
+	mask := 0
+	for _, d := range days {
+		mask |= 1 << uint(d)
+	}
+	return mask
+}
+
+func TestRuleMatches(t *testing.T) {
+	lunch := db.MenuAvailability{Weekdays: weekdays(time.Monday, time.Tuesday), StartTime: "11:00", EndTime: "15:00"}
+	assert.True(t, RuleMatches(lunch, at("2025-06-02", "11:00")))
+	assert.True(t, RuleMatches(lunch, at("2025-06-03", "14:59")))
+	assert.False(t, RuleMatches(lunch, at("2025-06-02", "15:00")))
+	assert.False(t, RuleMatches(lunch, at("2025-06-04", "12:00")))
+
+	// Windows past midnight belong to the day they start
+	late := db.MenuAvailability{Weekdays: weekdays(time.Friday), StartTime: "22:00", EndTime: "02:00"}
+	assert.True(t, RuleMatches(late, at("2025-06-06", "23:30")))
+	assert.True(t, RuleMatches(late, at("2025-06-07", "01:30")))
+	assert.False(t, RuleMatches(late, at("2025-06-07", "23:30")))
+	assert.False(t, RuleMatches(late, at("2025-06-06", "01:30")))
+
+	// Seasonal, all day
+	winter := db.MenuAvailability{Weekdays: AllWeekdays, StartDate: "2025-12-01", EndDate: "2026-02-28"}
+	assert.True(t, RuleMatches(winter, at("2025-12-01", "00:00")))
+	assert.True(t, RuleMatches(winter, at("2026-02-28", "23:59")))
+	assert.False(t, RuleMatches(winter, at("2026-03-01", "12:00")))
+	assert.False(t, RuleMatches(winter, at("2025-06-02", "12:00")))
+}
+
+func TestMenuSchedule_Available(t *testing.T) {
+	food := db.MenuCategory{ID: uuid.New()}
+	momo := db.MenuCategory{ID: uuid.New(), ParentID: &food.ID}
+	drinks := db.MenuCategory{ID: uuid.New()}
+
+	lunchOnly := uuid.New()
+	steamed := db.Menu{ID: uuid.New(), CategoryID: &momo.ID}
+	special := db.Menu{ID: lunchOnly, CategoryID: &momo.ID}
+	lassi := db.Menu{ID: uuid.New(), CategoryID: &drinks.ID}
+	loose := db.Menu{ID: uuid.New()}
+
+	rules := []db.MenuAvailability{
+		{CategoryID: &food.ID, Weekdays: AllWeekdays, StartTime: "11:00", EndTime: "22:00"},
+		{MenuID: &lunchOnly, Weekdays: AllWeekdays, StartTime: "11:00", EndTime: "15:00"},
+	}
+	s := NewMenuSchedule(kathmandu, rules, []db.MenuCategory{food, momo, drinks})
+
+	// Inherited from the parent category
+	assert.True(t, s.Available(steamed, at("2025-06-02", "20:00")))
+	assert.False(t, s.Available(steamed, at("2025-06-02", "23:00")))
+
+	// The item's own rules win over the category's
+	assert.True(t, s.Available(special, at("2025-06-02", "12:00")))
+	assert.False(t, s.Available(special, at("2025-06-02", "20:00")))
+
+	// No rules anywhere: always available
+	assert.True(t, s.Available(lassi, at("2025-06-02", "03:00")))
+	assert.True(t, s.Available(loose, at("2025-06-02", "03:00")))
+
+	// Sold out until the next day, then back automatically
+	until := NextDayStart(at("2025-06-02", "13:00"), kathmandu)
+	assert.Equal(t, at("2025-06-03", "00:00"), until)
+	lassi.SoldOutUntil = &until
+	assert.False(t, s.Available(lassi, at("2025-06-02", "18:00")))
+	assert.True(t, s.Available(lassi, at("2025-06-03", "00:00")))
+}
+
+func TestParseClock(t *testing.T) {
+	m, err := ParseClock("09:30")
+	assert.NoError(t, err)
+	assert.Equal(t, 570, m)
+
+	for _, bad := range []string{"", "9", "24:00", "12:60", "noon"} {
+		_, err := ParseClock(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRestaurantLocation(t *testing.T) {
+	assert.Equal(t, "Asia/Kathmandu", RestaurantLocation(db.Restaurant{Timezone: "Asia/Kathmandu"}).String())
+	assert.Equal(t, time.UTC, RestaurantLocation(db.Restaurant{Timezone: "Mars/Olympus"}))
+	assert.Equal(t, time.UTC, RestaurantLocation(db.Restaurant{}))
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LateHour is the time a restaurant open late is still open at
+const LateHour = "22:00"
+
+// DayHours are a restaurant's hours on one date: its special hours for the
+// date, or else its weekly hours for the weekday
+type DayHours struct {
+	Date      string `json:"date"`
+	OpenTime  string `json:"open_time,omitempty"`
+	CloseTime string `json:"close_time,omitempty"` // Before OpenTime when open past midnight
+	IsClosed  bool   `json:"is_closed"`
+	Special   bool   `json:"special"`        // Special hours replace the weekly ones on Date
+	Note      string `json:"note,omitempty"` // Of special hours, e.g. "Closed for Dashain"
+}
+
+// window returns the opening and closing of the day in minutes after
+// midnight. Hours past midnight close after 24:00.
+func (d DayHours) window() (int, int, bool) {
+	if d.IsClosed {
+		return 0, 0, false
+	}
+	opens, err := ParseClock(d.OpenTime)
+	if err != nil {
+		return 0, 0, false
+	}
+	closes, err := ParseClock(d.CloseTime)
+	if err != nil {
+		return 0, 0, false
+	}
+	if closes <= opens {
+		closes += 24 * 60
+	}
+	return opens, closes, true
+}
+
+// OpeningHours answers when a restaurant is open, from its weekly hours and
+// the special hours that replace them on particular dates
+type OpeningHours struct {
+	loc     *time.Location
+	weekly  []db.OpeningHour
+	byDay   map[int]db.OpeningHour
+	special map[string]db.SpecialHour
+}
+
+// NewOpeningHours indexes a restaurant's hours. Times are evaluated in loc,
+// the restaurant's timezone. A weekday without hours is closed.
+func NewOpeningHours(loc *time.Location, weekly []db.OpeningHour, special []db.SpecialHour) *OpeningHours {
+	h := &OpeningHours{
+		loc:     loc,
+		weekly:  append([]db.OpeningHour(nil), weekly...),
+		byDay:   make(map[int]db.OpeningHour, len(weekly)),
+		special: make(map[string]db.SpecialHour, len(special)),
+	}
+	sort.SliceStable(h.weekly, func(i, j int) bool { return h.weekly[i].Weekday < h.weekly[j].Weekday })
+	for _, oh := range h.weekly {
+		if _, ok := h.byDay[oh.Weekday]; !ok {
+			h.byDay[oh.Weekday] = oh
+		}
+	}
+	for _, sh := range special {
+		h.special[sh.Date] = sh
+	}
+	return h
+}
+
+// Weekly returns the weekly hours, Sunday first
+func (h *OpeningHours) Weekly() []db.OpeningHour {
+	return h.weekly
+}
+
+// On returns the hours of a date, taken as a calendar date
+func (h *OpeningHours) On(date time.Time) DayHours {
+	day := date.Format(dateLayout)
+	if sh, ok := h.special[day]; ok {
+		return DayHours{Date: day, OpenTime: sh.OpenTime, CloseTime: sh.CloseTime, IsClosed: sh.IsClosed, Special: true, Note: sh.Note}
+	}
+	oh, ok := h.byDay[int(date.Weekday())]
+	if !ok {
+		return DayHours{Date: day, IsClosed: true}
+	}
+	return DayHours{Date: day, OpenTime: oh.OpenTime, CloseTime: oh.CloseTime, IsClosed: oh.IsClosed}
+}
+
+// OpenAt reports whether the restaurant is open at t. Hours past midnight
+// belong to the day they start, as with RuleMatches.
+func (h *OpeningHours) OpenAt(t time.Time) bool {
+	local := t.In(h.loc)
+	now := local.Hour()*60 + local.Minute()
+	if opens, closes, ok := h.On(local).window(); ok && now >= opens && now < closes {
+		return true
+	}
+	opens, closes, ok := h.On(local.AddDate(0, 0, -1)).window()
+	return ok && now+24*60 >= opens && now+24*60 < closes
+}
+
+// OpenLate reports whether the restaurant is still open at LateHour on the
+// date of t where the restaurant is
+func (h *OpeningHours) OpenLate(t time.Time) bool {
+	local := t.In(h.loc)
+	late, _ := ParseClock(LateHour)
+	return h.OpenAt(time.Date(local.Year(), local.Month(), local.Day(), late/60, late%60, 0, 0, h.loc))
+}
+
+// LoadOpeningHours reads the weekly hours of restaurants and their special
+// hours from one date to another (YYYY-MM-DD), inclusive, in two queries
+func LoadOpeningHours(gdb *gorm.DB, restaurants []db.Restaurant, from, to string) (map[uuid.UUID]*OpeningHours, error) {
+	ids := make([]uuid.UUID, len(restaurants))
+	for i, r := range restaurants {
+		ids[i] = r.ID
+	}
+
+	var weekly []db.OpeningHour
+	if err := gdb.Where("restaurant_id IN ?", ids).Find(&weekly).Error; err != nil {
+		return nil, err
+	}
+	var special []db.SpecialHour
+	if err := gdb.Where("restaurant_id IN ? AND date BETWEEN ? AND ?", ids, from, to).Find(&special).Error; err != nil {
+		return nil, err
+	}
+
+	weeklyOf := make(map[uuid.UUID][]db.OpeningHour)
+	for _, oh := range weekly {
+		weeklyOf[oh.RestaurantID] = append(weeklyOf[oh.RestaurantID], oh)
+	}
+	specialOf := make(map[uuid.UUID][]db.SpecialHour)
+	for _, sh := range special {
+		specialOf[sh.RestaurantID] = append(specialOf[sh.RestaurantID], sh)
+	}
+
+	hours := make(map[uuid.UUID]*OpeningHours, len(restaurants))
+	for _, r := range restaurants {
+		hours[r.ID] = NewOpeningHours(RestaurantLocation(r), weeklyOf[r.ID], specialOf[r.ID])
+	}
+	return hours, nil
+}
+
+// hoursSpan is the dates to read special hours for, so that those of the
+// days around the given times can be looked up in any timezone
+func hoursSpan(times ...time.Time) (string, string) {
+	from, to := times[0], times[0]
+	for _, t := range times[1:] {
+		if t.Before(from) {
+			from = t
+		}
+		if t.After(to) {
+			to = t
+		}
+	}
+	return from.AddDate(0, 0, -2).Format(dateLayout), to.AddDate(0, 0, 2).Format(dateLayout)
+}
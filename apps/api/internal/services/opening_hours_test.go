@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpeningHours(t *testing.T) {
+	hours := NewOpeningHours(kathmandu, []db.OpeningHour{
+		{Weekday: int(time.Monday), OpenTime: "11:00", CloseTime: "21:00"},
+		{Weekday: int(time.Friday), OpenTime: "18:00", CloseTime: "02:00"},
+		{Weekday: int(time.Saturday), OpenTime: "11:00", CloseTime: "21:00", IsClosed: true},
+	}, []db.SpecialHour{
+		{Date: "2025-06-09", IsClosed: true, Note: "Holiday"},
+		{Date: "2025-06-10", OpenTime: "17:00", CloseTime: "23:30", Note: "Festival night"},
+	})
+
+	assert.True(t, hours.OpenAt(at("2025-06-02", "11:00")))
+	assert.False(t, hours.OpenAt(at("2025-06-02", "21:00")))
+	assert.False(t, hours.OpenAt(at("2025-06-03", "12:00")))
+
+	// Friday's hours run into Saturday, which is otherwise closed
+	assert.True(t, hours.OpenAt(at("2025-06-06", "23:30")))
+	assert.True(t, hours.OpenAt(at("2025-06-07", "01:59")))
+	assert.False(t, hours.OpenAt(at("2025-06-07", "02:00")))
+	assert.False(t, hours.OpenAt(at("2025-06-07", "12:00")))
+
+	// Special hours replace the weekly ones on their dates
+	assert.False(t, hours.OpenAt(at("2025-06-09", "12:00")))
+	assert.True(t, hours.OpenAt(at("2025-06-10", "23:00")))
+	assert.Equal(t, DayHours{Date: "2025-06-09", IsClosed: true, Special: true, Note: "Holiday"}, hours.On(at("2025-06-09", "00:00")))
+	assert.Equal(t, DayHours{Date: "2025-06-02", OpenTime: "11:00", CloseTime: "21:00"}, hours.On(at("2025-06-02", "00:00")))
+
+	// In the restaurant's timezone: 05:30 UTC is 11:15 in Kathmandu
+	assert.True(t, hours.OpenAt(time.Date(2025, 6, 2, 5, 30, 0, 0, time.UTC)))
+
+	assert.False(t, hours.OpenLate(at("2025-06-02", "12:00")))
+	assert.True(t, hours.OpenLate(at("2025-06-06", "12:00")))
+	assert.True(t, hours.OpenLate(at("2025-06-10", "12:00")))
+
+	var days []int
+	for _, oh := range hours.Weekly() {
+		days = append(days, oh.Weekday)
+	}
+	assert.Equal(t, []int{1, 5, 6}, days)
+}
+
+func TestOpenMatches(t *testing.T) {
+	hours := NewOpeningHours(kathmandu, []db.OpeningHour{
+		{Weekday: int(time.Monday), OpenTime: "11:00", CloseTime: "21:00"},
+		{Weekday: int(time.Friday), OpenTime: "18:00", CloseTime: "02:00"},
+	}, nil)
+	monday := at("2025-06-02", "12:00")
+
+	assert.True(t, openMatches(hours, SearchFilters{OpenNow: true}, monday))
+	assert.False(t, openMatches(hours, SearchFilters{OpenNow: true}, at("2025-06-02", "22:00")))
+
+	// Local times are taken in the restaurant's timezone, instants as given
+	assert.True(t, openMatches(hours, SearchFilters{OpenAt: "2025-06-06T23:00"}, monday))
+	assert.False(t, openMatches(hours, SearchFilters{OpenAt: "2025-06-06T23:00Z"}, monday))
+	assert.False(t, openMatches(hours, SearchFilters{OpenAt: "tonight"}, monday))
+
+	// Late on the day searched
+	assert.False(t, openMatches(hours, SearchFilters{OpenLate: true}, monday))
+	assert.True(t, openMatches(hours, SearchFilters{OpenLate: true, OpenAt: "2025-06-06T19:00"}, monday))
+	assert.True(t, openMatches(hours, SearchFilters{OpenLate: true, Date: "2025-06-06"}, monday))
+}
@@ -3,11 +3,17 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 	"strings"
 	"time"
 
+	"github.com/example/restosaas/apps/api/internal/budget"
 	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/geo"
+	"github.com/example/restosaas/apps/api/internal/i18n"
+	"github.com/example/restosaas/apps/api/internal/money"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SearchService struct {
@@ -17,14 +23,65 @@ type SearchService struct {
 type SearchFilters struct {
 	Area    string `json:"area"`
 	Cuisine string `json:"cuisine"`
-	Budget  string `json:"budget"`
-	People  string `json:"people"`
-	Date    string `json:"date"`
-	Time    string `json:"time"`
-	SortBy  string `json:"sort_by"`  // rating, name, created_at
-	SortDir string `json:"sort_dir"` // asc, desc
-	Page    int    `json:"page"`
-	Limit   int    `json:"limit"`
+	Budget  string `json:"budget"` // Price level ("$$") or amount or range per person ("500-1500")
+
+	// Multi-select filters, matching restaurants with any of the values:
+	// areas (area or place), places and cuisines, matched like Area and
+	// Cuisine, and rating bands (see Facets)
+	Areas       []string `json:"areas,omitempty"`
+	Places      []string `json:"places,omitempty"`
+	Cuisines    []string `json:"cuisines,omitempty"`
+	RatingBands []int    `json:"rating_bands,omitempty"`
+
+	// A table for People (1 when empty) on Date (YYYY-MM-DD, today when
+	// empty) at Time (HH:MM, any time when empty), give or take Window
+	// minutes (DefaultAvailabilityWindow when 0). With a date or time only
+	// restaurants with a free slot are found, along with the slots.
+	People string `json:"people"`
+	Date   string `json:"date"`
+	Time   string `json:"time"`
+	Window int    `json:"window,omitempty"`
+
+	// Restaurants open now, at OpenAt (see ParseOpenAt), still open at
+	// LateHour on the day searched, or on a weekday (0 = Sunday) by their
+	// weekly hours. Hours are those of each restaurant's timezone, with
+	// special hours replacing them on their dates.
+	OpenNow  bool   `json:"open_now,omitempty"`
+	OpenAt   string `json:"open_at,omitempty"`
+	OpenLate bool   `json:"open_late,omitempty"`
+	OpenOn   *int   `json:"open_on,omitempty"`
+
+	// Diet tags (dietary.Tags) that at least one menu item or course of the
+	// restaurant must all have, e.g. VEGAN
+	Diet    []string `json:"diet"`
+	SortBy  string   `json:"sort_by"`  // relevance (text searches), rating, name, created_at, capacity, price, distance (with Near)
+	SortDir string   `json:"sort_dir"` // asc, desc; nearest first by default for distance
+	Page    int      `json:"page"`
+	Limit   int      `json:"limit"`
+
+	// Locales to show restaurant text in, most preferred first (i18n.Chain)
+	Locales []string `json:"locales,omitempty"`
+
+	// Text to search for in websearch syntax, e.g. momo -fried; set by
+	// AdvancedSearch
+	Query string `json:"query,omitempty"`
+
+	// Budget per person in minor units of Currency (money.Default when
+	// empty): restaurants whose budget overlaps PriceMin to PriceMax.
+	// Restaurants pricing in other currencies are left out.
+	PriceMin *int64 `json:"price_min,omitempty"`
+	PriceMax *int64 `json:"price_max,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	// Price levels from 1 ($) to 4 ($$$$), any of them
+	PriceLevels []int `json:"price_levels,omitempty"`
+
+	// Restaurants within RadiusKm of Near, or all of them with their
+	// distance when RadiusKm is 0. Restaurants not yet on the map are left
+	// out of radius and Bounds searches.
+	Near     *geo.Point `json:"near,omitempty"`
+	RadiusKm float64    `json:"radius_km,omitempty"`
+	// Restaurants in a box, for map views
+	Bounds *geo.BoundingBox `json:"bounds,omitempty"`
 }
 
 type SearchResult struct {
@@ -34,6 +91,7 @@ type SearchResult struct {
 	Limit       int                    `json:"limit"`
 	TotalPages  int                    `json:"total_pages"`
 	Filters     SearchFilters          `json:"filters"`
+	Facets      *Facets                `json:"facets"`
 }
 
 type RestaurantWithRating struct {
@@ -44,6 +102,9 @@ type RestaurantWithRating struct {
 	Place       string           `json:"place"`
 	Genre       string           `json:"genre"`
 	Budget      string           `json:"budget"`
+	BudgetMin   *money.Money     `json:"budget_min,omitempty"` // Per person, when known
+	BudgetMax   *money.Money     `json:"budget_max,omitempty"`
+	PriceLevel  int              `json:"price_level"` // 1 ($) to 4 ($$$$), 0 when unknown
 	Title       string           `json:"title"`
 	Description string           `json:"description"`
 	Area        string           `json:"area"`
@@ -54,11 +115,37 @@ type RestaurantWithRating struct {
 	IsOpen      bool             `json:"is_open"`
 	MainImageID *string          `json:"main_image_id"`
 	Images      []db.Image       `json:"images"`
-	OpenHours   []db.OpeningHour `json:"open_hours"`
+	OpenHours   []db.OpeningHour `json:"open_hours"` // Weekly, Sunday first
 	AvgRating   float64          `json:"avg_rating"`
 	ReviewCount int              `json:"review_count"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
+	Latitude    *float64         `json:"latitude,omitempty"` // When on the map
+	Longitude   *float64         `json:"longitude,omitempty"`
+
+	// Hours today where the restaurant is, special hours included, and
+	// whether it is open now
+	TodayHours *DayHours `json:"today_hours,omitempty"`
+	OpenNow    bool      `json:"open_now"`
+
+	// Searches for a table only: the slots the party can be seated at
+	AvailableSlots []AvailableSlot `json:"available_slots,omitempty"`
+
+	// Searches near a point only: how far away the restaurant is, when it
+	// is on the map
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+
+	// Text searches only: how well the restaurant matched and where
+	Rank      float64          `json:"rank,omitempty"`
+	Highlight *SearchHighlight `json:"highlight,omitempty"`
+}
+
+// SearchHighlight shows where a restaurant matched a text search, as HTML
+// with the matching words wrapped in <mark> tags
+type SearchHighlight struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`          // Excerpt around the matches
+	MenuItems   []string `json:"menu_items,omitempty"` // Matching menu items and courses
 }
 
 // In-memory cache for search results (in production, use Redis)
@@ -86,41 +173,52 @@ func (s *SearchService) SearchRestaurants(filters SearchFilters) (*SearchResult,
 		filters.SortBy = "rating"
 	}
 	if filters.SortDir == "" {
-		filters.SortDir = "desc"
+		filters.SortDir = defaultSortDir(filters.SortBy)
 	}
 
 	// Create cache key
 	cacheKey := s.generateCacheKey(filters)
 
-	// Check cache first
-	if cached, exists := s.getFromCache(cacheKey); exists {
+	// Check cache first; free tables change with every reservation and
+	// open restaurants with the time, so such searches are not cached
+	cacheable := !filters.wantsTable() && !filters.wantsHours()
+	if cached, exists := s.getFromCache(cacheKey); exists && cacheable {
 		return &cached, nil
 	}
 
+	// Narrow down to free tables for a date or time and to restaurants
+	// open at a time
+	narrowed, slots, err := s.narrowSearch(filters)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build query
 	query := s.buildSearchQuery(filters)
 
-	// Get total count
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("failed to count restaurants: %w", err)
+	// Get restaurants with pagination and total count
+	restaurants, total, err := s.findRestaurants(query, narrowed, filters)
+	if err != nil {
+		return nil, err
+	}
+	facets, err := s.countFacets(filters, narrowed)
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate pagination
-	offset := (filters.Page - 1) * filters.Limit
 	totalPages := int((total + int64(filters.Limit) - 1) / int64(filters.Limit))
 
-	// Get restaurants with pagination
-	var restaurants []db.Restaurant
-	if err := query.Offset(offset).Limit(filters.Limit).Find(&restaurants).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch restaurants: %w", err)
-	}
-
 	// Convert to response format with ratings
-	restaurantsWithRatings, err := s.addRatingsToRestaurants(restaurants)
+	restaurantsWithRatings, err := s.addRatingsToRestaurants(restaurants, filters.Locales)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add ratings: %w", err)
 	}
+	addDistances(restaurantsWithRatings, filters.Near)
+	addSlots(restaurantsWithRatings, slots)
+	if err := s.addOpeningHours(restaurantsWithRatings, restaurants, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to add opening hours: %w", err)
+	}
 
 	// Create result
 	result := &SearchResult{
@@ -130,67 +228,155 @@ func (s *SearchService) SearchRestaurants(filters SearchFilters) (*SearchResult,
 		Limit:       filters.Limit,
 		TotalPages:  totalPages,
 		Filters:     filters,
+		Facets:      facets,
 	}
 
 	// Cache the result
-	s.setCache(cacheKey, *result)
+	if cacheable {
+		s.setCache(cacheKey, *result)
+	}
 
 	return result, nil
 }
 
 func (s *SearchService) buildSearchQuery(filters SearchFilters) *gorm.DB {
-	query := s.DB.Model(&db.Restaurant{})
+	query := s.applyFilters(s.DB.Model(&db.Restaurant{}), filters)
+
+	// Preload related data
+	query = query.Preload("Images")
+	// Opening hours, with special hours, are added by addOpeningHours
+
+	return s.applySort(query, filters)
+}
+
+// applyFilters applies the filters of a search that are evaluated in SQL,
+// leaving out those of the facets named in except so that each facet can be
+// counted against the other filters
+func (s *SearchService) applyFilters(query *gorm.DB, filters SearchFilters, except ...string) *gorm.DB {
+	skip := make(map[string]bool, len(except))
+	for _, facet := range except {
+		skip[facet] = true
+	}
 
 	// Only show open restaurants
-	query = query.Where("is_open = ?", true)
+	query = query.Where("restaurants.is_open = ?", true)
+
+	// Full-text search of names, descriptions, menu items and courses
+	if filters.Query != "" {
+		query = query.Where("restaurants.search_vector @@ "+searchQuery, filters.Query)
+	}
 
 	// Area filter (search in both area and place fields)
-	if filters.Area != "" {
-		query = query.Where("(area LIKE ? OR place LIKE ?)",
-			"%"+filters.Area+"%", "%"+filters.Area+"%")
+	if !skip[FacetArea] {
+		query = matchAny(query, filterValues(filters.Area, filters.Areas), "restaurants.area", "restaurants.place")
+	}
+	if !skip[FacetPlace] {
+		query = matchAny(query, filters.Places, "restaurants.place")
 	}
 
 	// Cuisine filter
-	if filters.Cuisine != "" {
-		query = query.Where("genre LIKE ?", "%"+filters.Cuisine+"%")
+	if !skip[FacetCuisine] {
+		query = matchAny(query, filterValues(filters.Cuisine, filters.Cuisines), "restaurants.genre")
 	}
 
 	// Budget filter
-	if filters.Budget != "" && filters.Budget != "all" {
-		query = query.Where("budget = ?", filters.Budget)
+	query = s.applyBudgetFilter(query, filters, !skip[FacetPriceLevel])
+
+	// Rating filter
+	if !skip[FacetRating] && len(filters.RatingBands) > 0 {
+		query = query.Where(s.getRatingBand()+" IN ?", filters.RatingBands)
 	}
 
-	// Capacity filter (number of people)
-	if filters.People != "" {
-		query = query.Where("capacity >= ?", filters.People)
+	// Capacity and opening day filter (number of people, date); free
+	// tables are checked by narrowSearch
+	query = s.applyPartyFilter(query, filters)
+
+	// Opening weekday filter; open now, at a time and late are checked by
+	// narrowSearch
+	query = s.applyHoursFilter(query, filters)
+
+	// Dietary filter (restaurants with e.g. vegan options)
+	if !skip[FacetDiet] {
+		query = s.applyDietFilter(query, filters.Diet)
 	}
 
-	// Date and time filtering (for future reservation availability)
-	if filters.Date != "" {
-		// This could be enhanced to check actual availability
-		// For now, we'll just ensure the restaurant is open
-		query = query.Where("is_open = ?", true)
+	// Distance and map filters
+	return s.applyLocationFilter(query, filters)
+}
+
+// filterValues lists the values of a filter given once and as a list
+func filterValues(value string, values []string) []string {
+	var all []string
+	for _, v := range append([]string{value}, values...) {
+		if v = strings.TrimSpace(v); v != "" {
+			all = append(all, v)
+		}
 	}
+	return all
+}
 
-	// Preload related data
-	query = query.Preload("Images")
-	// Note: OpenHours preload removed for SQLite compatibility in tests
+// matchAny keeps restaurants where one of the columns contains one of the
+// values
+func matchAny(query *gorm.DB, values []string, columns ...string) *gorm.DB {
+	if len(values) == 0 {
+		return query
+	}
+	var conditions []string
+	var args []interface{}
+	for _, v := range values {
+		for _, column := range columns {
+			conditions = append(conditions, column+" LIKE ?")
+			args = append(args, "%"+v+"%")
+		}
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
 
-	// Apply sorting
+func (s *SearchService) applySort(query *gorm.DB, filters SearchFilters) *gorm.DB {
 	sortField := s.getSortField(filters.SortBy)
 	sortDirection := "DESC"
 	if filters.SortDir == "asc" {
 		sortDirection = "ASC"
 	}
 
-	// For rating-based sorting, we need a subquery
-	if filters.SortBy == "rating" {
-		query = query.Order(fmt.Sprintf("(%s) %s", s.getRatingSubquery(), sortDirection))
-	} else {
-		query = query.Order(fmt.Sprintf("%s %s", sortField, sortDirection))
+	switch filters.SortBy {
+	case "rating":
+		// For rating-based sorting, we need a subquery
+		return query.Order(fmt.Sprintf("(%s) %s", s.getRatingSubquery(), sortDirection))
+	case "relevance":
+		if filters.Query == "" {
+			return query.Order(fmt.Sprintf("(%s) %s", s.getRatingSubquery(), sortDirection))
+		}
+		return query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank_cd(restaurants.search_vector, " + searchQuery + ") " + sortDirection,
+			Vars:               []interface{}{filters.Query},
+			WithoutParentheses: true,
+		}})
+	case "distance":
+		if filters.Near == nil {
+			return query.Order(fmt.Sprintf("(%s) %s", s.getRatingSubquery(), sortDirection))
+		}
+		// Restaurants not on the map last
+		return query.Order("restaurants.latitude IS NULL").Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                distanceKm + " " + sortDirection,
+			Vars:               []interface{}{filters.Near.Lat, filters.Near.Lat, filters.Near.Lng},
+			WithoutParentheses: true,
+		}})
+	case "price":
+		// By price level, then budget within a level; unknown budgets last
+		return query.Order("price_level = 0").
+			Order(fmt.Sprintf("price_level %s, budget_min_amount %s, budget_max_amount %s", sortDirection, sortDirection, sortDirection))
+	default:
+		return query.Order(fmt.Sprintf("%s %s", sortField, sortDirection))
 	}
+}
 
-	return query
+// defaultSortDir is nearest first for distance, and highest first otherwise
+func defaultSortDir(sortBy string) string {
+	if sortBy == "distance" {
+		return "asc"
+	}
+	return "desc"
 }
 
 func (s *SearchService) getSortField(sortBy string) string {
@@ -206,6 +392,103 @@ func (s *SearchService) getSortField(sortBy string) string {
 	}
 }
 
+// applyBudgetFilter keeps restaurants whose budget per person matches the
+// budget text, price range and, with withLevels, price levels of the filters
+func (s *SearchService) applyBudgetFilter(query *gorm.DB, filters SearchFilters, withLevels bool) *gorm.DB {
+	currency := filters.Currency
+	if currency == "" {
+		currency = money.Default
+	}
+	low, high := filters.PriceMin, filters.PriceMax
+	levels := append([]int(nil), filters.PriceLevels...)
+
+	// Budget text was matched exactly before budgets were stored as
+	// amounts; it is now a price level or a range like the others
+	if text := filters.Budget; text != "" && text != "all" {
+		if level, ok := budget.ParseLevel(text); ok && strings.HasPrefix(text, "$") {
+			levels = append(levels, level)
+		} else if r, err := budget.Parse(text, currency); err == nil {
+			low, high = &r.Min.Amount, &r.Max.Amount
+		}
+	}
+
+	if len(levels) > 0 && withLevels {
+		query = query.Where("price_level IN ?", levels)
+	}
+	if low != nil || high != nil {
+		query = query.Where("price_level > 0 AND budget_max_currency = ?", currency)
+		if low != nil {
+			query = query.Where("budget_max_amount >= ?", *low)
+		}
+		if high != nil {
+			query = query.Where("budget_min_amount <= ?", *high)
+		}
+	}
+	return query
+}
+
+// applyDietFilter keeps restaurants offering at least one menu item or
+// course with all of the given (already validated) diet tags
+func (s *SearchService) applyDietFilter(query *gorm.DB, tags []string) *gorm.DB {
+	if len(tags) == 0 {
+		return query
+	}
+	list := db.StringList(tags)
+	return query.Where(`(
+		EXISTS (SELECT 1 FROM menus WHERE menus.restaurant_id = restaurants.id AND menus.dietary_tags @> ?::jsonb)
+		OR EXISTS (SELECT 1 FROM courses WHERE courses.restaurant_id = restaurants.id AND courses.dietary_tags @> ?::jsonb)
+	)`, list, list)
+}
+
+// applyLocationFilter keeps restaurants within the radius and the bounds of
+// the filters. A radius is first narrowed to the box around it, which the
+// location index can serve, then measured.
+func (s *SearchService) applyLocationFilter(query *gorm.DB, filters SearchFilters) *gorm.DB {
+	if filters.Bounds != nil {
+		query = inBoundingBox(query, *filters.Bounds)
+	}
+	if filters.Near != nil && filters.RadiusKm > 0 {
+		near := *filters.Near
+		query = inBoundingBox(query, geo.Around(near, filters.RadiusKm))
+		query = query.Where(distanceKm+" <= ?", near.Lat, near.Lat, near.Lng, filters.RadiusKm)
+	}
+	return query
+}
+
+func inBoundingBox(query *gorm.DB, b geo.BoundingBox) *gorm.DB {
+	query = query.Where("restaurants.latitude BETWEEN ? AND ?", b.South, b.North)
+	if b.CrossesAntimeridian() {
+		return query.Where("(restaurants.longitude >= ? OR restaurants.longitude <= ?)", b.West, b.East)
+	}
+	return query.Where("restaurants.longitude BETWEEN ? AND ?", b.West, b.East)
+}
+
+// distanceKm is the great-circle distance in km from a point, bound as its
+// latitude, latitude again and longitude, to a restaurant (see
+// geo.Distance)
+const distanceKm = `(2 * 6371 * asin(least(1, sqrt(
+	power(sin(radians(restaurants.latitude - ?) / 2), 2) +
+	cos(radians(?)) * cos(radians(restaurants.latitude)) * power(sin(radians(restaurants.longitude - ?) / 2), 2)))))`
+
+// searchQuery parses the text of a search. Restaurants' search_vector
+// (see db.RunMigrations) holds their name, cuisine, place, text in every
+// locale and the names of their menu items and courses, with the 'simple'
+// configuration so that no language's stemming is assumed.
+const searchQuery = "websearch_to_tsquery('simple', ?)"
+
+// Options of ts_headline for names, shown whole, and descriptions,
+// shortened to the words around the matches
+const (
+	nameHeadline        = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	descriptionHeadline = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=12, MaxFragments=2"
+)
+
+// escapeHTML is the SQL of html.EscapeString for a column. Text is escaped
+// before ts_headline, whose parser keeps the entities whole, so that only
+// its <mark> tags are markup.
+const escapeHTML = `replace(replace(replace(replace(replace(%s,
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`
+
 func (s *SearchService) getRatingSubquery() string {
 	return `(
 		SELECT COALESCE(AVG(rating), 0) 
@@ -214,10 +497,20 @@ func (s *SearchService) getRatingSubquery() string {
 	)`
 }
 
-func (s *SearchService) addRatingsToRestaurants(restaurants []db.Restaurant) ([]RestaurantWithRating, error) {
+// getRatingBand is the whole stars of a restaurant's average rating, from
+// 1 to 4 with 4 for 4.0 and up, or 0 when it is not rated
+func (s *SearchService) getRatingBand() string {
+	return fmt.Sprintf("LEAST(FLOOR(%s), 4)::int", s.getRatingSubquery())
+}
+
+func (s *SearchService) addRatingsToRestaurants(restaurants []db.Restaurant, locales []string) ([]RestaurantWithRating, error) {
 	var result []RestaurantWithRating
 
 	for _, restaurant := range restaurants {
+		if len(locales) > 0 {
+			i18n.LocalizeRestaurant(&restaurant, locales)
+		}
+
 		// Get reviews for this restaurant
 		var reviews []db.Review
 		if err := s.DB.Where("restaurant_id = ? AND is_approved = ?", restaurant.ID, true).Find(&reviews).Error; err != nil {
@@ -246,6 +539,9 @@ func (s *SearchService) addRatingsToRestaurants(restaurants []db.Restaurant) ([]
 			Place:       restaurant.Place,
 			Genre:       restaurant.Genre,
 			Budget:      restaurant.Budget,
+			BudgetMin:   knownBudget(restaurant, restaurant.BudgetMin),
+			BudgetMax:   knownBudget(restaurant, restaurant.BudgetMax),
+			PriceLevel:  restaurant.PriceLevel,
 			Title:       restaurant.Title,
 			Description: restaurant.Description,
 			Address:     restaurant.Address,
@@ -261,11 +557,13 @@ func (s *SearchService) addRatingsToRestaurants(restaurants []db.Restaurant) ([]
 				return nil
 			}(),
 			Images:      []db.Image{},       // Will be populated separately if needed
-			OpenHours:   []db.OpeningHour{}, // Will be populated separately if needed
+			OpenHours:   []db.OpeningHour{}, // Set by addOpeningHours
 			AvgRating:   avgRating,
 			ReviewCount: reviewCount,
 			CreatedAt:   restaurant.CreatedAt,
 			UpdatedAt:   restaurant.UpdatedAt,
+			Latitude:    restaurant.Latitude,
+			Longitude:   restaurant.Longitude,
 		}
 
 		result = append(result, restaurantWithRating)
@@ -274,21 +572,61 @@ func (s *SearchService) addRatingsToRestaurants(restaurants []db.Restaurant) ([]
 	return result, nil
 }
 
-func (s *SearchService) generateCacheKey(filters SearchFilters) string {
-	// Create a unique key based on all filter parameters
-	keyData := map[string]interface{}{
-		"area":     filters.Area,
-		"cuisine":  filters.Cuisine,
-		"budget":   filters.Budget,
-		"people":   filters.People,
-		"date":     filters.Date,
-		"time":     filters.Time,
-		"sort_by":  filters.SortBy,
-		"sort_dir": filters.SortDir,
-		"page":     filters.Page,
-		"limit":    filters.Limit,
+// addDistances sets how far restaurants on the map are from near
+func addDistances(restaurants []RestaurantWithRating, near *geo.Point) {
+	if near == nil {
+		return
 	}
+	for i := range restaurants {
+		r := &restaurants[i]
+		if r.Latitude == nil || r.Longitude == nil {
+			continue
+		}
+		distance := geo.Distance(*near, geo.Point{Lat: *r.Latitude, Lng: *r.Longitude})
+		r.DistanceKm = &distance
+	}
+}
 
+// addSlots sets the free slots of restaurants found by a search for a table
+func addSlots(restaurants []RestaurantWithRating, slots map[string][]AvailableSlot) {
+	for i := range restaurants {
+		restaurants[i].AvailableSlots = slots[restaurants[i].ID]
+	}
+}
+
+// addOpeningHours sets the weekly hours of restaurants, their hours today
+// and whether they are open at now, reading hours for all of them at once
+func (s *SearchService) addOpeningHours(results []RestaurantWithRating, restaurants []db.Restaurant, now time.Time) error {
+	if len(restaurants) == 0 {
+		return nil
+	}
+	from, to := hoursSpan(now)
+	hours, err := LoadOpeningHours(s.DB, restaurants, from, to)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		h := hours[restaurants[i].ID]
+		today := h.On(now.In(h.loc))
+		results[i].OpenHours = append([]db.OpeningHour{}, h.Weekly()...)
+		results[i].TodayHours = &today
+		results[i].OpenNow = h.OpenAt(now)
+	}
+	return nil
+}
+
+// knownBudget returns an end of the restaurant's budget, nil when the
+// budget is unknown
+func knownBudget(restaurant db.Restaurant, end money.Money) *money.Money {
+	if restaurant.BudgetMax.IsZero() {
+		return nil
+	}
+	return &end
+}
+
+func (s *SearchService) generateCacheKey(filters SearchFilters) string {
+	// Create a unique key based on all filter parameters
+	keyData := filters
 	keyBytes, _ := json.Marshal(keyData)
 	return fmt.Sprintf("search:%x", keyBytes)
 }
@@ -334,62 +672,53 @@ func (s *SearchService) AdvancedSearch(query string, filters SearchFilters) (*Se
 	if filters.Limit <= 0 {
 		filters.Limit = 20
 	}
-
-	// Build advanced query
-	dbQuery := s.DB.Model(&db.Restaurant{})
-
-	// Text search across multiple fields
-	if query != "" {
-		searchTerm := "%" + strings.ToLower(query) + "%"
-		dbQuery = dbQuery.Where(
-			"(LOWER(name) LIKE ? OR LOWER(slogan) LIKE ? OR LOWER(description) LIKE ? OR LOWER(genre) LIKE ? OR LOWER(place) LIKE ? OR LOWER(area) LIKE ?)",
-			searchTerm, searchTerm, searchTerm, searchTerm, searchTerm, searchTerm,
-		)
-	}
-
-	// Apply other filters
-	if filters.Area != "" {
-		dbQuery = dbQuery.Where("(area LIKE ? OR place LIKE ?)",
-			"%"+filters.Area+"%", "%"+filters.Area+"%")
-	}
-	if filters.Cuisine != "" {
-		dbQuery = dbQuery.Where("genre LIKE ?", "%"+filters.Cuisine+"%")
+	if filters.Limit > 100 {
+		filters.Limit = 100
 	}
-	if filters.Budget != "" && filters.Budget != "all" {
-		dbQuery = dbQuery.Where("budget = ?", filters.Budget)
+	filters.Query = strings.TrimSpace(query)
+	if filters.SortBy == "" {
+		filters.SortBy = "rating"
+		if filters.Query != "" {
+			filters.SortBy = "relevance"
+		}
 	}
-	if filters.People != "" {
-		dbQuery = dbQuery.Where("capacity >= ?", filters.People)
+	if filters.SortDir == "" {
+		filters.SortDir = defaultSortDir(filters.SortBy)
 	}
 
-	// Only open restaurants
-	dbQuery = dbQuery.Where("is_open = ?", true)
-
-	// Preload related data
-	dbQuery = dbQuery.Preload("Images")
-	// Note: OpenHours preload removed for SQLite compatibility in tests
-
-	// Get total count
-	var total int64
-	if err := dbQuery.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("failed to count restaurants: %w", err)
+	// Narrow down to free tables for a date or time and to restaurants
+	// open at a time
+	narrowed, slots, err := s.narrowSearch(filters)
+	if err != nil {
+		return nil, err
 	}
 
-	// Apply pagination
-	offset := (filters.Page - 1) * filters.Limit
-	totalPages := int((total + int64(filters.Limit) - 1) / int64(filters.Limit))
-
-	// Get restaurants
-	var restaurants []db.Restaurant
-	if err := dbQuery.Offset(offset).Limit(filters.Limit).Find(&restaurants).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch restaurants: %w", err)
+	// Get restaurants with pagination and total count
+	restaurants, total, err := s.findRestaurants(s.buildSearchQuery(filters), narrowed, filters)
+	if err != nil {
+		return nil, err
+	}
+	facets, err := s.countFacets(filters, narrowed)
+	if err != nil {
+		return nil, err
 	}
+	totalPages := int((total + int64(filters.Limit) - 1) / int64(filters.Limit))
 
 	// Add ratings
-	restaurantsWithRatings, err := s.addRatingsToRestaurants(restaurants)
+	restaurantsWithRatings, err := s.addRatingsToRestaurants(restaurants, filters.Locales)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add ratings: %w", err)
 	}
+	addDistances(restaurantsWithRatings, filters.Near)
+	addSlots(restaurantsWithRatings, slots)
+	if err := s.addOpeningHours(restaurantsWithRatings, restaurants, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to add opening hours: %w", err)
+	}
+	if filters.Query != "" {
+		if err := s.addHighlights(restaurantsWithRatings, filters.Query); err != nil {
+			return nil, fmt.Errorf("failed to add highlights: %w", err)
+		}
+	}
 
 	return &SearchResult{
 		Restaurants: restaurantsWithRatings,
@@ -398,5 +727,50 @@ func (s *SearchService) AdvancedSearch(query string, filters SearchFilters) (*Se
 		Limit:       filters.Limit,
 		TotalPages:  totalPages,
 		Filters:     filters,
+		Facets:      facets,
 	}, nil
 }
+
+// addHighlights ranks restaurants found by a text search and marks where
+// they matched. Names and descriptions are highlighted as shown, in the
+// locale of the search, and HTML-escaped but for the <mark> tags.
+func (s *SearchService) addHighlights(restaurants []RestaurantWithRating, query string) error {
+	for i := range restaurants {
+		r := &restaurants[i]
+
+		var row struct {
+			Rank        float64
+			Name        string
+			Description string
+		}
+		err := s.DB.Raw(`SELECT
+				ts_rank_cd(restaurants.search_vector, q) AS rank,
+				ts_headline('simple', ?, q, ?) AS name,
+				ts_headline('simple', ?, q, ?) AS description
+			FROM restaurants, `+searchQuery+` AS q
+			WHERE restaurants.id = ?`,
+			html.EscapeString(r.Name), nameHeadline, html.EscapeString(r.Description), descriptionHeadline, query, r.ID,
+		).Scan(&row).Error
+		if err != nil {
+			return err
+		}
+
+		var items []string
+		err = s.DB.Raw(`SELECT ts_headline('simple', `+fmt.Sprintf(escapeHTML, "name")+`, q, ?) FROM (
+				SELECT name FROM menus WHERE restaurant_id = ?
+				UNION ALL
+				SELECT title FROM courses WHERE restaurant_id = ?
+			) AS items, `+searchQuery+` AS q
+			WHERE to_tsvector('simple', name) @@ q
+			LIMIT 5`,
+			nameHeadline, r.ID, r.ID, query,
+		).Scan(&items).Error
+		if err != nil {
+			return err
+		}
+
+		r.Rank = row.Rank
+		r.Highlight = &SearchHighlight{Name: row.Name, Description: row.Description, MenuItems: items}
+	}
+	return nil
+}
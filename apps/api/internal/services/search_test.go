@@ -0,0 +1,150 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/example/restosaas/apps/api/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupSearchDB connects to TEST_DATABASE_URL with every migration run, so
+// that search vectors and trigram indexes exist
+func setupSearchDB(t *testing.T) *gorm.DB {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("Skipping integration test - no TEST_DATABASE_URL configured")
+	}
+
+	gdb, err := gorm.Open(postgres.Open(dbURL), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.RunMigrations(gdb); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return gdb
+}
+
+// searchWord is a word found only in the restaurants of one test, so that
+// searches are not thrown off by other data in the database
+func searchWord() string {
+	return "zq" + strings.ReplaceAll(uuid.NewString()[:8], "-", "")
+}
+
+// searchFixture creates restaurants of one organization for a test and
+// removes them afterwards
+type searchFixture struct {
+	t   *testing.T
+	db  *gorm.DB
+	org db.Organization
+}
+
+func newSearchFixture(t *testing.T, gdb *gorm.DB) *searchFixture {
+	f := &searchFixture{t: t, db: gdb, org: db.Organization{ID: uuid.New(), Name: "search-" + uuid.NewString()[:8], SubscriptionStatus: "ACTIVE", CreatedAt: time.Now()}}
+	require.NoError(t, gdb.Create(&f.org).Error)
+	t.Cleanup(func() {
+		restaurants := gdb.Model(&db.Restaurant{}).Select("id").Where("org_id = ?", f.org.ID)
+		gdb.Where("restaurant_id IN (?)", restaurants).Delete(&db.Review{})
+		gdb.Where("restaurant_id IN (?)", restaurants).Delete(&db.OpeningHour{})
+		gdb.Where("restaurant_id IN (?)", restaurants).Delete(&db.Course{})
+		gdb.Where("restaurant_id IN (?)", restaurants).Delete(&db.Menu{})
+		gdb.Where("org_id = ?", f.org.ID).Delete(&db.Restaurant{})
+		gdb.Delete(&f.org)
+	})
+	return f
+}
+
+// restaurant creates an open restaurant from r, filling in what is not set,
+// with a menu item of each name
+func (f *searchFixture) restaurant(r db.Restaurant, items ...string) db.Restaurant {
+	now := time.Now()
+	r.ID = uuid.New()
+	r.OrgID = f.org.ID
+	r.Slug = "search-" + r.ID.String()
+	r.IsOpen = true
+	r.CreatedAt, r.UpdatedAt = now, now
+	for field, value := range map[*string]string{&r.Slogan: "s", &r.Place: "Kathmandu", &r.Genre: "Nepali", &r.Budget: "500-1500", &r.Title: "t"} {
+		if *field == "" {
+			*field = value
+		}
+	}
+	require.NoError(f.t, f.db.Create(&r).Error)
+
+	for _, name := range items {
+		f.dish(r, name)
+	}
+	return r
+}
+
+// dish adds a menu item with the diet tags to the restaurant
+func (f *searchFixture) dish(r db.Restaurant, name string, tags ...string) {
+	now := time.Now()
+	menu := db.Menu{ID: uuid.New(), RestaurantID: r.ID, Name: name, Price: money.FromMajor(300, money.Default), Type: db.MenuTypeFood, MealType: db.MealTypeBoth, DietaryTags: db.StringList(tags), CreatedAt: now, UpdatedAt: now}
+	require.NoError(f.t, f.db.Create(&menu).Error)
+}
+
+// hours opens the restaurant on a weekday (0 = Sunday)
+func (f *searchFixture) hours(r db.Restaurant, weekday int, open, close string) {
+	hour := db.OpeningHour{ID: uuid.New(), RestaurantID: r.ID, Weekday: weekday, OpenTime: open, CloseTime: close}
+	require.NoError(f.t, f.db.Create(&hour).Error)
+}
+
+// review adds a review of the restaurant
+func (f *searchFixture) review(r db.Restaurant, rating int, approved bool) {
+	now := time.Now()
+	review := db.Review{ID: uuid.New(), RestaurantID: r.ID, Rating: rating, IsApproved: approved, CreatedAt: now, UpdatedAt: now}
+	require.NoError(f.t, f.db.Create(&review).Error)
+}
+
+func resultIDs(result *SearchResult) []string {
+	ids := make([]string, len(result.Restaurants))
+	for i, r := range result.Restaurants {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestAdvancedSearch_Integration(t *testing.T) {
+	gdb := setupSearchDB(t)
+	f := newSearchFixture(t, gdb)
+	word := searchWord()
+
+	// Matches by name, weighed above menu items
+	byName := f.restaurant(db.Restaurant{Name: word + " <b>Momo</b>", Description: "Steamed " + word + " dumplings"})
+	// Matches by a menu item only
+	byItem := f.restaurant(db.Restaurant{Name: "Thakali Kitchen"}, word+" special", "Dal bhat")
+	// Left out by -fried
+	fried := f.restaurant(db.Restaurant{Name: word + " fried"})
+
+	service := NewSearchService(gdb)
+	result, err := service.AdvancedSearch(word+" -fried", SearchFilters{Limit: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, 100, result.Limit)
+	assert.Equal(t, []string{byName.ID.String(), byItem.ID.String()}, resultIDs(result))
+	assert.Greater(t, result.Restaurants[0].Rank, result.Restaurants[1].Rank)
+
+	// Owners' text is escaped, only the matches are marked
+	name := result.Restaurants[0].Highlight.Name
+	assert.Contains(t, name, "<mark>"+word+"</mark>")
+	assert.Contains(t, name, "&lt;b&gt;Momo&lt;/b&gt;")
+	assert.NotContains(t, name, "<b>")
+	assert.Contains(t, result.Restaurants[0].Highlight.Description, "<mark>"+word+"</mark>")
+	assert.Equal(t, []string{"<mark>" + word + "</mark> special"}, result.Restaurants[1].Highlight.MenuItems)
+
+	// Phrases and alternatives
+	result, err = service.AdvancedSearch(`"`+word+` special"`, SearchFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{byItem.ID.String()}, resultIDs(result))
+
+	result, err = service.AdvancedSearch(word+` fried or "`+word+` special"`, SearchFilters{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{byItem.ID.String(), fried.ID.String()}, resultIDs(result))
+}
@@ -1,10 +1,10 @@
 package services
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -14,36 +14,82 @@ type Slot struct {
 	Available int
 }
 
-// Generate 30-min slots within opening hours and subtract overlapping reservations.
+const (
+	slotLength = 30 * time.Minute
+	// seatingLength is how long a party arriving at a slot is assumed to
+	// stay, taking seats from reservations that overlap it
+	seatingLength = 90 * time.Minute
+)
+
+// Generate 30-min slots within opening hours, or the special hours of the
+// date, and subtract overlapping reservations.
 func GenerateSlots(gdb *gorm.DB, restaurantID string, date time.Time) ([]Slot, error) {
 	var r db.Restaurant
 	if err := gdb.First(&r, "id = ?", restaurantID).Error; err != nil {
 		return nil, err
 	}
-	weekday := int(date.Weekday())
-	var oh db.OpeningHour
-	if err := gdb.Where("restaurant_id = ? AND weekday = ?", restaurantID, weekday).First(&oh).Error; err != nil {
+	day := date.Format(dateLayout)
+	hours, err := LoadOpeningHours(gdb, []db.Restaurant{r}, day, day)
+	if err != nil {
+		return nil, err
+	}
+	from, to := dayBounds(date)
+	reservations, err := activeReservations(gdb, []uuid.UUID{r.ID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	slots := daySlots(r, hours[r.ID].On(date), reservations, date)
+	if slots == nil {
 		return []Slot{}, nil // closed
 	}
-	parse := func(hm string) (int, int) { var H, M int; fmt.Sscanf(hm, "%d:%d", &H, &M); return H, M }
-	H1, M1 := parse(oh.OpenTime)
-	H2, M2 := parse(oh.CloseTime)
-	loc, _ := time.LoadLocation(r.Timezone)
-	start := time.Date(date.Year(), date.Month(), date.Day(), H1, M1, 0, 0, loc)
-	end := time.Date(date.Year(), date.Month(), date.Day(), H2, M2, 0, 0, loc)
+	return slots, nil
+}
+
+// daySlots lays out the slots of a restaurant's hours on a date, with the
+// seats its reservations leave free in each. Hours past midnight run into
+// the next day.
+func daySlots(r db.Restaurant, hours DayHours, reservations []db.Reservation, date time.Time) []Slot {
 	var out []Slot
-	for t := start; t.Add(30*time.Minute).Before(end) || t.Add(30*time.Minute).Equal(end); t = t.Add(30 * time.Minute) {
+	opens, closes, ok := hours.window()
+	if !ok {
+		return out
+	}
+	loc := RestaurantLocation(r)
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	start := midnight.Add(time.Duration(opens) * time.Minute)
+	end := midnight.Add(time.Duration(closes) * time.Minute)
+	for t := start; t.Add(slotLength).Before(end) || t.Add(slotLength).Equal(end); t = t.Add(slotLength) {
 		// sum overlapping reservations for 90-min duration default
-		var used int64
-		q := gdb.Model(&db.Reservation{}).
-			Where("restaurant_id = ? AND status IN ? AND starts_at < ? AND (starts_at + (duration_min || ' minutes')::interval) > ?",
-				restaurantID, []db.ReservationStatus{db.ResvPending, db.ResvConfirmed}, t.Add(90*time.Minute), t)
-		q.Select("COALESCE(SUM(party_size),0)").Scan(&used)
-		avail := int(r.Capacity) - int(used)
+		used := 0
+		for _, resv := range reservations {
+			resvEnd := resv.StartsAt.Add(time.Duration(resv.DurationMin) * time.Minute)
+			if resv.RestaurantID == r.ID && resv.StartsAt.Before(t.Add(seatingLength)) && resvEnd.After(t) {
+				used += resv.PartySize
+			}
+		}
+		avail := int(r.Capacity) - used
 		if avail < 0 {
 			avail = 0
 		}
-		out = append(out, Slot{Start: t, End: t.Add(30 * time.Minute), Available: avail})
+		out = append(out, Slot{Start: t, End: t.Add(slotLength), Available: avail})
 	}
-	return out, nil
+	return out
+}
+
+// activeReservations returns the pending and confirmed reservations of
+// restaurants that overlap from to to
+func activeReservations(gdb *gorm.DB, restaurantIDs []uuid.UUID, from, to time.Time) ([]db.Reservation, error) {
+	var reservations []db.Reservation
+	err := gdb.Select("restaurant_id", "starts_at", "duration_min", "party_size").
+		Where("restaurant_id IN ? AND status IN ? AND starts_at < ? AND (starts_at + (duration_min || ' minutes')::interval) > ?",
+			restaurantIDs, []db.ReservationStatus{db.ResvPending, db.ResvConfirmed}, to, from).
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// dayBounds is a span holding every slot of a date, and the reservations
+// overlapping them, in any timezone
+func dayBounds(date time.Time) (time.Time, time.Time) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return day.Add(-24 * time.Hour), day.Add(48 * time.Hour)
 }
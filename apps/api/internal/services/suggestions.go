@@ -0,0 +1,83 @@
+package services
+
+import (
+	"gorm.io/gorm"
+)
+
+// Suggestion is something to search for, offered while the query is typed
+type Suggestion struct {
+	Type       string  `json:"type"` // restaurant, cuisine, area or dish
+	Text       string  `json:"text"`
+	ID         string  `json:"id,omitempty"`   // Restaurants only
+	Slug       string  `json:"slug,omitempty"` // Restaurants only
+	Popularity int     `json:"popularity"`     // Approved reviews of a restaurant, or restaurants with the cuisine, area or dish
+	Score      float64 `json:"score"`
+}
+
+// suggestionThreshold is how close, from 0 to 1, a word of a name must be
+// to the query to be suggested. It is lower than the pg_trgm default of 0.6
+// so that short prefixes and typos still match.
+const suggestionThreshold = "0.4"
+
+// suggestionsQuery matches the query against the trigram indexes on names,
+// cuisines, areas, places and dishes of open restaurants. Each type is
+// limited on its own, then all are ranked by similarity with a boost for
+// popularity. Reviews are counted for the matching restaurants only.
+const suggestionsQuery = `SELECT type, text, id, slug, popularity,
+		similarity + 0.05 * ln(1 + popularity) AS score
+	FROM (
+		(SELECT 'restaurant' AS type, r.name AS text, r.id::text AS id, r.slug AS slug,
+				COUNT(reviews.id) AS popularity, r.similarity
+			FROM (
+				SELECT id, name, slug, word_similarity(@q, name) AS similarity
+				FROM restaurants
+				WHERE is_open = true AND @q <% name
+				ORDER BY similarity DESC
+				LIMIT @limit
+			) AS r
+			LEFT JOIN reviews ON reviews.restaurant_id = r.id AND reviews.is_approved = true
+			GROUP BY r.id, r.name, r.slug, r.similarity)
+		UNION ALL
+		(SELECT 'cuisine', MIN(genre), '', '', COUNT(*), MAX(word_similarity(@q, genre))
+			FROM restaurants
+			WHERE is_open = true AND @q <% genre
+			GROUP BY lower(genre)
+			ORDER BY 6 DESC
+			LIMIT @limit)
+		UNION ALL
+		(SELECT 'area', MIN(value), '', '', COUNT(*), MAX(word_similarity(@q, value))
+			FROM (
+				SELECT area AS value FROM restaurants WHERE is_open = true AND area <> '' AND @q <% area
+				UNION ALL
+				SELECT place FROM restaurants WHERE is_open = true AND @q <% place
+			) AS areas
+			GROUP BY lower(value)
+			ORDER BY 6 DESC
+			LIMIT @limit)
+		UNION ALL
+		(SELECT 'dish', MIN(m.name), '', '', COUNT(DISTINCT m.restaurant_id), MAX(word_similarity(@q, m.name))
+			FROM menus m
+			JOIN restaurants r ON r.id = m.restaurant_id
+			WHERE r.is_open = true AND @q <% m.name
+			GROUP BY lower(m.name)
+			ORDER BY 6 DESC
+			LIMIT @limit)
+	) AS suggestions
+	ORDER BY score DESC, text
+	LIMIT @limit`
+
+// Suggest returns up to limit restaurants, cuisines, areas and dishes whose
+// names are like the query, tolerating typos, best match first
+func (s *SearchService) Suggest(query string, limit int) ([]Suggestion, error) {
+	suggestions := []Suggestion{}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", suggestionThreshold).Error; err != nil {
+			return err
+		}
+		return tx.Raw(suggestionsQuery, map[string]interface{}{"q": query, "limit": limit}).Scan(&suggestions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/example/restosaas/apps/api/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// typo replaces the middle letter of a word
+func typo(word string) string {
+	middle := len(word) / 2
+	replacement := "x"
+	if word[middle] == 'x' {
+		replacement = "y"
+	}
+	return word[:middle] + replacement + word[middle+1:]
+}
+
+func TestSuggest_Integration(t *testing.T) {
+	gdb := setupSearchDB(t)
+	f := newSearchFixture(t, gdb)
+	word := searchWord()
+
+	house := f.restaurant(db.Restaurant{Name: word + " House", Genre: word + "ese", Area: word + " Tole"}, word+" Momo")
+	f.review(house, 5, true)
+	f.review(house, 4, true)
+	f.review(house, 1, false)
+	f.restaurant(db.Restaurant{Name: "Other Kitchen", Genre: word + "ESE", Place: word + " Tole"}, word+" momo")
+	closed := f.restaurant(db.Restaurant{Name: word + " Closed"})
+	require.NoError(t, gdb.Model(&closed).Update("is_open", false).Error)
+
+	suggestions, err := NewSearchService(gdb).Suggest(typo(word), 10)
+	require.NoError(t, err)
+
+	byType := map[string][]Suggestion{}
+	for _, s := range suggestions {
+		if strings.Contains(strings.ToLower(s.Text), word) {
+			byType[s.Type] = append(byType[s.Type], s)
+		}
+	}
+
+	// Closed restaurants are not suggested
+	require.Len(t, byType["restaurant"], 1)
+	assert.Equal(t, word+" House", byType["restaurant"][0].Text)
+	assert.Equal(t, house.ID.String(), byType["restaurant"][0].ID)
+	assert.Equal(t, house.Slug, byType["restaurant"][0].Slug)
+	assert.Equal(t, 2, byType["restaurant"][0].Popularity)
+
+	// Cuisines, areas and dishes once whatever their case, by restaurants
+	// having them
+	require.Len(t, byType["cuisine"], 1)
+	assert.Equal(t, 2, byType["cuisine"][0].Popularity)
+	require.Len(t, byType["area"], 1)
+	assert.Equal(t, word+" Tole", byType["area"][0].Text)
+	assert.Equal(t, 2, byType["area"][0].Popularity)
+	require.Len(t, byType["dish"], 1)
+	assert.Equal(t, 2, byType["dish"][0].Popularity)
+
+	for i := 1; i < len(suggestions); i++ {
+		assert.GreaterOrEqual(t, suggestions[i-1].Score, suggestions[i].Score)
+	}
+
+	none, err := NewSearchService(gdb).Suggest(searchWord(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+// TestSuggest_TrigramIndexes checks that every branch of the suggestions
+// query can be answered from a trigram index rather than by scanning
+func TestSuggest_TrigramIndexes(t *testing.T) {
+	gdb := setupSearchDB(t)
+
+	var plan []string
+	err := gdb.Transaction(func(tx *gorm.DB) error {
+		// Test tables are too small for the planner to prefer an index
+		if err := tx.Exec("SET LOCAL enable_seqscan = off").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", suggestionThreshold).Error; err != nil {
+			return err
+		}
+		return tx.Raw("EXPLAIN "+suggestionsQuery, map[string]interface{}{"q": "momo", "limit": 10}).Scan(&plan).Error
+	})
+	require.NoError(t, err)
+
+	explain := strings.Join(plan, "\n")
+	for _, index := range []string{
+		"idx_restaurants_name_trgm",
+		"idx_restaurants_genre_trgm",
+		"idx_restaurants_area_trgm",
+		"idx_restaurants_place_trgm",
+		"idx_menus_name_trgm",
+	} {
+		assert.Contains(t, explain, index)
+	}
+}
@@ -40,7 +40,9 @@ import type {
   MenuResponse,
   CourseResponse,
   RestaurantResponse,
+  Money,
 } from '@restosaas/types';
+import { formatMoney, majorAmount, minorAmount } from '@restosaas/types';
 
 type TabType = 'courses' | 'menus' | 'drinks' | 'lunch' | 'dinner';
 
@@ -188,8 +190,8 @@ export function OwnerMenus() {
       course.title.toLowerCase().includes(searchTerm.toLowerCase())
     ) || [];
 
-  const formatPrice = (price: number) => {
-    return `Rs ${price}`;
+  const formatPrice = (price: Money) => {
+    return formatMoney(price);
   };
 
   const formatTime = (minutes: number) => {
@@ -900,21 +902,26 @@ function CourseForm({
     title: initialData?.title || '',
     description: initialData?.description || '',
     imageUrl: initialData?.imageUrl || '',
-    coursePrice: initialData?.coursePrice || 0,
-    originalPrice: initialData?.originalPrice || '',
+    coursePrice: initialData ? majorAmount(initialData.coursePrice) : 0,
+    originalPrice: initialData?.originalPrice
+      ? majorAmount(initialData.originalPrice)
+      : '',
     numberOfItems: initialData?.numberOfItems || 1,
     stayTime: initialData?.stayTime || 60,
     courseContent: initialData?.courseContent || '',
     precautions: initialData?.precautions || '',
   });
 
+  // New prices are in the restaurant's currency, NPR unless edited
+  const currency = initialData?.coursePrice.currency;
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     const submitData = {
       ...formData,
-      coursePrice: Math.round(formData.coursePrice * 100), // Convert to cents and round
+      coursePrice: minorAmount(formData.coursePrice, currency),
       originalPrice: formData.originalPrice
-        ? Math.round(parseFloat(String(formData.originalPrice)) * 100)
+        ? minorAmount(parseFloat(String(formData.originalPrice)), currency)
         : undefined,
     };
     onSubmit(submitData);
@@ -1104,16 +1111,19 @@ function MenuForm({
     name: initialData?.name || '',
     shortDesc: initialData?.shortDesc || '',
     imageUrl: initialData?.imageUrl || '',
-    price: initialData?.price ? initialData.price / 100 : 0,
+    price: initialData ? majorAmount(initialData.price) : 0,
     type: initialData?.type || defaultType || 'FOOD',
     mealType: initialData?.mealType || defaultMealType || 'BOTH',
   });
 
+  // New prices are in the restaurant's currency, NPR unless edited
+  const currency = initialData?.price.currency;
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     const submitData = {
       ...formData,
-      price: Math.round(formData.price * 100), // Convert to cents and round
+      price: minorAmount(formData.price, currency),
     };
     onSubmit(submitData);
   };
@@ -4,6 +4,7 @@ import { useState, useEffect } from 'react';
 import { Card, CardContent, CardHeader, CardTitle } from '@restosaas/ui';
 import { Button } from '@restosaas/ui';
 import { Tabs, TabsContent, TabsList, TabsTrigger } from '@restosaas/ui';
+import { formatMoney } from '@restosaas/types';
 import {
   Clock,
   MapPin,
@@ -383,7 +384,7 @@ export function RestaurantTabs({ slug }: RestaurantTabsProps) {
                             {menu.mealType}
                           </span>
                           <span className='text-lg font-semibold text-green-600'>
-                            Rs {menu.price}
+                            {formatMoney(menu.price)}
                           </span>
                         </div>
                       </CardHeader>
@@ -442,11 +443,11 @@ export function RestaurantTabs({ slug }: RestaurantTabsProps) {
                                 course.originalPrice ? 'line-through' : ''
                               }
                             >
-                              Rs {course.coursePrice}
+                              {formatMoney(course.coursePrice)}
                             </span>
                             {course.originalPrice && (
                               <span className='text-green-600 font-semibold'>
-                                Rs {course.originalPrice}
+                                {formatMoney(course.originalPrice)}
                               </span>
                             )}
                           </div>
@@ -525,7 +526,7 @@ export function RestaurantTabs({ slug }: RestaurantTabsProps) {
                             {menu.mealType}
                           </span>
                           <span className='text-lg font-semibold text-green-600'>
-                            Rs {menu.price}
+                            {formatMoney(menu.price)}
                           </span>
                         </div>
                       </CardHeader>
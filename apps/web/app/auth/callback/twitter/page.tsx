@@ -8,6 +8,7 @@ export default function TwitterCallback() {
 
   useEffect(() => {
     const code = searchParams.get('code');
+    const state = searchParams.get('state');
     const error = searchParams.get('error');
 
     if (error) {
@@ -29,6 +30,7 @@ export default function TwitterCallback() {
         {
           type: 'OAUTH_SUCCESS',
           code: code,
+          state: state,
         },
         window.location.origin
       );
@@ -0,0 +1,184 @@
+'use client';
+
+import { useEffect, useState } from 'react';
+import { useRouter, useSearchParams } from 'next/navigation';
+import { Button } from '@/components/ui/button';
+import {
+  Card,
+  CardContent,
+  CardDescription,
+  CardHeader,
+  CardTitle,
+} from '@/components/ui/card';
+import { Input } from '@/components/ui/input';
+import { Label } from '@/components/ui/label';
+import { api } from '@/lib/api';
+
+interface InvitationPreview {
+  organizationName: string;
+  email: string;
+  role: string;
+  expiresAt: string;
+  accountExists: boolean;
+}
+
+function errorMessage(err: unknown, fallback: string) {
+  return err && typeof err === 'object' && 'response' in err
+    ? (err as { response?: { data?: { error?: string } } }).response?.data
+        ?.error || fallback
+    : fallback;
+}
+
+export default function AcceptInvitationPage() {
+  const router = useRouter();
+  const searchParams = useSearchParams();
+  const token = searchParams.get('token') || '';
+
+  const [invitation, setInvitation] = useState<InvitationPreview | null>(null);
+  const [error, setError] = useState('');
+  const [isLoading, setIsLoading] = useState(false);
+  const [declined, setDeclined] = useState(false);
+  const [formData, setFormData] = useState({ displayName: '', password: '' });
+  const signedIn =
+    typeof window !== 'undefined' && !!localStorage.getItem('authToken');
+
+  useEffect(() => {
+    if (!token) {
+      setError('This invitation link is incomplete.');
+      return;
+    }
+    api
+      .post('/invitations/preview', { token })
+      .then(({ data }) => setInvitation(data))
+      .catch((err) => setError(errorMessage(err, 'Invitation not found')));
+  }, [token]);
+
+  const handleAccept = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsLoading(true);
+    setError('');
+    try {
+      const { data } = await api.post('/invitations/accept', {
+        token,
+        ...formData,
+      });
+      localStorage.setItem('authToken', data.token);
+      localStorage.setItem('user', JSON.stringify(data.user));
+      router.push('/owner-dashboard');
+    } catch (err) {
+      setError(errorMessage(err, 'Failed to accept invitation'));
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const handleDecline = async () => {
+    setIsLoading(true);
+    setError('');
+    try {
+      await api.post('/invitations/decline', { token });
+      setDeclined(true);
+    } catch (err) {
+      setError(errorMessage(err, 'Failed to decline invitation'));
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  // Existing accounts accept after signing in as the invited email
+  const needsLogin = invitation?.accountExists && !signedIn;
+
+  return (
+    <div className='flex min-h-svh w-full items-center justify-center p-6 md:p-10'>
+      <div className='w-full max-w-sm'>
+        <Card>
+          <CardHeader>
+            <CardTitle className='text-2xl'>
+              {invitation
+                ? `Join ${invitation.organizationName}`
+                : 'Invitation'}
+            </CardTitle>
+            {invitation && (
+              <CardDescription>
+                {invitation.email} was invited as{' '}
+                {invitation.role.toLowerCase()}.
+              </CardDescription>
+            )}
+          </CardHeader>
+          <CardContent>
+            {error && (
+              <div className='mb-4 text-sm text-red-600 bg-red-50 p-3 rounded-md'>
+                {error}
+              </div>
+            )}
+            {declined ? (
+              <p className='text-sm text-gray-600'>
+                You declined this invitation.
+              </p>
+            ) : needsLogin ? (
+              <div className='flex flex-col gap-4'>
+                <p className='text-sm text-gray-600'>
+                  An account already exists for {invitation?.email}. Sign in
+                  with it, then open this link again to accept.
+                </p>
+                <Button onClick={() => router.push('/login')}>
+                  Sign in
+                </Button>
+              </div>
+            ) : (
+              invitation && (
+                <form onSubmit={handleAccept} className='flex flex-col gap-4'>
+                  {!invitation.accountExists && (
+                    <>
+                      <div className='grid gap-2'>
+                        <Label htmlFor='displayName'>Name</Label>
+                        <Input
+                          id='displayName'
+                          value={formData.displayName}
+                          onChange={(e) =>
+                            setFormData({
+                              ...formData,
+                              displayName: e.target.value,
+                            })
+                          }
+                          required
+                        />
+                      </div>
+                      <div className='grid gap-2'>
+                        <Label htmlFor='password'>Password</Label>
+                        <Input
+                          id='password'
+                          type='password'
+                          value={formData.password}
+                          onChange={(e) =>
+                            setFormData({
+                              ...formData,
+                              password: e.target.value,
+                            })
+                          }
+                          required
+                          minLength={4}
+                        />
+                      </div>
+                    </>
+                  )}
+                  <Button type='submit' disabled={isLoading}>
+                    Accept invitation
+                  </Button>
+                  <Button
+                    type='button'
+                    variant='outline'
+                    disabled={isLoading}
+                    onClick={handleDecline}
+                  >
+                    Decline
+                  </Button>
+                </form>
+              )
+            )}
+          </CardContent>
+        </Card>
+      </div>
+    </div>
+  );
+}
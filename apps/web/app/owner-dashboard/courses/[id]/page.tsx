@@ -14,6 +14,12 @@ import {
 import { Badge } from '@/components/ui/badge';
 import { RichTextEditor } from '@/components/ui/rich-text-editor';
 import { api } from '@/lib/api';
+import {
+  formatMoney,
+  majorAmount,
+  minorAmount,
+  type Money,
+} from '@/lib/money';
 import {
   ArrowLeft,
   Edit,
@@ -29,8 +35,8 @@ interface Course {
   title: string;
   description: string;
   imageUrl: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;
@@ -117,8 +123,8 @@ function CourseDetailContent() {
     }
   };
 
-  const formatPrice = (price: number) => {
-    return `Rs ${price}`;
+  const formatPrice = (price: Money) => {
+    return formatMoney(price);
   };
 
   const formatTime = (minutes: number) => {
@@ -367,9 +373,11 @@ function CourseForm({
     title: initialData?.title || '',
     description: initialData?.description || '',
     imageUrl: initialData?.imageUrl || '',
-    coursePrice: initialData?.coursePrice ? initialData.coursePrice / 100 : 0,
+    coursePrice: initialData?.coursePrice
+      ? majorAmount(initialData.coursePrice)
+      : 0,
     originalPrice: initialData?.originalPrice
-      ? initialData.originalPrice / 100
+      ? majorAmount(initialData.originalPrice)
       : '',
     numberOfItems: initialData?.numberOfItems || 1,
     stayTime: initialData?.stayTime || 60,
@@ -377,13 +385,16 @@ function CourseForm({
     precautions: initialData?.precautions || '',
   });
 
+  // New prices are in the restaurant's currency, NPR unless edited
+  const currency = initialData?.coursePrice?.currency;
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     const submitData = {
       ...formData,
-      coursePrice: Math.round(formData.coursePrice * 100), // Convert to cents and round
+      coursePrice: minorAmount(formData.coursePrice, currency),
       originalPrice: formData.originalPrice
-        ? Math.round(parseFloat(String(formData.originalPrice)) * 100)
+        ? minorAmount(parseFloat(String(formData.originalPrice)), currency)
         : null,
     };
     onSubmit(submitData);
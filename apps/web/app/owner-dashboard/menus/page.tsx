@@ -15,6 +15,12 @@ import {
 import { Badge } from '@/components/ui/badge';
 import { RichTextEditor } from '@/components/ui/rich-text-editor';
 import { api } from '@/lib/api';
+import {
+  formatMoney,
+  majorAmount,
+  minorAmount,
+  type Money,
+} from '@/lib/money';
 import type {
   CreateCourseRequest,
   CreateMenuRequest,
@@ -38,8 +44,8 @@ interface Course {
   title: string;
   description: string;
   imageUrl: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;
@@ -53,7 +59,7 @@ interface Menu {
   name: string;
   shortDesc: string;
   imageUrl: string;
-  price: number;
+  price: Money;
   type: 'DRINK' | 'FOOD';
   mealType: 'LUNCH' | 'DINNER' | 'BOTH';
   createdAt: string;
@@ -252,8 +258,8 @@ function MenusPageContent() {
     }
   };
 
-  const formatPrice = (price: number) => {
-    return `Rs ${price}`;
+  const formatPrice = (price: Money) => {
+    return formatMoney(price);
   };
 
   const formatTime = (minutes: number) => {
@@ -879,21 +885,26 @@ function CourseForm({
     title: initialData?.title || '',
     description: initialData?.description || '',
     imageUrl: initialData?.imageUrl || '',
-    coursePrice: initialData?.coursePrice || 0,
-    originalPrice: initialData?.originalPrice || '',
+    coursePrice: initialData ? majorAmount(initialData.coursePrice) : 0,
+    originalPrice: initialData?.originalPrice
+      ? majorAmount(initialData.originalPrice)
+      : '',
     numberOfItems: initialData?.numberOfItems || 1,
     stayTime: initialData?.stayTime || 60,
     courseContent: initialData?.courseContent || '',
     precautions: initialData?.precautions || '',
   });
 
+  // New prices are in the restaurant's currency, NPR unless edited
+  const currency = initialData?.coursePrice.currency;
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     const submitData = {
       ...formData,
-      coursePrice: Math.round(formData.coursePrice * 100), // Convert to cents and round
+      coursePrice: minorAmount(formData.coursePrice, currency),
       originalPrice: formData.originalPrice
-        ? Math.round(parseFloat(String(formData.originalPrice)) * 100)
+        ? minorAmount(parseFloat(String(formData.originalPrice)), currency)
         : null,
     };
     onSubmit(submitData);
@@ -1077,16 +1088,19 @@ function MenuForm({
     name: initialData?.name || '',
     shortDesc: initialData?.shortDesc || '',
     imageUrl: initialData?.imageUrl || '',
-    price: initialData?.price ? initialData.price / 100 : 0,
+    price: initialData ? majorAmount(initialData.price) : 0,
     type: initialData?.type || defaultType || 'FOOD',
     mealType: initialData?.mealType || defaultMealType || 'BOTH',
   });
 
+  // New prices are in the restaurant's currency, NPR unless edited
+  const currency = initialData?.price.currency;
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     const submitData = {
       ...formData,
-      price: Math.round(formData.price * 100), // Convert to cents and round
+      price: minorAmount(formData.price, currency),
     };
     onSubmit(submitData);
   };
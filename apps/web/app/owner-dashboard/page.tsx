@@ -20,6 +20,7 @@ import {
 import { Badge } from '@/components/ui/badge';
 import { EnhancedRestaurantForm } from '@/components/forms/enhanced-restaurant-form';
 import { api } from '@/lib/api';
+import type { Money } from '@/lib/money';
 import Link from 'next/link';
 import {
   Edit,
@@ -77,7 +78,7 @@ interface Menu {
   name: string;
   shortDesc: string;
   imageUrl: string;
-  price: number;
+  price: Money;
   type: 'DRINK' | 'FOOD';
   mealType: 'LUNCH' | 'DINNER' | 'BOTH';
   createdAt: string;
@@ -89,8 +90,8 @@ interface Course {
   title: string;
   description: string;
   imageUrl: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;
@@ -1,12 +1,30 @@
 import { api } from '@/lib/api';
-import type { Reservation } from '@restosaas/types';
+
+// Reservations are listed per restaurant, as stored by the API
+interface OwnerReservation {
+  ID: string;
+  StartsAt: string;
+  PartySize: number;
+  Status: 'PENDING' | 'CONFIRMED' | 'CANCELLED' | 'COMPLETED';
+}
+
+const demoHeaders = { 'X-Demo-Role': 'OWNER', 'X-Demo-User': 'demo-user' };
 
 async function getReservations() {
   try {
-    const { data } = await api.get('/owner/reservations', {
-      headers: { 'X-Demo-Role': 'OWNER', 'X-Demo-User': 'demo-user' },
+    const { data: mine } = await api.get('/owner/restaurants/me', {
+      headers: demoHeaders,
     });
-    return data as Reservation[];
+    // For now, use the first restaurant, as the owner dashboard does
+    const restaurant = mine.restaurants?.[0];
+    if (!restaurant) {
+      return [];
+    }
+    const { data } = await api.get(
+      `/owner/restaurants/${restaurant.id}/reservations`,
+      { headers: demoHeaders }
+    );
+    return (data.reservations ?? []) as OwnerReservation[];
   } catch {
     return [];
   }
@@ -18,13 +36,13 @@ export default async function Owner() {
     <main className='max-w-4xl mx-auto p-6 space-y-4'>
       <h1 className='text-2xl font-semibold'>Owner Calendar (demo)</h1>
       <ul className='space-y-2'>
-        {list.map((r, i) => (
-          <li key={i} className='bg-white rounded-2xl border p-4'>
+        {list.map((r) => (
+          <li key={r.ID} className='bg-white rounded-2xl border p-4'>
             <div className='font-mono text-sm'>
-              {r.date} {r.time}
+              {new Date(r.StartsAt).toLocaleString()}
             </div>
             <div>
-              Party: {r.partySize} — Status: {r.status}
+              Party: {r.PartySize} — Status: {r.Status}
             </div>
           </li>
         ))}
@@ -3,6 +3,7 @@
 import { useState, useEffect } from 'react';
 import { useParams, useRouter } from 'next/navigation';
 import { api } from '@/lib/api';
+import { formatMoney, type Money } from '@/lib/money';
 import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card';
 import { Button } from '@/components/ui/button';
 import { Badge } from '@/components/ui/badge';
@@ -21,8 +22,8 @@ interface Course {
   title: string;
   description: string;
   imageUrl?: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;
@@ -197,11 +198,11 @@ export default function CourseDetailPage() {
               <CardContent className='space-y-4'>
                 <div className='text-center'>
                   <div className='text-3xl font-bold text-green-600'>
-                    Rs ${course.coursePrice}
+                    {formatMoney(course.coursePrice)}
                   </div>
                   {course.originalPrice && (
                     <div className='text-lg text-gray-500 line-through'>
-                      Rs ${course.originalPrice}
+                      {formatMoney(course.originalPrice)}
                     </div>
                   )}
                 </div>
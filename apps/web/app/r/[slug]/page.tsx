@@ -2,6 +2,7 @@
 
 import { useState, useEffect } from 'react';
 import { api } from '@/lib/api';
+import { formatMoney, type Money } from '@/lib/money';
 import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card';
 import { Badge } from '@/components/ui/badge';
 import { Button } from '@/components/ui/button';
@@ -62,7 +63,7 @@ interface Menu {
   name: string;
   shortDesc: string;
   imageUrl?: string;
-  price: number;
+  price: Money;
   type: 'FOOD' | 'DRINK';
   mealType: 'LUNCH' | 'DINNER' | 'BOTH';
   createdAt: string;
@@ -74,8 +75,8 @@ interface Course {
   title: string;
   description: string;
   imageUrl?: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;
@@ -637,7 +638,7 @@ export default function RestaurantPage({
                             </Badge>
                             <Badge variant='outline'>{menu.mealType}</Badge>
                             <span className='text-lg font-semibold text-green-600'>
-                              Rs ${menu.price}
+                              {formatMoney(menu.price)}
                             </span>
                           </div>
                         </CardHeader>
@@ -697,11 +698,11 @@ export default function RestaurantPage({
                                   course.originalPrice ? 'line-through' : ''
                                 }
                               >
-                                Rs ${course.coursePrice}
+                                {formatMoney(course.coursePrice)}
                               </span>
                               {course.originalPrice && (
                                 <span className='text-green-600 font-semibold'>
-                                  Rs ${course.originalPrice}
+                                  {formatMoney(course.originalPrice)}
                                 </span>
                               )}
                             </div>
@@ -785,7 +786,7 @@ export default function RestaurantPage({
                           <div className='flex items-center gap-2'>
                             <Badge variant='outline'>{menu.mealType}</Badge>
                             <span className='text-lg font-semibold text-green-600'>
-                              Rs ${menu.price}
+                              {formatMoney(menu.price)}
                             </span>
                           </div>
                         </CardHeader>
@@ -1,6 +1,6 @@
 'use client';
 
-import { useState } from 'react';
+import { useEffect, useState } from 'react';
 import { cn } from '@/lib/utils';
 import { Button } from '@/components/ui/button';
 import {
@@ -34,8 +34,19 @@ export function LoginForm({ className, onSuccess, ...props }: LoginFormProps) {
     displayName: '',
     role: 'CUSTOMER',
   });
+  const [oidcProviders, setOidcProviders] = useState<
+    { name: string; displayName: string }[]
+  >([]);
   const { login } = useAuth();
 
+  // Load any configured OpenID Connect providers
+  useEffect(() => {
+    api
+      .get('/auth/oidc/providers')
+      .then(({ data }) => setOidcProviders(data.providers || []))
+      .catch(() => setOidcProviders([]));
+  }, []);
+
   // Email validation regex
   const emailRegex =
     /^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$/;
@@ -81,19 +92,15 @@ export function LoginForm({ className, onSuccess, ...props }: LoginFormProps) {
     }
   };
 
-  const handleOAuthLogin = async (
-    provider: 'google' | 'facebook' | 'twitter'
-  ) => {
+  const handleOAuthLogin = async (provider: string, oidc = false) => {
     setIsOAuthLoading(provider);
     setError('');
 
     try {
-      // Get OAuth authorization URL
-      const authUrl = getOAuthUrl(provider);
-
-      // Open OAuth popup
+      // Open the popup synchronously so it is not blocked, then point it at
+      // the provider once the server has issued a state
       const popup = window.open(
-        authUrl,
+        '',
         'oauth',
         'width=500,height=600,scrollbars=yes,resizable=yes'
       );
@@ -102,17 +109,46 @@ export function LoginForm({ className, onSuccess, ...props }: LoginFormProps) {
         throw new Error('Popup blocked. Please allow popups for this site.');
       }
 
+      const redirectUri = oidc
+        ? `${window.location.origin}/auth/callback/oidc/${provider}`
+        : `${window.location.origin}/auth/callback/${provider}`;
+      const authorizePath = oidc
+        ? `/auth/oidc/${provider}/authorize`
+        : `/auth/oauth/${provider}/authorize`;
+      const callbackPath = oidc
+        ? `/auth/oidc/${provider}/callback`
+        : `/auth/oauth/${provider}`;
+      let state: string;
+      try {
+        const { data } = await api.get(authorizePath, {
+          params: { redirectUri },
+        });
+        state = data.state;
+        popup.location.href = data.authorizationUrl;
+      } catch (err) {
+        popup.close();
+        throw err;
+      }
+
       // Listen for OAuth callback
       const handleMessage = async (event: MessageEvent) => {
         if (event.origin !== window.location.origin) return;
 
         if (event.data.type === 'OAUTH_SUCCESS') {
-          const { code } = event.data;
+          const { code, state: returnedState } = event.data;
+
+          if (returnedState !== state) {
+            setError(`Failed to login with ${provider}`);
+            popup.close();
+            window.removeEventListener('message', handleMessage);
+            return;
+          }
 
           try {
-            // Send code to backend
-            const { data } = await api.post(`/auth/oauth/${provider}`, {
+            // Send code and state to backend
+            const { data } = await api.post(callbackPath, {
               code,
+              state: returnedState,
             });
 
             // Store token and user data
@@ -162,34 +198,6 @@ export function LoginForm({ className, onSuccess, ...props }: LoginFormProps) {
     }
   };
 
-  const getOAuthUrl = (provider: 'google' | 'facebook' | 'twitter'): string => {
-    const baseUrl = window.location.origin;
-    const redirectUri = `${baseUrl}/auth/callback/${provider}`;
-
-    switch (provider) {
-      case 'google':
-        const googleClientId = process.env.NEXT_PUBLIC_GOOGLE_CLIENT_ID;
-        return `https://accounts.google.com/o/oauth2/v2/auth?client_id=${googleClientId}&redirect_uri=${encodeURIComponent(
-          redirectUri
-        )}&response_type=code&scope=openid%20email%20profile&access_type=offline`;
-
-      case 'facebook':
-        const facebookClientId = process.env.NEXT_PUBLIC_FACEBOOK_CLIENT_ID;
-        return `https://www.facebook.com/v18.0/dialog/oauth?client_id=${facebookClientId}&redirect_uri=${encodeURIComponent(
-          redirectUri
-        )}&response_type=code&scope=email,public_profile`;
-
-      case 'twitter':
-        const twitterClientId = process.env.NEXT_PUBLIC_TWITTER_CLIENT_ID;
-        return `https://twitter.com/i/oauth2/authorize?client_id=${twitterClientId}&redirect_uri=${encodeURIComponent(
-          redirectUri
-        )}&response_type=code&scope=tweet.read%20users.read%20offline.access&state=state&code_challenge=challenge&code_challenge_method=plain`;
-
-      default:
-        throw new Error('Unsupported OAuth provider');
-    }
-  };
-
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     setIsLoading(true);
@@ -417,6 +425,23 @@ export function LoginForm({ className, onSuccess, ...props }: LoginFormProps) {
                     </>
                   )}
                 </Button>
+
+                {oidcProviders.map((provider) => (
+                  <Button
+                    key={provider.name}
+                    variant='outline'
+                    type='button'
+                    onClick={() => handleOAuthLogin(provider.name, true)}
+                    disabled={isLoading || isOAuthLoading !== null}
+                    className='w-full'
+                  >
+                    {isOAuthLoading === provider.name
+                      ? 'Loading...'
+                      : `${isLogin ? 'Login' : 'Sign up'} with ${
+                          provider.displayName
+                        }`}
+                  </Button>
+                ))}
               </div>
             </div>
 
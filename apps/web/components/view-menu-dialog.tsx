@@ -11,14 +11,15 @@ import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card';
 import { Badge } from '@/components/ui/badge';
 import { Tabs, TabsContent, TabsList, TabsTrigger } from '@/components/ui/tabs';
 import { api } from '@/lib/api';
+import { formatMoney, type Money } from '@/lib/money';
 
 interface Course {
   id: string;
   title: string;
   description: string;
   imageUrl: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;
@@ -32,7 +33,7 @@ interface Menu {
   name: string;
   shortDesc: string;
   imageUrl: string;
-  price: number;
+  price: Money;
   type: 'DRINK' | 'FOOD';
   mealType: 'LUNCH' | 'DINNER' | 'BOTH';
   createdAt: string;
@@ -91,8 +92,8 @@ export function ViewMenuDialog({
     }
   };
 
-  const formatPrice = (price: number) => {
-    return `Rs ${price}`;
+  const formatPrice = (price: Money) => {
+    return formatMoney(price);
   };
 
   const formatTime = (minutes: number) => {
@@ -0,0 +1,20 @@
+import { formatMoney, majorAmount, minorAmount } from '../money';
+
+describe('Money', () => {
+  it('should format minor units in major units', () => {
+    expect(formatMoney({ amount: 25050, currency: 'NPR' })).toBe('Rs 250.50');
+    expect(formatMoney({ amount: 2500, currency: 'JPY' })).toBe('¥ 2500');
+    expect(formatMoney({ amount: 999, currency: 'USD' })).toBe('$ 9.99');
+  });
+
+  it('should treat bare numbers as paisa', () => {
+    expect(formatMoney(25000)).toBe('Rs 250.00');
+    expect(majorAmount(25050)).toBe(250.5);
+  });
+
+  it('should convert form values to minor units', () => {
+    expect(minorAmount(250.5)).toBe(25050);
+    expect(minorAmount(2500, 'JPY')).toBe(2500);
+    expect(minorAmount(0.1 + 0.2)).toBe(30);
+  });
+});
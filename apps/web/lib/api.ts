@@ -26,7 +26,9 @@ api.interceptors.response.use(
       const isAuthEndpoint =
         error.config?.url?.includes('/auth/login') ||
         error.config?.url?.includes('/auth/register') ||
-        error.config?.url?.includes('/auth/oauth/');
+        error.config?.url?.includes('/auth/oauth/') ||
+        error.config?.url?.includes('/auth/oidc/') ||
+        error.config?.url?.includes('/invitations/');
 
       if (!isAuthEndpoint) {
         localStorage.removeItem('authToken');
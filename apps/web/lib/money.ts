@@ -0,0 +1,43 @@
+// Prices as the API returns them: an amount in minor units (paisa for
+// NPR) and an ISO 4217 currency code
+export interface Money {
+  amount: number;
+  currency: string;
+}
+
+// The API's supported currencies (internal/money), by code
+const currencies: Record<string, { exponent: number; symbol: string }> = {
+  NPR: { exponent: 2, symbol: 'Rs' },
+  INR: { exponent: 2, symbol: '₹' },
+  JPY: { exponent: 0, symbol: '¥' },
+  CNY: { exponent: 2, symbol: 'CN¥' },
+  USD: { exponent: 2, symbol: '$' },
+  EUR: { exponent: 2, symbol: '€' },
+  GBP: { exponent: 2, symbol: '£' },
+  AUD: { exponent: 2, symbol: 'A$' },
+};
+
+const decimals = (currency: string) => currencies[currency]?.exponent ?? 2;
+
+// Older responses gave prices as a bare number of paisa
+const toMoney = (price: Money | number): Money =>
+  typeof price === 'number' ? { amount: price, currency: 'NPR' } : price;
+
+// majorAmount is the price in whole units, e.g. 250.5 for Rs 250.50
+export function majorAmount(price: Money | number): number {
+  const { amount, currency } = toMoney(price);
+  return amount / 10 ** decimals(currency);
+}
+
+// minorAmount converts a form value in whole units back to minor units
+export function minorAmount(value: number, currency = 'NPR'): number {
+  return Math.round(value * 10 ** decimals(currency));
+}
+
+// formatMoney renders a price, e.g. "Rs 250.50" or "¥ 2500"
+export function formatMoney(price: Money | number): string {
+  const money = toMoney(price);
+  const places = decimals(money.currency);
+  const symbol = currencies[money.currency]?.symbol ?? money.currency;
+  return `${symbol} ${majorAmount(money).toFixed(places)}`;
+}
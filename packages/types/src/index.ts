@@ -50,13 +50,59 @@ export interface Image {
   displayOrder: number;
 }
 
+// Money types
+// Prices are an amount in minor units (paisa for NPR) and an ISO 4217
+// currency code
+export interface Money {
+  amount: number;
+  currency: string;
+}
+
+// The API's supported currencies (internal/money), by code
+export const CURRENCIES: Record<string, { exponent: number; symbol: string }> =
+  {
+    NPR: { exponent: 2, symbol: 'Rs' },
+    INR: { exponent: 2, symbol: '₹' },
+    JPY: { exponent: 0, symbol: '¥' },
+    CNY: { exponent: 2, symbol: 'CN¥' },
+    USD: { exponent: 2, symbol: '$' },
+    EUR: { exponent: 2, symbol: '€' },
+    GBP: { exponent: 2, symbol: '£' },
+    AUD: { exponent: 2, symbol: 'A$' },
+  };
+
+const currencyDecimals = (currency: string) =>
+  CURRENCIES[currency]?.exponent ?? 2;
+
+// Older responses gave prices as a bare number of paisa
+const toMoney = (price: Money | number): Money =>
+  typeof price === 'number' ? { amount: price, currency: 'NPR' } : price;
+
+// majorAmount is the price in whole units, e.g. 250.5 for Rs 250.50
+export function majorAmount(price: Money | number): number {
+  const { amount, currency } = toMoney(price);
+  return amount / 10 ** currencyDecimals(currency);
+}
+
+// minorAmount converts a form value in whole units back to minor units
+export function minorAmount(value: number, currency = 'NPR'): number {
+  return Math.round(value * 10 ** currencyDecimals(currency));
+}
+
+// formatMoney renders a price, e.g. "Rs 250.50" or "¥ 2500"
+export function formatMoney(price: Money | number): string {
+  const money = toMoney(price);
+  const symbol = CURRENCIES[money.currency]?.symbol ?? money.currency;
+  return `${symbol} ${majorAmount(money).toFixed(currencyDecimals(money.currency))}`;
+}
+
 // Menu types
 export interface Menu {
   id: string;
   name: string;
   shortDesc: string;
   imageUrl?: string;
-  price: number;
+  price: Money;
   type: 'FOOD' | 'DRINK';
   mealType: 'LUNCH' | 'DINNER' | 'BOTH';
   createdAt: string;
@@ -69,8 +115,8 @@ export interface Course {
   title: string;
   description: string;
   imageUrl?: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;
@@ -224,7 +270,7 @@ export interface MenuResponse {
   name: string;
   shortDesc: string;
   imageUrl: string;
-  price: number;
+  price: Money;
   type: string;
   mealType: string;
   createdAt: string;
@@ -237,8 +283,8 @@ export interface CourseResponse {
   title: string;
   description: string;
   imageUrl: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;
@@ -434,7 +480,7 @@ export interface CreateMenuRequest {
   name: string;
   shortDesc: string;
   imageUrl: string;
-  price: number;
+  price: number; // Minor units, in the restaurant's currency
   type: 'DRINK' | 'FOOD';
   mealType: 'LUNCH' | 'DINNER' | 'BOTH';
 }
@@ -453,7 +499,7 @@ export interface CreateCourseRequest {
   title: string;
   description: string;
   imageUrl: string;
-  coursePrice: number;
+  coursePrice: number; // Minor units, in the restaurant's currency
   originalPrice?: number;
   numberOfItems: number;
   stayTime: number;
@@ -551,7 +597,7 @@ export interface MenuResponse {
   name: string;
   shortDesc: string;
   imageUrl: string;
-  price: number;
+  price: Money;
   type: string;
   mealType: string;
   createdAt: string;
@@ -565,8 +611,8 @@ export interface CourseResponse {
   title: string;
   description: string;
   imageUrl: string;
-  coursePrice: number;
-  originalPrice?: number;
+  coursePrice: Money;
+  originalPrice?: Money;
   numberOfItems: number;
   stayTime: number;
   courseContent: string;